@@ -38,12 +38,12 @@ var combineCmd = &cobra.Command{
 			return err
 		}
 
-		skipCompression, err := cmd.Flags().GetBool("no-compress")
+		paths, err := cli.LoadFiles(inputs, ".json")
 		if err != nil {
 			return err
 		}
 
-		paths, err := cli.LoadFiles(inputs, ".json")
+		strategy, err := cli.MergeStrategy(cmd)
 		if err != nil {
 			return err
 		}
@@ -62,7 +62,7 @@ var combineCmd = &cobra.Command{
 				logger.Error(err.Error())
 				return err
 			}
-			result.Combine(*newResult)
+			result.CombineWith(*newResult, strategy)
 		}
 
 		err = cli.DecorateResults(&result, cmd)
@@ -71,12 +71,7 @@ var combineCmd = &cobra.Command{
 			return err
 		}
 
-		jsonData, err := cli.Marshal(result)
-		if err != nil {
-			return err
-		}
-
-		_, err = cli.WriteToFilePath(jsonData, output, !skipCompression)
+		err = cli.WriteResult(&result, output, cmd)
 		if err != nil {
 			return err
 		}
@@ -85,7 +80,12 @@ var combineCmd = &cobra.Command{
 }
 
 func init() {
-	combineCmd.Flags().Int32P("trim-output-to", "s", 0, "trim stdout to N characters, defaults to 0(unlimited)")
+	combineCmd.Flags().Int32P("trim-output-to", "s", 0, "trim outputs and failure bodies to N bytes keeping head and tail, defaults to 0(unlimited)")
+	combineCmd.Flags().Int("trim-passed-output-to", 0, "trim outputs of passed tests to N bytes, overrides --trim-output-to")
+	combineCmd.Flags().Int("trim-failed-output-to", 0, "trim outputs and failure bodies of failed tests to N bytes, overrides --trim-output-to")
+	combineCmd.Flags().String("max-output-size", "", "cap total size of all outputs, e.g. 10MB, largest outputs are trimmed first")
 	combineCmd.Flags().BoolP("omit-output-for-passed", "o", false, "omit stdout if test passed, defaults to false")
+	combineCmd.Flags().Bool("no-redact", false, "skip redaction of secrets in test output and failures")
+	combineCmd.Flags().String("merge-strategy", "worst", mergeStrategyDescription)
 	rootCmd.AddCommand(combineCmd)
 }
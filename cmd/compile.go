@@ -17,11 +17,12 @@ limitations under the License.
 */
 
 import (
-	"encoding/json"
-	"os"
+	"fmt"
 
 	"github.com/semaphoreci/test-results/pkg/cli"
+	"github.com/semaphoreci/test-results/pkg/gates"
 	"github.com/semaphoreci/test-results/pkg/logger"
+	"github.com/semaphoreci/test-results/pkg/parser"
 	"github.com/spf13/cobra"
 )
 
@@ -44,73 +45,90 @@ var compileCmd = &cobra.Command{
 			return err
 		}
 
-		skipCompression, err := cmd.Flags().GetBool("no-compress")
+		rules, err := cli.ParseQualityGates(cmd)
 		if err != nil {
 			return err
 		}
 
-		paths, err := cli.LoadFiles(inputs, ".xml")
+		quarantineList, err := cli.LoadQuarantine(cmd)
 		if err != nil {
 			return err
 		}
 
-		dirPath, err := os.MkdirTemp("", "test-results-*")
+		ownersResolver, err := cli.LoadOwners(cmd)
+		if err != nil {
+			return err
+		}
 
+		paths, err := cli.LoadFiles(inputs, ".xml")
 		if err != nil {
 			return err
 		}
 
-		defer os.RemoveAll(dirPath)
-
-		for _, path := range paths {
-			parser, err := cli.FindParser(path, cmd)
-			if err != nil {
-				return err
-			}
-
-			testResults, err := cli.Parse(parser, path, cmd)
-			if err != nil {
-				return err
-			}
-
-			jsonData, err := cli.Marshal(testResults)
-			if err != nil {
-				return err
-			}
-
-			tmpFile, err := os.CreateTemp(dirPath, "result-*.json")
-			if err != nil {
-				return err
-			}
-
-			_, err = cli.WriteToFilePath(jsonData, tmpFile.Name(), !skipCompression)
-			if err != nil {
-				return err
-			}
+		result, err := cli.ParseFiles(paths, cmd)
+		if err != nil {
+			// Failed files are already listed, usage would only hide them
+			return newExitError(cmd, 1, err)
 		}
 
-		result, err := cli.MergeFiles(dirPath, cmd)
+		err = cli.FilterTags(cmd, result)
 		if err != nil {
 			return err
 		}
 
-		jsonData, err := json.Marshal(result)
+		cli.ApplyQuarantine(quarantineList, result)
+		cli.ApplyOwners(ownersResolver, result)
+
+		err = cli.WriteResult(result, output, cmd)
 		if err != nil {
-			logger.Error("Marshaling results failed with: %v", err)
 			return err
 		}
 
-		_, err = cli.WriteToFilePath(jsonData, output, !skipCompression)
+		err = cli.PrintFailOwners(cmd, result)
 		if err != nil {
 			return err
 		}
 
-		return nil
+		return checkQualityGates(cmd, *result, rules)
 	},
 }
 
+// checkQualityGates fails the command with exit code of the first rule that tripped
+func checkQualityGates(cmd *cobra.Command, result parser.Result, rules []gates.Rule) error {
+	violations := gates.Evaluate(result, rules)
+	if len(violations) == 0 {
+		return nil
+	}
+
+	for _, violation := range violations {
+		logger.Error("Quality gate \"%s\" tripped: %s", violation.Rule, violation.Message)
+	}
+
+	first := violations[0]
+	return newExitError(cmd, first.Rule.ExitCode(), fmt.Errorf("quality gate \"%s\" tripped: %s", first.Rule, first.Message))
+}
+
+const failOnDescription = `fail with non-zero exit code when rule trips, can be repeated, one of:
+failed[=N], errors[=N], pass-rate=PERCENT, skipped-ratio=PERCENT, duration=DURATION, no-tests`
+
+const mergeStrategyDescription = "which result is kept when the same test is found in more than one report: worst, best or last"
+
 func init() {
-	compileCmd.Flags().Int32P("trim-output-to", "s", 0, "trim stdout to N characters, defaults to 0(unlimited)")
+	compileCmd.Flags().Int32P("trim-output-to", "s", 0, "trim outputs and failure bodies to N bytes keeping head and tail, defaults to 0(unlimited)")
+	compileCmd.Flags().Int("trim-passed-output-to", 0, "trim outputs of passed tests to N bytes, overrides --trim-output-to")
+	compileCmd.Flags().Int("trim-failed-output-to", 0, "trim outputs and failure bodies of failed tests to N bytes, overrides --trim-output-to")
+	compileCmd.Flags().String("max-output-size", "", "cap total size of all outputs, e.g. 10MB, largest outputs are trimmed first")
 	compileCmd.Flags().BoolP("omit-output-for-passed", "o", false, "omit stdout if test passed, defaults to false")
+	compileCmd.Flags().Bool("no-redact", false, "skip redaction of secrets in test output and failures")
+	compileCmd.Flags().StringSlice("fail-on", []string{}, failOnDescription)
+	compileCmd.Flags().Int("jobs", 0, "number of files parsed in parallel, defaults to number of CPUs")
+	compileCmd.Flags().String("merge-strategy", "worst", mergeStrategyDescription)
+	compileCmd.Flags().String("on-parse-error", "fail", "what to do with files which could not be parsed: fail, warn (keep them with error status) or skip")
+	compileCmd.Flags().StringSlice("include-tags", []string{}, "keep only tests tagged with any of the tags")
+	compileCmd.Flags().StringSlice("exclude-tags", []string{}, "drop tests tagged with any of the tags")
+	compileCmd.Flags().String("quarantine", "", "YAML file listing quarantined tests, their failures are not counted as failed")
+	compileCmd.Flags().String("codeowners", "", "CODEOWNERS file used to annotate tests with owners, looked up in .github/, root and docs/ when not set")
+	compileCmd.Flags().String("owners", "", "YAML file assigning owners to tests, takes precedence over CODEOWNERS")
+	compileCmd.Flags().Bool("fail-owners", false, "print failed tests grouped by owner")
 	rootCmd.AddCommand(compileCmd)
 }
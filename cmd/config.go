@@ -0,0 +1,76 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/semaphoreci/test-results/pkg/cli"
+	"github.com/semaphoreci/test-results/pkg/config"
+	"github.com/semaphoreci/test-results/pkg/logger"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// configCmd represents the config command
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "inspects configuration of test-results commands",
+	Long:  `Inspects configuration of test-results commands`,
+}
+
+// configPrintCmd represents the config print command
+var configPrintCmd = &cobra.Command{
+	Use:   "print [<command>...]",
+	Short: "prints effective configuration",
+	Long: `Prints effective configuration as YAML
+
+	Every value is commented with its source: flag, env, file or default.
+	Flags are read from .test-results.yml, overridden by TEST_RESULTS_* environment
+	variables, which are overridden by flags given on the command line.
+	Without arguments, flags of all commands are printed, e.g. "config print publish"
+	prints flags of publish only.
+	`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		err := cli.SetLogLevel(cmd)
+		if err != nil {
+			return err
+		}
+
+		commands := configurableCommands(rootCmd)
+		if len(args) > 0 {
+			target, _, err := rootCmd.Find(args)
+			if err != nil || target == rootCmd {
+				return fmt.Errorf("unknown command %q", args)
+			}
+			commands = []*cobra.Command{target}
+		}
+
+		data, err := config.Print(viper.GetViper(), rootCmd, commands)
+		if err != nil {
+			logger.Error("Printing configuration failed: %v", err)
+			return err
+		}
+
+		fmt.Fprint(cmd.OutOrStdout(), string(data))
+		return nil
+	},
+}
+
+// configurableCommands lists subcommands of cmd, recursively, which can be configured
+func configurableCommands(cmd *cobra.Command) []*cobra.Command {
+	commands := []*cobra.Command{}
+	for _, c := range cmd.Commands() {
+		if c == configCmd || c.Name() == "help" || c.Name() == "completion" {
+			continue
+		}
+
+		commands = append(commands, c)
+		commands = append(commands, configurableCommands(c)...)
+	}
+
+	return commands
+}
+
+func init() {
+	configCmd.AddCommand(configPrintCmd)
+	rootCmd.AddCommand(configCmd)
+}
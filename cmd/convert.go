@@ -0,0 +1,71 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/semaphoreci/test-results/pkg/cli"
+	"github.com/semaphoreci/test-results/pkg/junit"
+	"github.com/semaphoreci/test-results/pkg/logger"
+	"github.com/spf13/cobra"
+)
+
+// convertCmd represents the convert command
+var convertCmd = &cobra.Command{
+	Use:   "convert --to junit <json-file> <xml-file>",
+	Short: "converts json report into other formats",
+	Long: `Converts json report into other formats
+
+	It reads report produced by compile, combine or gen-pipeline-report commands
+	and serialises it into a single <testsuites> JUnit XML document.
+	`,
+	Args: cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		input := args[0]
+		output := args[1]
+
+		err := cli.SetLogLevel(cmd)
+		if err != nil {
+			return err
+		}
+
+		format, err := cmd.Flags().GetString("to")
+		if err != nil {
+			logger.Error("Reading flag error: %v", err)
+			return err
+		}
+
+		if format != "junit" {
+			err = fmt.Errorf("unsupported output format: %s", format)
+			logger.Error(err.Error())
+			return err
+		}
+
+		inFile, err := cli.CheckFile(input)
+		if err != nil {
+			return err
+		}
+
+		result, err := cli.Load(inFile)
+		if err != nil {
+			logger.Error(err.Error())
+			return err
+		}
+
+		xmlData, err := junit.Marshal(*result)
+		if err != nil {
+			return err
+		}
+
+		_, err = cli.WriteToFilePath(xmlData, output, false)
+		if err != nil {
+			return err
+		}
+
+		return nil
+	},
+}
+
+func init() {
+	convertCmd.Flags().String("to", "junit", "output format, one of: junit")
+	rootCmd.AddCommand(convertCmd)
+}
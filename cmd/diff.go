@@ -0,0 +1,92 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/semaphoreci/test-results/pkg/cli"
+	"github.com/semaphoreci/test-results/pkg/diff"
+	"github.com/semaphoreci/test-results/pkg/logger"
+	"github.com/spf13/cobra"
+)
+
+// diffCmd represents the diff command
+var diffCmd = &cobra.Command{
+	Use:   "diff <base-json-path> <head-json-path>",
+	Short: "compares test results of two runs",
+	Long: `Compares test results of two runs
+
+	Tests are matched by their IDs. Both <base-json-path> and <head-json-path> can be
+	either a json report or a directory with json reports that are merged together.
+	The command exits with non-zero code when tests started failing.
+	`,
+	Args: cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		err := cli.SetLogLevel(cmd)
+		if err != nil {
+			return err
+		}
+
+		format, err := cmd.Flags().GetString("format")
+		if err != nil {
+			logger.Error("Reading flag error: %v", err)
+			return err
+		}
+
+		options := diff.DefaultOptions()
+
+		options.SlowerPercent, err = cmd.Flags().GetFloat64("slower-percent")
+		if err != nil {
+			logger.Error("Reading flag error: %v", err)
+			return err
+		}
+
+		options.SlowerMin, err = cmd.Flags().GetDuration("slower-min")
+		if err != nil {
+			logger.Error("Reading flag error: %v", err)
+			return err
+		}
+
+		base, err := cli.LoadResult(args[0], cmd)
+		if err != nil {
+			return err
+		}
+
+		head, err := cli.LoadResult(args[1], cmd)
+		if err != nil {
+			return err
+		}
+
+		d := diff.Compare(*base, *head, options)
+
+		switch format {
+		case "text":
+			fmt.Fprint(cmd.OutOrStdout(), d.Text())
+		case "markdown":
+			fmt.Fprint(cmd.OutOrStdout(), d.Markdown())
+		case "json":
+			data, err := d.JSON()
+			if err != nil {
+				return err
+			}
+			fmt.Fprint(cmd.OutOrStdout(), string(data))
+		default:
+			err = fmt.Errorf("unsupported output format: %s", format)
+			logger.Error(err.Error())
+			return err
+		}
+
+		if regressions := d.Regressions(); len(regressions) > 0 {
+			return newExitError(cmd, 1, fmt.Errorf("%d test(s) started failing", len(regressions)))
+		}
+
+		return nil
+	},
+}
+
+func init() {
+	defaults := diff.DefaultOptions()
+	diffCmd.Flags().String("format", "text", "output format, one of: text, markdown, json")
+	diffCmd.Flags().Float64("slower-percent", defaults.SlowerPercent, "report tests that got slower by at least N percent, 0 disables")
+	diffCmd.Flags().Duration("slower-min", defaults.SlowerMin, "report tests that got slower by at least given duration, 0 disables")
+	rootCmd.AddCommand(diffCmd)
+}
@@ -0,0 +1,144 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/semaphoreci/test-results/pkg/cli"
+	"github.com/semaphoreci/test-results/pkg/flaky"
+	"github.com/semaphoreci/test-results/pkg/history"
+	"github.com/semaphoreci/test-results/pkg/logger"
+	"github.com/semaphoreci/test-results/pkg/parser"
+	"github.com/spf13/cobra"
+)
+
+// flakyCmd represents the flaky command
+var flakyCmd = &cobra.Command{
+	Use:   "flaky <results-dir>...",
+	Short: "detects flaky tests in historical results",
+	Long: `Detects flaky tests in historical results
+
+	Every json report found in <results-dir> is treated as a separate run. Tests are
+	reported as flaky when they both passed and failed on the same commit, or when
+	their outcome flips across recent runs more often than --threshold percent.
+	Rows from --store history are taken into account as well.
+	`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		err := cli.SetLogLevel(cmd)
+		if err != nil {
+			return err
+		}
+
+		format, err := cmd.Flags().GetString("format")
+		if err != nil {
+			logger.Error("Reading flag error: %v", err)
+			return err
+		}
+
+		options := flaky.DefaultOptions()
+
+		options.Threshold, err = cmd.Flags().GetFloat64("threshold")
+		if err != nil {
+			logger.Error("Reading flag error: %v", err)
+			return err
+		}
+
+		options.MinRuns, err = cmd.Flags().GetInt("min-runs")
+		if err != nil {
+			logger.Error("Reading flag error: %v", err)
+			return err
+		}
+
+		options.Window, err = cmd.Flags().GetInt("window")
+		if err != nil {
+			logger.Error("Reading flag error: %v", err)
+			return err
+		}
+
+		options.OrganizationURL, err = cmd.Flags().GetString("organization-url")
+		if err != nil {
+			logger.Error("Reading flag error: %v", err)
+			return err
+		}
+
+		records, err := loadFlakyRecords(cmd, args)
+		if err != nil {
+			return err
+		}
+
+		tests := flaky.Detect(records, options)
+		logger.Info("Found %d flaky test(s) in %d test executions", len(tests), len(records))
+
+		switch format {
+		case "markdown":
+			fmt.Fprint(cmd.OutOrStdout(), flaky.Markdown(tests))
+		case "json":
+			data, err := flaky.JSON(tests)
+			if err != nil {
+				logger.Error("Marshaling flaky tests failed with: %v", err)
+				return err
+			}
+			fmt.Fprintln(cmd.OutOrStdout(), string(data))
+		default:
+			err = fmt.Errorf("unsupported output format: %s", format)
+			logger.Error(err.Error())
+			return err
+		}
+
+		return nil
+	},
+}
+
+// loadFlakyRecords loads each json report as a separate run, file modification time is used when job start time is unknown.
+// Rows of the same test from the same job are counted once, whether they come from the store or reports.
+func loadFlakyRecords(cmd *cobra.Command, args []string) ([]parser.TestResult, error) {
+	storePath, err := cmd.Flags().GetString("store")
+	if err != nil {
+		logger.Error("Reading flag error: %v", err)
+		return nil, err
+	}
+
+	records := []parser.TestResult{}
+	if storePath != "" {
+		records, err = history.NewStore(storePath).Load()
+		if err != nil {
+			logger.Error("Reading history failed: %v", err)
+			return nil, err
+		}
+	}
+
+	paths, err := cli.LoadFiles(args, ".json")
+	if err != nil {
+		return nil, err
+	}
+
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			logger.Error("Input file read failed: %v", err)
+			return nil, err
+		}
+
+		result, err := cli.Load(path)
+		if err != nil {
+			logger.Error("Loading %s failed: %v", path, err)
+			return nil, err
+		}
+
+		records = append(records, history.Records(*result, info.ModTime())...)
+	}
+
+	// Reports already ingested into the store would count each run twice
+	return history.Dedup(records), nil
+}
+
+func init() {
+	flakyCmd.Flags().String("format", "markdown", "output format, one of: markdown, json")
+	flakyCmd.Flags().Float64("threshold", flaky.DefaultOptions().Threshold, "minimal flip rate in percent of recent runs for a test to be reported")
+	flakyCmd.Flags().Int("min-runs", flaky.DefaultOptions().MinRuns, "minimal number of executed runs needed to judge flip rate")
+	flakyCmd.Flags().Int("window", flaky.DefaultOptions().Window, "number of most recent runs of each test to take into account, 0 for all")
+	flakyCmd.Flags().String("organization-url", os.Getenv("SEMAPHORE_ORGANIZATION_URL"), "organization URL used to link jobs, defaults to SEMAPHORE_ORGANIZATION_URL")
+	flakyCmd.Flags().String("store", "", "history store to include, see history command")
+	rootCmd.AddCommand(flakyCmd)
+}
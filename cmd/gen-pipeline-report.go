@@ -18,12 +18,14 @@ limitations under the License.
 
 import (
 	"encoding/json"
+	"errors"
 	"os"
 	"path"
 
 	"github.com/semaphoreci/test-results/pkg/cli"
 	"github.com/semaphoreci/test-results/pkg/logger"
 	"github.com/semaphoreci/test-results/pkg/parser"
+	"github.com/semaphoreci/test-results/pkg/storage"
 	"github.com/spf13/cobra"
 )
 
@@ -49,13 +51,22 @@ var genPipelineReportCmd = &cobra.Command{
 
 		var dir string
 
-		pipelineID, found := os.LookupEnv("SEMAPHORE_PIPELINE_ID")
+		pipelineID, found := cli.SemaphoreID(cmd, "SEMAPHORE_PIPELINE_ID")
 		if !found {
 			logger.Error("SEMAPHORE_PIPELINE_ID env is missing")
-			return err
+			return errors.New("SEMAPHORE_PIPELINE_ID env is missing")
 		}
 
 		if len(args) == 0 {
+			dryRun, err := cli.DryRun(cmd)
+			if err != nil {
+				return err
+			}
+			if dryRun != nil {
+				logger.Error("Dry run cannot pull artifacts, pass a directory of reports as <path>")
+				return newExitError(cmd, 1, errors.New("--dry-run requires <path>, a directory of reports to merge"))
+			}
+
 			dir, err = os.MkdirTemp("", "test-results")
 			if err != nil {
 				logger.Error("Creating temporary directory failed %v", err)
@@ -76,6 +87,11 @@ var genPipelineReportCmd = &cobra.Command{
 			return err
 		}
 
+		err = cli.PrintFailOwners(cmd, result)
+		if err != nil {
+			return err
+		}
+
 		jsonData, err := json.Marshal(result)
 		if err != nil {
 			logger.Error("Marshaling results failed with: %v", err)
@@ -88,23 +104,30 @@ var genPipelineReportCmd = &cobra.Command{
 		}
 		defer os.Remove(fileName)
 
-		_, err = cli.PushArtifacts("workflow", fileName, path.Join("test-results", pipelineID+".json"), cmd)
+		uploads := []storage.Upload{{Level: "workflow", File: fileName, Destination: path.Join("test-results", pipelineID+".json")}}
+
+		summaryFileName, err := writeSummary(result.TestResults, cmd)
 		if err != nil {
 			return err
 		}
+		if summaryFileName != "" {
+			defer os.Remove(summaryFileName)
+			uploads = append(uploads, storage.Upload{Level: "workflow", File: summaryFileName, Destination: path.Join("test-results", pipelineID+"-summary.json")})
+		}
 
-		return pushSummaries(result.TestResults, "workflow", path.Join("test-results", pipelineID+"-summary.json"), cmd)
+		return cli.UploadArtifacts(cmd, uploads)
 	},
 }
 
-func pushSummaries(testResult []parser.TestResults, level, path string, cmd *cobra.Command) error {
+// writeSummary writes merged summary of test results into a temporary file, returns empty name when there are no results
+func writeSummary(testResult []parser.TestResults, cmd *cobra.Command) (string, error) {
 	skipCompression, err := cmd.Flags().GetBool("no-compress")
 	if err != nil {
-		return err
+		return "", err
 	}
 	if len(testResult) == 0 {
 		logger.Info("no test results to process")
-		return nil
+		return "", nil
 	}
 
 	logger.Info("starting to generate summary")
@@ -116,20 +139,16 @@ func pushSummaries(testResult []parser.TestResults, level, path string, cmd *cob
 
 	jsonSummary, err := json.Marshal(summaryReport)
 	if err != nil {
-		return err
-	}
-
-	summaryFileName, err := cli.WriteToTmpFile(jsonSummary, !skipCompression)
-	if err != nil {
-		return err
+		return "", err
 	}
-	defer os.Remove(summaryFileName)
 
-	_, err = cli.PushArtifacts(level, summaryFileName, path, cmd)
-	return err
+	return cli.WriteToTmpFile(jsonSummary, !skipCompression)
 }
 
 func init() {
 	genPipelineReportCmd.Flags().BoolP("force", "f", false, "force artifact push, passes -f flag to artifact CLI")
+	addUploadFlags(genPipelineReportCmd)
+	genPipelineReportCmd.Flags().Bool("fail-owners", false, "print failed tests of the pipeline grouped by owner")
+	genPipelineReportCmd.Flags().String("merge-strategy", "worst", mergeStrategyDescription)
 	rootCmd.AddCommand(genPipelineReportCmd)
 }
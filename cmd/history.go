@@ -0,0 +1,188 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/semaphoreci/test-results/pkg/cli"
+	"github.com/semaphoreci/test-results/pkg/history"
+	"github.com/semaphoreci/test-results/pkg/logger"
+	"github.com/spf13/cobra"
+)
+
+// historyCmd represents the history command
+var historyCmd = &cobra.Command{
+	Use:   "history",
+	Short: "manages local history of test executions",
+	Long: `Manages local history of test executions
+
+	History is kept in an append-only JSON lines file, one row per test execution
+	with test ID, git SHA, job ID, timestamp, state and duration.
+	`,
+}
+
+// historyIngestCmd represents the history ingest command
+var historyIngestCmd = &cobra.Command{
+	Use:   "ingest <json-file-path>...",
+	Short: "appends json reports to the history store",
+	Long: `Appends json reports to the history store
+
+	Each argument can be either a json report or a directory with json reports.
+	Rows are timestamped with SEMAPHORE_JOB_CREATION_TIME recorded in the report,
+	falling back to --timestamp and then to the modification time of the report file.
+	Rows already ingested from the same job, or with the same timestamp when the job
+	is unknown, are skipped.
+	`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		err := cli.SetLogLevel(cmd)
+		if err != nil {
+			return err
+		}
+
+		store, err := historyStore(cmd)
+		if err != nil {
+			return err
+		}
+
+		timestamp, err := ingestTimestamp(cmd)
+		if err != nil {
+			return err
+		}
+
+		paths, err := cli.LoadFiles(args, ".json")
+		if err != nil {
+			return err
+		}
+
+		for _, path := range paths {
+			result, err := cli.LoadResult(path, cmd)
+			if err != nil {
+				return err
+			}
+
+			fallback := timestamp
+			if fallback.IsZero() {
+				info, err := os.Stat(path)
+				if err != nil {
+					logger.Error("Reading %s failed: %v", path, err)
+					return err
+				}
+				fallback = info.ModTime()
+			}
+
+			records := history.Records(*result, fallback)
+			appended, err := store.Append(records)
+			if err != nil {
+				logger.Error("Writing history failed: %v", err)
+				return err
+			}
+
+			logger.Info("Ingested %d of %d test executions from %s", appended, len(records), path)
+		}
+
+		return nil
+	},
+}
+
+// historyShowCmd represents the history show command
+var historyShowCmd = &cobra.Command{
+	Use:   "show <test-id-or-name>",
+	Short: "shows history of a single test",
+	Long: `Shows history of a single test
+
+	Test can be identified by its ID or by "<suite> › <name>". Besides the executions,
+	failure and flip rate, commits with flaky outcome, duration trend and the commit
+	where the current failure streak started are reported.
+	`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		err := cli.SetLogLevel(cmd)
+		if err != nil {
+			return err
+		}
+
+		format, err := cmd.Flags().GetString("format")
+		if err != nil {
+			logger.Error("Reading flag error: %v", err)
+			return err
+		}
+
+		store, err := historyStore(cmd)
+		if err != nil {
+			return err
+		}
+
+		records, err := store.Find(args[0])
+		if err != nil {
+			logger.Error("Reading history failed: %v", err)
+			return err
+		}
+
+		analysis := history.Analyze(records)
+
+		switch format {
+		case "text":
+			fmt.Fprint(cmd.OutOrStdout(), analysis.Text())
+			for _, record := range records {
+				fmt.Fprintf(cmd.OutOrStdout(), "  %s  %-8s %10s  %s  %s\n", record.Timestamp.Format(time.RFC3339), record.State, record.Duration, record.GitSha, record.JobId)
+			}
+		case "json":
+			data, err := json.MarshalIndent(map[string]interface{}{"analysis": analysis, "history": records}, "", "  ")
+			if err != nil {
+				logger.Error("Marshaling history failed with: %v", err)
+				return err
+			}
+			fmt.Fprintln(cmd.OutOrStdout(), string(data))
+		default:
+			err = fmt.Errorf("unsupported output format: %s", format)
+			logger.Error(err.Error())
+			return err
+		}
+
+		return nil
+	},
+}
+
+// ingestTimestamp reads --timestamp flag, zero time is returned when it is not given
+func ingestTimestamp(cmd *cobra.Command) (time.Time, error) {
+	value, err := cmd.Flags().GetString("timestamp")
+	if err != nil {
+		logger.Error("Reading flag error: %v", err)
+		return time.Time{}, err
+	}
+
+	if value == "" {
+		return time.Time{}, nil
+	}
+
+	timestamp, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		err = fmt.Errorf("invalid --timestamp %q, expected RFC3339, e.g. 2024-01-31T12:00:00Z", value)
+		logger.Error(err.Error())
+		return time.Time{}, err
+	}
+
+	return timestamp, nil
+}
+
+func historyStore(cmd *cobra.Command) (*history.Store, error) {
+	path, err := cmd.Flags().GetString("store")
+	if err != nil {
+		logger.Error("Reading flag error: %v", err)
+		return nil, err
+	}
+
+	return history.NewStore(path), nil
+}
+
+func init() {
+	historyCmd.PersistentFlags().String("store", history.DefaultPath, "path to the history store")
+	historyIngestCmd.Flags().String("timestamp", "", "RFC3339 timestamp of rows from reports without SEMAPHORE_JOB_CREATION_TIME, defaults to modification time of the report file")
+	historyShowCmd.Flags().String("format", "text", "output format, one of: text, json")
+	historyCmd.AddCommand(historyIngestCmd)
+	historyCmd.AddCommand(historyShowCmd)
+	rootCmd.AddCommand(historyCmd)
+}
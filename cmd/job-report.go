@@ -0,0 +1,191 @@
+package cmd
+
+import (
+	"errors"
+	"os"
+	"time"
+
+	"github.com/semaphoreci/test-results/pkg/cli"
+	"github.com/semaphoreci/test-results/pkg/jobreport"
+	"github.com/semaphoreci/test-results/pkg/logger"
+	"github.com/semaphoreci/test-results/pkg/metrics"
+	"github.com/semaphoreci/test-results/pkg/parser"
+	"github.com/spf13/cobra"
+)
+
+// jobReportCmd represents the job-report command
+var jobReportCmd = &cobra.Command{
+	Use:   "job-report [<markdown-file>]",
+	Short: "generates a job report combining commands, resource usage and test results",
+	Long: `Generates a job report combining commands, resource usage and test results
+
+	Reads commands from the job log, resource usage from the system metrics file and
+	test results from a compiled or merged json report. The report shows a timeline of
+	commands with test runs highlighted, resource usage annotated with the command
+	running at the time, and a test summary. Markdown is appended to <markdown-file>,
+	the report model is written as json to --json. Missing inputs are skipped.
+
+	Timestamps of system metrics are written in the agent's time zone, e.g. EEST.
+	They are resolved in --timezone, samples in other zones are skipped.
+	`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		err := cli.SetLogLevel(cmd)
+		if err != nil {
+			return err
+		}
+
+		jobLog, err := cmd.Flags().GetString("job-log")
+		if err != nil {
+			logger.Error("Reading flag error: %v", err)
+			return err
+		}
+
+		systemMetrics, err := cmd.Flags().GetString("system-metrics")
+		if err != nil {
+			logger.Error("Reading flag error: %v", err)
+			return err
+		}
+
+		testResults, err := cmd.Flags().GetString("test-results")
+		if err != nil {
+			logger.Error("Reading flag error: %v", err)
+			return err
+		}
+
+		jsonPath, err := cmd.Flags().GetString("json")
+		if err != nil {
+			logger.Error("Reading flag error: %v", err)
+			return err
+		}
+
+		timezone, err := cmd.Flags().GetString("timezone")
+		if err != nil {
+			logger.Error("Reading flag error: %v", err)
+			return err
+		}
+
+		location, err := time.LoadLocation(timezone)
+		if err != nil {
+			logger.Error("Invalid time zone %q: %v", timezone, err)
+			return err
+		}
+
+		if len(args) == 0 && jsonPath == "" {
+			return errors.New("either <markdown-file> or --json is required")
+		}
+
+		options, err := jobReportOptions(cmd)
+		if err != nil {
+			return err
+		}
+
+		commands, err := metrics.ReadJobLog(jobLog)
+		if errors.Is(err, os.ErrNotExist) {
+			logger.Warn("Job log not found, skipping commands: %v", err)
+		} else if err != nil {
+			logger.Error("Reading job log failed: %v", err)
+			return err
+		}
+
+		samples, err := metrics.ReadSamples(systemMetrics, location)
+		if errors.Is(err, os.ErrNotExist) {
+			logger.Warn("System metrics not found, skipping resource usage: %v", err)
+		} else if err != nil {
+			logger.Error("Reading system metrics failed: %v", err)
+			return err
+		}
+
+		skipped := 0
+		for _, sample := range samples {
+			if sample.Timestamp.IsZero() {
+				skipped++
+			}
+		}
+		if skipped > 0 {
+			logger.Warn("Skipping %d of %d system metrics sample(s) with invalid timestamps or time zones unknown in %s, see --timezone", skipped, len(samples), location)
+		}
+
+		var result *parser.Result
+		if testResults != "" {
+			result, err = cli.LoadResult(testResults, cmd)
+			if err != nil {
+				return err
+			}
+		}
+
+		if len(commands) == 0 && len(samples) == 0 && result == nil {
+			return errors.New("nothing to report: no commands, system metrics or test results found")
+		}
+
+		r := jobreport.New(commands, samples, result, options)
+
+		if jsonPath != "" {
+			data, err := r.JSON()
+			if err != nil {
+				logger.Error("Marshaling job report failed with: %v", err)
+				return err
+			}
+
+			if _, err := cli.WriteToFilePath(data, jsonPath, false); err != nil {
+				return err
+			}
+		}
+
+		if len(args) > 0 {
+			return cli.AppendToFile(args[0], jobreport.Markdown(r))
+		}
+
+		return nil
+	},
+}
+
+func jobReportOptions(cmd *cobra.Command) (jobreport.Options, error) {
+	options := jobreport.DefaultOptions()
+
+	patterns, err := cmd.Flags().GetStringArray("test-command")
+	if err != nil {
+		logger.Error("Reading flag error: %v", err)
+		return options, err
+	}
+
+	options.TestCommands, err = jobreport.CompileTestCommands(patterns)
+	if err != nil {
+		logger.Error(err.Error())
+		return options, err
+	}
+
+	options.MaxFailures, err = cmd.Flags().GetInt("max-failures")
+	if err != nil {
+		logger.Error("Reading flag error: %v", err)
+		return options, err
+	}
+
+	options.Top, err = cmd.Flags().GetInt("top")
+	if err != nil {
+		logger.Error("Reading flag error: %v", err)
+		return options, err
+	}
+
+	options.MessageLength, err = cmd.Flags().GetInt("message-length")
+	if err != nil {
+		logger.Error("Reading flag error: %v", err)
+		return options, err
+	}
+
+	return options, nil
+}
+
+func init() {
+	defaults := jobreport.DefaultOptions()
+	jobReportCmd.Flags().String("job-log", "/tmp/job_log_*.json", "job log to read commands from, the first file matching the pattern is used")
+	jobReportCmd.Flags().String("system-metrics", "/tmp/system-metrics", "source file to read system metrics from")
+	jobReportCmd.Flags().String("timezone", "Local", "time zone of system metrics timestamps, e.g. Europe/Helsinki")
+	jobReportCmd.Flags().String("test-results", "", "compiled or merged json report to read test results from")
+	jobReportCmd.Flags().String("json", "", "write report model as json to given file")
+	jobReportCmd.Flags().StringArray("test-command", jobreport.DefaultTestCommands, "regular expression matching commands which run tests, can be repeated")
+	jobReportCmd.Flags().Int("max-failures", defaults.MaxFailures, "maximum number of failed tests to list")
+	jobReportCmd.Flags().Int("top", defaults.Top, "number of slowest tests to list")
+	jobReportCmd.Flags().Int("message-length", defaults.MessageLength, "truncate failure messages to N characters, 0 means unlimited")
+	rootCmd.AddCommand(jobReportCmd)
+}
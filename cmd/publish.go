@@ -18,12 +18,15 @@ limitations under the License.
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"path"
+	"time"
 
 	"github.com/semaphoreci/test-results/pkg/cli"
 	"github.com/semaphoreci/test-results/pkg/logger"
+	"github.com/semaphoreci/test-results/pkg/storage"
 	"github.com/spf13/cobra"
 )
 
@@ -49,56 +52,41 @@ var publishCmd = &cobra.Command{
 			return err
 		}
 
-		paths, err := cli.LoadFiles(inputs, ".xml")
+		rules, err := cli.ParseQualityGates(cmd)
 		if err != nil {
 			return err
 		}
 
-		dirPath, err := os.MkdirTemp("", "test-results-*")
-
+		quarantineList, err := cli.LoadQuarantine(cmd)
 		if err != nil {
 			return err
 		}
 
-		defer os.RemoveAll(dirPath)
-
-		for _, path := range paths {
-			parser, err := cli.FindParser(path, cmd)
-			if err != nil {
-				return err
-			}
-
-			testResults, err := cli.Parse(parser, path, cmd)
-			if err != nil {
-				return err
-			}
-
-			jsonData, err := cli.Marshal(testResults)
-			if err != nil {
-				return err
-			}
-
-			tmpFile, err := os.CreateTemp(dirPath, "result-*.json")
-			if err != nil {
-				return err
-			}
+		ownersResolver, err := cli.LoadOwners(cmd)
+		if err != nil {
+			return err
+		}
 
-			_, err = cli.WriteToFile(jsonData, tmpFile, !skipCompression)
-			if err != nil {
-				return err
-			}
+		paths, err := cli.LoadFiles(inputs, ".xml")
+		if err != nil {
+			return err
+		}
 
-			err = tmpFile.Close()
-			if err != nil {
-				return err
-			}
+		result, err := cli.ParseFiles(paths, cmd)
+		if err != nil {
+			// Failed files are already listed, usage would only hide them
+			return newExitError(cmd, 1, err)
 		}
 
-		result, err := cli.MergeFiles(dirPath, cmd)
+		err = cli.FilterTags(cmd, result)
 		if err != nil {
 			return err
 		}
 
+		cli.ApplyQuarantine(quarantineList, result)
+		cli.ApplyOwners(ownersResolver, result)
+		cli.RecordResult(result)
+
 		jsonData, err := json.Marshal(result)
 		if err != nil {
 			logger.Error("Marshaling results failed with: %v", err)
@@ -112,31 +100,28 @@ var publishCmd = &cobra.Command{
 
 		defer os.Remove(fileName)
 
-		_, err = cli.PushArtifacts("job", fileName, path.Join("test-results", "junit.json"), cmd)
+		uploads := []storage.Upload{{Level: "job", File: fileName, Destination: path.Join("test-results", "junit.json")}}
+
+		summaryFileName, err := writeSummary(result.TestResults, cmd)
 		if err != nil {
 			return err
 		}
-
-		if err = pushSummaries(result.TestResults, "job", path.Join("test-results", "summary.json"), cmd); err != nil {
-			return err
+		if summaryFileName != "" {
+			defer os.Remove(summaryFileName)
+			uploads = append(uploads, storage.Upload{Level: "job", File: summaryFileName, Destination: path.Join("test-results", "summary.json")})
 		}
 
-		pipelineID, found := os.LookupEnv("SEMAPHORE_PIPELINE_ID")
+		pipelineID, found := cli.SemaphoreID(cmd, "SEMAPHORE_PIPELINE_ID")
 		if !found {
-			logger.Error("SEMAPHORE_PIPELINE_ID env is missing")
-			return err
+			return uploadJobArtifacts(cmd, uploads, errors.New("SEMAPHORE_PIPELINE_ID env is missing"))
 		}
 
-		jobID, found := os.LookupEnv("SEMAPHORE_JOB_ID")
+		jobID, found := cli.SemaphoreID(cmd, "SEMAPHORE_JOB_ID")
 		if !found {
-			logger.Error("SEMAPHORE_JOB_ID env is missing")
-			return err
+			return uploadJobArtifacts(cmd, uploads, errors.New("SEMAPHORE_JOB_ID env is missing"))
 		}
 
-		_, err = cli.PushArtifacts("workflow", fileName, path.Join("test-results", pipelineID, jobID+".json"), cmd)
-		if err != nil {
-			return err
-		}
+		uploads = append(uploads, storage.Upload{Level: "workflow", File: fileName, Destination: path.Join("test-results", pipelineID, jobID+".json")})
 
 		noRaw, err := cmd.Flags().GetBool("no-raw")
 		if err != nil {
@@ -156,24 +141,68 @@ var publishCmd = &cobra.Command{
 					outPath = path.Join("test-results", fmt.Sprintf("junit-%d.xml", idx))
 				}
 
-				_, err = cli.PushArtifacts("job", rawFilePath, outPath, cmd)
-				if err != nil {
-					return err
-				}
+				uploads = append(uploads, storage.Upload{Level: "job", File: rawFilePath, Destination: outPath})
 			}
 		}
 
-		return nil
+		err = cli.UploadArtifacts(cmd, uploads)
+		if err != nil {
+			return err
+		}
+
+		err = cli.PrintFailOwners(cmd, result)
+		if err != nil {
+			return err
+		}
+
+		return checkQualityGates(cmd, *result, rules)
 	},
 }
 
+// uploadJobArtifacts uploads artifacts which don't need pipeline or job ID and fails with envErr,
+// so missing environment is not mistaken for successful publish
+func uploadJobArtifacts(cmd *cobra.Command, uploads []storage.Upload, envErr error) error {
+	logger.Error("%v", envErr)
+
+	err := cli.UploadArtifacts(cmd, uploads)
+	if err != nil {
+		return errors.Join(envErr, err)
+	}
+
+	return envErr
+}
+
 func init() {
 
 	desc := `Skips uploading raw XML files`
 	publishCmd.Flags().BoolP("no-raw", "", false, desc)
 	publishCmd.Flags().BoolP("force", "f", false, "force artifact push, passes -f flag to artifact CLI")
-	publishCmd.Flags().Int32P("trim-output-to", "s", 0, "trim stdout to N characters, defaults to 0(unlimited)")
+	addUploadFlags(publishCmd)
+	publishCmd.Flags().Int32P("trim-output-to", "s", 0, "trim outputs and failure bodies to N bytes keeping head and tail, defaults to 0(unlimited)")
+	publishCmd.Flags().Int("trim-passed-output-to", 0, "trim outputs of passed tests to N bytes, overrides --trim-output-to")
+	publishCmd.Flags().Int("trim-failed-output-to", 0, "trim outputs and failure bodies of failed tests to N bytes, overrides --trim-output-to")
+	publishCmd.Flags().String("max-output-size", "", "cap total size of all outputs, e.g. 10MB, largest outputs are trimmed first")
 	publishCmd.Flags().BoolP("omit-output-for-passed", "o", false, "omit stdout if test passed, defaults to false")
+	publishCmd.Flags().Bool("no-redact", false, "skip redaction of secrets in test output and failures")
+	publishCmd.Flags().StringSlice("fail-on", []string{}, failOnDescription)
+	publishCmd.Flags().Int("jobs", 0, "number of files parsed in parallel, defaults to number of CPUs")
+	publishCmd.Flags().String("merge-strategy", "worst", mergeStrategyDescription)
+	publishCmd.Flags().String("on-parse-error", "fail", "what to do with files which could not be parsed: fail, warn (keep them with error status) or skip")
+	publishCmd.Flags().StringSlice("include-tags", []string{}, "keep only tests tagged with any of the tags")
+	publishCmd.Flags().StringSlice("exclude-tags", []string{}, "drop tests tagged with any of the tags")
+	publishCmd.Flags().String("quarantine", "", "YAML file listing quarantined tests, their failures are not counted as failed")
+	publishCmd.Flags().String("codeowners", "", "CODEOWNERS file used to annotate tests with owners, looked up in .github/, root and docs/ when not set")
+	publishCmd.Flags().String("owners", "", "YAML file assigning owners to tests, takes precedence over CODEOWNERS")
+	publishCmd.Flags().Bool("fail-owners", false, "print failed tests grouped by owner")
 
 	rootCmd.AddCommand(publishCmd)
 }
+
+// addUploadFlags registers flags tuning artifact uploads and dry run
+func addUploadFlags(cmd *cobra.Command) {
+	cmd.Flags().Int("upload-concurrency", 4, "number of artifacts uploaded in parallel")
+	cmd.Flags().Int("upload-retries", 3, "number of retries of a failed upload, with exponential backoff")
+	cmd.Flags().Duration("upload-timeout", 2*time.Minute, "timeout of a single upload attempt, 0 disables it")
+	cmd.Flags().Bool("dry-run", false, "write artifacts and their manifest into --dry-run-dir instead of uploading them")
+	cmd.Flags().String("dry-run-dir", "test-results-dry-run", "directory of artifacts written in dry run")
+}
@@ -0,0 +1,104 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/semaphoreci/test-results/pkg/cli"
+	"github.com/semaphoreci/test-results/pkg/logger"
+	"github.com/semaphoreci/test-results/pkg/query"
+	"github.com/spf13/cobra"
+)
+
+// queryCmd represents the query command
+var queryCmd = &cobra.Command{
+	Use:   "query <json-file-path>",
+	Short: "finds tests matching a filter in json report",
+	Long: `Finds tests matching a filter in json report
+
+	Filter is a whitespace separated list of conditions that all have to match:
+
+	  state=failed,error          state is one of the listed values, != negates
+	  name~"^User (signs|logs)"   regexp match on name, classname, file, suite,
+	                              package or framework, !~ negates
+	  duration>1s                 duration comparison with >, >=, <, <=, =, !=
+	  tag=slow,smoke              test is tagged with any of the tags, != negates
+	  prop.seed=1234              test or suite property value
+
+	Matching tests are printed as a table, as json lines, or as a pruned json report
+	with re-aggregated summaries that can be passed to combine.
+	`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		err := cli.SetLogLevel(cmd)
+		if err != nil {
+			return err
+		}
+
+		filter, err := cmd.Flags().GetString("filter")
+		if err != nil {
+			logger.Error("Reading flag error: %v", err)
+			return err
+		}
+
+		format, err := cmd.Flags().GetString("format")
+		if err != nil {
+			logger.Error("Reading flag error: %v", err)
+			return err
+		}
+
+		output, err := cmd.Flags().GetString("output")
+		if err != nil {
+			logger.Error("Reading flag error: %v", err)
+			return err
+		}
+
+		skipCompression, err := cmd.Flags().GetBool("no-compress")
+		if err != nil {
+			return err
+		}
+
+		q, err := query.Parse(filter)
+		if err != nil {
+			logger.Error("Parsing filter failed: %v", err)
+			return err
+		}
+
+		result, err := cli.LoadResult(args[0], cmd)
+		if err != nil {
+			return err
+		}
+
+		var data []byte
+		switch format {
+		case "table":
+			data = []byte(query.Table(q.Find(result)))
+		case "jsonl":
+			data, err = query.JSONLines(q.Find(result))
+		case "json":
+			data, err = json.Marshal(q.Prune(*result))
+		default:
+			err = fmt.Errorf("unsupported output format: %s", format)
+		}
+
+		if err != nil {
+			logger.Error(err.Error())
+			return err
+		}
+
+		if output == "" {
+			_, err = cmd.OutOrStdout().Write(data)
+			return err
+		}
+
+		_, err = cli.WriteToFilePath(data, output, format == "json" && !skipCompression)
+		return err
+	},
+}
+
+func init() {
+	queryCmd.Flags().StringP("filter", "f", "", "filter expression, matches all tests when empty")
+	queryCmd.Flags().String("format", "table", "output format, one of: table, jsonl, json")
+	queryCmd.Flags().StringP("output", "o", "", "write output to file instead of stdout, json output is compressed unless --no-compress is set")
+	rootCmd.AddCommand(queryCmd)
+}
@@ -0,0 +1,71 @@
+package cmd
+
+import (
+	"github.com/semaphoreci/test-results/pkg/cli"
+	"github.com/semaphoreci/test-results/pkg/logger"
+	"github.com/semaphoreci/test-results/pkg/report"
+	"github.com/spf13/cobra"
+)
+
+// reportCmd represents the report command
+var reportCmd = &cobra.Command{
+	Use:   "report",
+	Short: "renders json report into human readable formats",
+	Long:  `Renders json report into human readable formats`,
+}
+
+// reportHTMLCmd represents the report html command
+var reportHTMLCmd = &cobra.Command{
+	Use:   "html <json-file> <html-file>",
+	Short: "renders json report into a self-contained HTML file",
+	Long: `Renders json report into a self-contained HTML file
+
+	The generated file has no external dependencies and can be opened offline
+	or published as an artifact.
+	`,
+	Args: cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		input := args[0]
+		output := args[1]
+
+		err := cli.SetLogLevel(cmd)
+		if err != nil {
+			return err
+		}
+
+		title, err := cmd.Flags().GetString("title")
+		if err != nil {
+			logger.Error("Reading flag error: %v", err)
+			return err
+		}
+
+		inFile, err := cli.CheckFile(input)
+		if err != nil {
+			return err
+		}
+
+		result, err := cli.Load(inFile)
+		if err != nil {
+			logger.Error(err.Error())
+			return err
+		}
+
+		htmlData, err := report.HTML(*result, report.HTMLOptions{Title: title})
+		if err != nil {
+			return err
+		}
+
+		_, err = cli.WriteToFilePath(htmlData, output, false)
+		if err != nil {
+			return err
+		}
+
+		return nil
+	},
+}
+
+func init() {
+	reportHTMLCmd.Flags().String("title", "", "title of the report, defaults to \"Test results\"")
+	reportCmd.AddCommand(reportHTMLCmd)
+	rootCmd.AddCommand(reportCmd)
+}
@@ -17,12 +17,13 @@ limitations under the License.
 */
 
 import (
+	"errors"
 	"fmt"
 	"os"
 
+	"github.com/semaphoreci/test-results/pkg/cli"
+	"github.com/semaphoreci/test-results/pkg/config"
 	"github.com/spf13/cobra"
-
-	homedir "github.com/mitchellh/go-homedir"
 	"github.com/spf13/viper"
 )
 
@@ -38,12 +39,50 @@ var rootCmd = &cobra.Command{
 	Version: versionString,
 	Short:   fmt.Sprintf("Semaphore 2.0 Test results CLI v%s", versionString),
 	Long:    fmt.Sprintf("Semaphore 2.0 Test results CLI v%s", versionString),
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		err := config.BindFlags(viper.GetViper(), cmd)
+		if err != nil {
+			return err
+		}
+
+		return cli.StartRun(cmd, args, versionString)
+	},
 }
 
 // Execute adds all child commands to the root command and sets flags appropriately.
 // This is called by main.main(). It only needs to happen once to the rootCmd.
 func Execute() {
-	cobra.CheckErr(rootCmd.Execute())
+	err := rootCmd.Execute()
+	if reportErr := cli.FinishRun(err); err == nil {
+		err = reportErr
+	}
+
+	var exitErr *exitError
+	if errors.As(err, &exitErr) {
+		os.Exit(exitErr.code)
+	}
+
+	cobra.CheckErr(err)
+}
+
+// exitError makes the process exit with given code instead of the default one
+type exitError struct {
+	code int
+	err  error
+}
+
+func (e *exitError) Error() string {
+	return e.err.Error()
+}
+
+func (e *exitError) Unwrap() error {
+	return e.err
+}
+
+// newExitError silences usage output for errors that are not caused by invalid invocation
+func newExitError(cmd *cobra.Command, code int, err error) error {
+	cmd.SilenceUsage = true
+	return &exitError{code: code, err: err}
 }
 
 func init() {
@@ -53,38 +92,28 @@ func init() {
 	// Cobra supports persistent flags, which, if defined here,
 	// will be global for your application.
 
-	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default is $HOME/.test-results.yaml)")
+	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default is .test-results.yml merged over $HOME/.test-results.yaml)")
 	rootCmd.PersistentFlags().BoolP("verbose", "v", false, "verbose output")
 	rootCmd.PersistentFlags().BoolP("trace", "", false, "trace output")
 	rootCmd.PersistentFlags().StringP("name", "N", "", "name of the suite")
 	rootCmd.PersistentFlags().StringP("suite-prefix", "S", "", "prefix for each suite")
 	rootCmd.PersistentFlags().StringP("parser", "p", "auto", "override parser to be used")
 	rootCmd.PersistentFlags().Bool("no-compress", false, "skip gzip compression for the output")
+	rootCmd.PersistentFlags().String("log-format", "text", "format of log lines: text or json, one object per line")
+	rootCmd.PersistentFlags().String("report-file", "", "write JSON report of the run: inputs, parsers, test counts, warnings, artifacts and timings")
+	rootCmd.PersistentFlags().String("storage", "artifact", "artifact storage: artifact, dir://<path> or s3://<bucket>/<prefix>?endpoint=<url>&region=<region>")
 
 	// Cobra also supports local flags, which will only run
 	// when this action is called directly.
 	rootCmd.Flags().BoolP("toggle", "t", false, "Help message for toggle")
 }
 
-// initConfig reads in config file and ENV variables if set.
+// initConfig reads in config files and ENV variables if set.
 func initConfig() {
-	if cfgFile != "" {
-		// Use config file from the flag.
-		viper.SetConfigFile(cfgFile)
-	} else {
-		// Find home directory.
-		home, err := homedir.Dir()
-		cobra.CheckErr(err)
-
-		// Search config in home directory with name ".test-results" (without extension).
-		viper.AddConfigPath(home)
-		viper.SetConfigName(".test-results")
-	}
-
-	viper.AutomaticEnv() // read in environment variables that match
+	files, err := config.Init(viper.GetViper(), cfgFile)
+	cobra.CheckErr(err)
 
-	// If a config file is found, read it in.
-	if err := viper.ReadInConfig(); err == nil {
-		fmt.Fprintln(os.Stderr, "Using config file:", viper.ConfigFileUsed())
+	for _, file := range files {
+		fmt.Fprintln(os.Stderr, "Using config file:", file)
 	}
 }
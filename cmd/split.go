@@ -0,0 +1,245 @@
+package cmd
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/semaphoreci/test-results/pkg/cli"
+	"github.com/semaphoreci/test-results/pkg/logger"
+	"github.com/semaphoreci/test-results/pkg/parser"
+	"github.com/semaphoreci/test-results/pkg/split"
+	"github.com/spf13/cobra"
+)
+
+// splitCmd represents the split command
+var splitCmd = &cobra.Command{
+	Use:   "split [<test-file>...]",
+	Short: "splits test files between parallel jobs based on historical durations",
+	Long: `Splits test files between parallel jobs based on historical durations
+
+	Historical durations are read from json reports at --results path or pulled from
+	the workflow artifacts of --pipeline-id pipeline. Test files are distributed between
+	SEMAPHORE_JOB_COUNT shards so that shards have balanced predicted durations,
+	and the share of SEMAPHORE_JOB_INDEX job is printed one item per line.
+
+	Test files are read from arguments or from --files-from file, use "-" for stdin.
+	`,
+	Args: cobra.MinimumNArgs(0),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		err := cli.SetLogLevel(cmd)
+		if err != nil {
+			return err
+		}
+
+		byFlag, err := cmd.Flags().GetString("by")
+		if err != nil {
+			logger.Error("Reading flag error: %v", err)
+			return err
+		}
+
+		by, err := split.ParseBy(byFlag)
+		if err != nil {
+			logger.Error(err.Error())
+			return err
+		}
+
+		format, err := cmd.Flags().GetString("format")
+		if err != nil {
+			logger.Error("Reading flag error: %v", err)
+			return err
+		}
+
+		index, err := intFlagOrEnv(cmd, "index", "SEMAPHORE_JOB_INDEX")
+		if err != nil {
+			return err
+		}
+
+		count, err := intFlagOrEnv(cmd, "count", "SEMAPHORE_JOB_COUNT")
+		if err != nil {
+			return err
+		}
+
+		if index < 1 || index > count {
+			err = fmt.Errorf("job index %d is out of range 1..%d", index, count)
+			logger.Error(err.Error())
+			return err
+		}
+
+		options := split.Options{Count: count}
+		options.DefaultDuration, err = cmd.Flags().GetDuration("default-duration")
+		if err != nil {
+			logger.Error("Reading flag error: %v", err)
+			return err
+		}
+
+		names, err := readSplitItems(cmd, args, by)
+		if err != nil {
+			return err
+		}
+
+		history, err := loadSplitHistory(cmd)
+		if err != nil {
+			return err
+		}
+
+		durations := split.Durations(*history, by)
+		if len(durations) == 0 && len(history.TestResults) > 0 {
+			logger.Warn("No tests in historical results have a %s, all items are weighted equally", by)
+		}
+
+		shards, err := split.Split(names, durations, options)
+		if err != nil {
+			logger.Error(err.Error())
+			return err
+		}
+
+		shard := shards[index-1]
+		logger.Info("Job %d/%d got %d of %d items, predicted duration: %s", index, count, len(shard.Items), len(names), shard.Duration)
+
+		switch format {
+		case "plain":
+			for _, name := range shard.Names() {
+				fmt.Fprintln(cmd.OutOrStdout(), name)
+			}
+		case "json":
+			data, err := json.MarshalIndent(map[string]interface{}{"index": index, "shards": shards}, "", "  ")
+			if err != nil {
+				logger.Error("Marshaling shards failed with: %v", err)
+				return err
+			}
+			fmt.Fprintln(cmd.OutOrStdout(), string(data))
+		default:
+			err = fmt.Errorf("unsupported output format: %s", format)
+			logger.Error(err.Error())
+			return err
+		}
+
+		return nil
+	},
+}
+
+// intFlagOrEnv reads integer flag, falling back to environment variable and then to 1
+func intFlagOrEnv(cmd *cobra.Command, name, env string) (int, error) {
+	value, err := cmd.Flags().GetInt(name)
+	if err != nil {
+		logger.Error("Reading flag error: %v", err)
+		return 0, err
+	}
+
+	if value > 0 {
+		return value, nil
+	}
+
+	envValue, found := os.LookupEnv(env)
+	if !found || envValue == "" {
+		return 1, nil
+	}
+
+	value, err = strconv.Atoi(envValue)
+	if err != nil {
+		logger.Error("Parsing %s env failed: %v", env, err)
+		return 0, err
+	}
+
+	return value, nil
+}
+
+func readSplitItems(cmd *cobra.Command, args []string, by split.By) ([]string, error) {
+	filesFrom, err := cmd.Flags().GetString("files-from")
+	if err != nil {
+		logger.Error("Reading flag error: %v", err)
+		return nil, err
+	}
+
+	items := append([]string{}, args...)
+
+	if filesFrom != "" {
+		var reader io.Reader = os.Stdin
+		if filesFrom != "-" {
+			file, err := os.Open(filepath.Clean(filesFrom))
+			if err != nil {
+				logger.Error("Input file read failed: %v", err)
+				return nil, err
+			}
+			defer file.Close()
+			reader = file
+		}
+
+		scanner := bufio.NewScanner(reader)
+		for scanner.Scan() {
+			if line := strings.TrimSpace(scanner.Text()); line != "" {
+				items = append(items, line)
+			}
+		}
+
+		if err := scanner.Err(); err != nil {
+			logger.Error("Input file read failed: %v", err)
+			return nil, err
+		}
+	}
+
+	if by == split.ByFile {
+		for i := range items {
+			items[i] = split.NormalizePath(items[i])
+		}
+	}
+
+	return items, nil
+}
+
+func loadSplitHistory(cmd *cobra.Command) (*parser.Result, error) {
+	resultsPath, err := cmd.Flags().GetString("results")
+	if err != nil {
+		logger.Error("Reading flag error: %v", err)
+		return nil, err
+	}
+
+	pipelineID, err := cmd.Flags().GetString("pipeline-id")
+	if err != nil {
+		logger.Error("Reading flag error: %v", err)
+		return nil, err
+	}
+
+	if resultsPath != "" {
+		return cli.LoadResult(resultsPath, cmd)
+	}
+
+	if pipelineID == "" {
+		logger.Warn("No historical results provided, all items are weighted equally")
+		result := parser.NewResult()
+		return &result, nil
+	}
+
+	dir, err := os.MkdirTemp("", "test-results")
+	if err != nil {
+		logger.Error("Creating temporary directory failed %v", err)
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+
+	dir, err = cli.PullArtifacts("workflow", path.Join("test-results", pipelineID), dir, cmd)
+	if err != nil {
+		return nil, err
+	}
+
+	return cli.MergeFiles(dir, cmd)
+}
+
+func init() {
+	splitCmd.Flags().String("results", "", "json report or directory with json reports to read historical durations from")
+	splitCmd.Flags().String("pipeline-id", "", "pull historical durations from workflow artifacts of given pipeline")
+	splitCmd.Flags().String("files-from", "", "read test files from given file, one per line, \"-\" reads from stdin")
+	splitCmd.Flags().String("by", "file", "split by: file, test")
+	splitCmd.Flags().Int("index", 0, "1-based index of current job, defaults to SEMAPHORE_JOB_INDEX")
+	splitCmd.Flags().Int("count", 0, "number of parallel jobs, defaults to SEMAPHORE_JOB_COUNT")
+	splitCmd.Flags().Duration("default-duration", 0, "estimated duration of items without history, defaults to average of known items")
+	splitCmd.Flags().String("format", "plain", "output format, one of: plain, json")
+	rootCmd.AddCommand(splitCmd)
+}
@@ -0,0 +1,115 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/semaphoreci/test-results/pkg/cli"
+	"github.com/semaphoreci/test-results/pkg/logger"
+	"github.com/semaphoreci/test-results/pkg/parser"
+	"github.com/semaphoreci/test-results/pkg/stats"
+	"github.com/spf13/cobra"
+)
+
+// statsCmd represents the stats command
+var statsCmd = &cobra.Command{
+	Use:   "stats <json-file-path>",
+	Short: "reports slowest tests and duration statistics",
+	Long: `Reports slowest tests and duration statistics
+
+	Lists the slowest tests, suites and files, duration percentiles per framework
+	and share of wall time spent in each suite. When --baseline report is given,
+	tests that got slower by both --slower-percent and --slower-by are highlighted.
+	Both <json-file-path> and --baseline can be a directory with json reports.
+	`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		err := cli.SetLogLevel(cmd)
+		if err != nil {
+			return err
+		}
+
+		format, err := cmd.Flags().GetString("format")
+		if err != nil {
+			logger.Error("Reading flag error: %v", err)
+			return err
+		}
+
+		baselinePath, err := cmd.Flags().GetString("baseline")
+		if err != nil {
+			logger.Error("Reading flag error: %v", err)
+			return err
+		}
+
+		options := stats.DefaultOptions()
+
+		options.Top, err = cmd.Flags().GetInt("top")
+		if err != nil {
+			logger.Error("Reading flag error: %v", err)
+			return err
+		}
+
+		options.SlowerPercent, err = cmd.Flags().GetFloat64("slower-percent")
+		if err != nil {
+			logger.Error("Reading flag error: %v", err)
+			return err
+		}
+
+		options.SlowerBy, err = cmd.Flags().GetDuration("slower-by")
+		if err != nil {
+			logger.Error("Reading flag error: %v", err)
+			return err
+		}
+
+		options.MinDuration, err = cmd.Flags().GetDuration("min-duration")
+		if err != nil {
+			logger.Error("Reading flag error: %v", err)
+			return err
+		}
+
+		result, err := cli.LoadResult(args[0], cmd)
+		if err != nil {
+			return err
+		}
+
+		var baseline *parser.Result
+		if baselinePath != "" {
+			baseline, err = cli.LoadResult(baselinePath, cmd)
+			if err != nil {
+				return err
+			}
+		}
+
+		s := stats.Compute(*result, baseline, options)
+
+		switch format {
+		case "text":
+			fmt.Fprint(cmd.OutOrStdout(), s.Text())
+		case "markdown":
+			fmt.Fprint(cmd.OutOrStdout(), s.Markdown())
+		case "json":
+			data, err := s.JSON()
+			if err != nil {
+				logger.Error("Marshaling stats failed with: %v", err)
+				return err
+			}
+			fmt.Fprintln(cmd.OutOrStdout(), string(data))
+		default:
+			err = fmt.Errorf("unsupported output format: %s", format)
+			logger.Error(err.Error())
+			return err
+		}
+
+		return nil
+	},
+}
+
+func init() {
+	defaults := stats.DefaultOptions()
+	statsCmd.Flags().String("format", "text", "output format, one of: text, markdown, json")
+	statsCmd.Flags().String("baseline", "", "json report or directory with json reports to compare durations with")
+	statsCmd.Flags().Int("top", defaults.Top, "number of slowest tests, suites and files to list")
+	statsCmd.Flags().Float64("slower-percent", defaults.SlowerPercent, "report tests slower than baseline by at least this percentage, 0 disables")
+	statsCmd.Flags().Duration("slower-by", defaults.SlowerBy, "report tests slower than baseline by at least this duration, 0 disables")
+	statsCmd.Flags().Duration("min-duration", defaults.MinDuration, "ignore tests faster than this duration when comparing with baseline")
+	rootCmd.AddCommand(statsCmd)
+}
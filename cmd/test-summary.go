@@ -0,0 +1,61 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/semaphoreci/test-results/pkg/cli"
+	"github.com/semaphoreci/test-results/pkg/report"
+	"github.com/spf13/cobra"
+)
+
+var testSummaryCmd = &cobra.Command{
+	Use:   "test-summary",
+	Short: "Generates a test results summary markdown report from compiled json report",
+	Long:  `Generates a test results summary markdown report from compiled json report`,
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		err := cli.SetLogLevel(cmd)
+		if err != nil {
+			return err
+		}
+
+		srcFile, err := cmd.Flags().GetString("src")
+		if err != nil {
+			return fmt.Errorf("src cannot be parsed: %w", err)
+		}
+
+		options := report.DefaultMarkdownOptions()
+
+		options.MaxFailures, err = cmd.Flags().GetInt("max-failures")
+		if err != nil {
+			return fmt.Errorf("max-failures cannot be parsed: %w", err)
+		}
+
+		options.Top, err = cmd.Flags().GetInt("top")
+		if err != nil {
+			return fmt.Errorf("top cannot be parsed: %w", err)
+		}
+
+		options.MessageLength, err = cmd.Flags().GetInt("message-length")
+		if err != nil {
+			return fmt.Errorf("message-length cannot be parsed: %w", err)
+		}
+
+		result, err := cli.Load(srcFile)
+		if err != nil {
+			return fmt.Errorf("failed to load test results: %w", err)
+		}
+
+		return cli.AppendToFile(args[0], report.Markdown(*result, options))
+	},
+}
+
+func init() {
+	defaults := report.DefaultMarkdownOptions()
+	testSummaryCmd.Flags().String("src", "", "compiled or merged json report to read test results from")
+	testSummaryCmd.Flags().Int("max-failures", defaults.MaxFailures, "maximum number of failed tests to list")
+	testSummaryCmd.Flags().Int("top", defaults.Top, "number of slowest tests and suites to list")
+	testSummaryCmd.Flags().Int("message-length", defaults.MessageLength, "truncate failure messages to N characters, 0 means unlimited")
+	_ = testSummaryCmd.MarkFlagRequired("src")
+	rootCmd.AddCommand(testSummaryCmd)
+}
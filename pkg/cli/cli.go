@@ -1,63 +1,44 @@
 package cli
 
 import (
-	"bytes"
-	"compress/gzip"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"io"
-	"io/fs"
 	"os"
-	"os/exec"
+	"os/signal"
 	"path/filepath"
-	"sort"
+	"runtime"
+	"strings"
+	"syscall"
+	"time"
 
+	"github.com/semaphoreci/test-results/pkg/gates"
 	"github.com/semaphoreci/test-results/pkg/logger"
+	"github.com/semaphoreci/test-results/pkg/owners"
 	"github.com/semaphoreci/test-results/pkg/parser"
-	"github.com/semaphoreci/test-results/pkg/parsers"
+	"github.com/semaphoreci/test-results/pkg/quarantine"
+	"github.com/semaphoreci/test-results/pkg/redact"
+	"github.com/semaphoreci/test-results/pkg/report"
+	"github.com/semaphoreci/test-results/pkg/runreport"
+	"github.com/semaphoreci/test-results/pkg/storage"
+	"github.com/semaphoreci/test-results/pkg/testresults"
+	"github.com/semaphoreci/test-results/pkg/trim"
 	"github.com/spf13/cobra"
-	"golang.org/x/text/cases"
-	"golang.org/x/text/language"
+	"github.com/spf13/viper"
 )
 
 // LoadFiles checks if path exists and can be `stat`ed at given `path`
 func LoadFiles(inPaths []string, ext string) ([]string, error) {
-	paths := []string{}
-
-	for _, path := range inPaths {
-		file, err := os.Stat(path)
-
-		if err != nil {
-			logger.Error("Input file read failed: %v", err)
-			return paths, err
-		}
-
-		switch file.IsDir() {
-		case true:
-			err := filepath.WalkDir(path, func(path string, d os.DirEntry, err error) error {
-				if d.Type().IsRegular() {
-					switch filepath.Ext(d.Name()) {
-					case ext:
-						paths = append(paths, path)
-					}
-				}
-				return nil
-			})
-
-			if err != nil {
-				logger.Error("Walking through directory %s failed %v", path, err)
-				return paths, err
-			}
-
-		case false:
-			switch filepath.Ext(file.Name()) {
-			case ext:
-				paths = append(paths, path)
-			}
-		}
+	paths, err := testresults.FindFiles(inPaths, ext)
+	if err != nil {
+		logger.Error("Input file read failed: %v", err)
+		return paths, err
 	}
 
-	sort.Strings(paths)
+	if runReport != nil {
+		runReport.AddInputs(paths...)
+	}
 
 	return paths, nil
 }
@@ -74,124 +55,292 @@ func CheckFile(path string) (string, error) {
 	return path, nil
 }
 
-// FindParser finds parser according to file type or flag specified by user
-func FindParser(path string, cmd *cobra.Command) (parser.Parser, error) {
+// ParseOptions reads options naming parsed test results from flags and `rules` and `tags.rspec-metadata` config keys
+func ParseOptions(cmd *cobra.Command) (testresults.ParseOptions, error) {
+	options := testresults.ParseOptions{}
 
-	parserName, err := cmd.Flags().GetString("parser")
+	var err error
+	options.Name, err = cmd.Flags().GetString("name")
 	if err != nil {
 		logger.Error("Reading flag error: %v", err)
-		return nil, err
+		return options, err
 	}
 
-	parser, err := parsers.FindParser(parserName, path)
+	options.SuitePrefix, err = cmd.Flags().GetString("suite-prefix")
 	if err != nil {
-		logger.Error("Could not find parser: %v", err)
+		logger.Error("Reading flag error: %v", err)
+		return options, err
+	}
+
+	options.Rules, err = Rules()
+	if err != nil {
+		return options, err
+	}
+
+	options.MergeStrategy, err = MergeStrategy(cmd)
+	if err != nil {
+		return options, err
+	}
+
+	options.RSpecTagMetadata = viper.GetStringSlice("tags.rspec-metadata")
+	return options, nil
+}
+
+// Rules reads per-path overrides of parser, name, suite prefix and properties from `rules` config key
+func Rules() ([]testresults.Rule, error) {
+	rules := []testresults.Rule{}
+	if err := viper.UnmarshalKey("rules", &rules); err != nil {
+		logger.Error("Reading rules from config failed: %v", err)
+		return nil, err
+	}
+
+	if err := testresults.CompileRules(rules); err != nil {
+		logger.Error("Invalid rules in config: %v", err)
 		return nil, err
 	}
-	logger.Info("Using %s parser", parser.GetName())
-	return parser, nil
+
+	return rules, nil
 }
 
-// Parse parses file at `path` with given `parser`
-func Parse(p parser.Parser, path string, cmd *cobra.Command) (parser.Result, error) {
-	result := parser.NewResult()
-	testResults := p.Parse(path)
+// MergeStrategy reads --merge-strategy flag, commands without the flag use the default strategy
+func MergeStrategy(cmd *cobra.Command) (parser.MergeStrategy, error) {
+	if cmd.Flags().Lookup("merge-strategy") == nil {
+		return parser.MergeWorst, nil
+	}
 
-	testResultsName, err := cmd.Flags().GetString("name")
+	name, err := cmd.Flags().GetString("merge-strategy")
 	if err != nil {
 		logger.Error("Reading flag error: %v", err)
-		return result, err
+		return "", err
 	}
 
-	if testResultsName != "" {
-		logger.Debug("Overriding test results name to %s", testResultsName)
-		testResults.Name = testResultsName
-		testResults.RegenerateID()
+	strategy, err := parser.ParseMergeStrategy(name)
+	if err != nil {
+		logger.Error("%v", err)
+		return "", err
 	}
 
-	if testResults.Name == "" {
-		logger.Debug("Attempting to name test results automatically")
-		testResults.Name = cases.Title(language.English, cases.NoLower).String(fmt.Sprintf("%s Suite", p.GetName()))
+	return strategy, nil
+}
+
+// ParseFiles parses files concurrently with number of workers given by --jobs flag and merges their results.
+// Results are merged in order of paths, so output does not depend on number of jobs.
+func ParseFiles(paths []string, cmd *cobra.Command) (*parser.Result, error) {
+	options, err := ParseOptions(cmd)
+	if err != nil {
+		return nil, err
 	}
 
-	suitePrefix, err := cmd.Flags().GetString("suite-prefix")
+	options.Parser, err = cmd.Flags().GetString("parser")
 	if err != nil {
 		logger.Error("Reading flag error: %v", err)
-		return result, err
+		return nil, err
 	}
 
-	if suitePrefix != "" {
-		logger.Debug("Prefixing each suite with %s", suitePrefix)
-		for suiteIdx := range testResults.Suites {
-			testResults.Suites[suiteIdx].Name = fmt.Sprintf("%s %s", suitePrefix, testResults.Suites[suiteIdx].Name)
-		}
-		testResults.RegenerateID()
+	options.Jobs, err = cmd.Flags().GetInt("jobs")
+	if err != nil {
+		logger.Error("Reading flag error: %v", err)
+		return nil, err
+	}
+	if options.Jobs <= 0 {
+		options.Jobs = runtime.NumCPU()
+	}
+
+	options.Decorate, err = DecorateOptions(cmd)
+	if err != nil {
+		return nil, err
 	}
 
-	result.TestResults = append(result.TestResults, testResults)
+	onParseError, err := cmd.Flags().GetString("on-parse-error")
+	if err != nil {
+		logger.Error("Reading flag error: %v", err)
+		return nil, err
+	}
 
-	err = DecorateResults(&result, cmd)
+	options.OnParseError, err = testresults.ParseErrorPolicy(onParseError)
 	if err != nil {
-		logger.Error("Decorating results failed with error: %v", err)
-		return result, err
+		logger.Error("%v", err)
+		return nil, err
 	}
 
+	logger.Debug("Parsing %d file(s) with %d job(s)", len(paths), options.Jobs)
+	stop := timePhase("parse")
+	files, err := testresults.ParseEach(commandContext(cmd), paths, options)
+	stop()
+	if files == nil && err != nil {
+		logger.Error("Parsing failed: %v", err)
+		return nil, err
+	}
+
+	for _, file := range files {
+		log := logger.With(logger.Fields{"file": file.Path, "parser": file.Parser})
+		recordFile(file)
+
+		if file.Rule != nil {
+			log.Info("Applied rule %s to %s", file.Rule, file.Path)
+		} else if len(options.Rules) > 0 {
+			log.Debug("No rule matches %s", file.Path)
+		}
+
+		if file.Err != nil {
+			continue
+		}
+		log.Info("Parsed %s with %s parser", file.Path, file.Parser)
+		logDecorateStats(log, file.Stats)
+	}
+
+	logParseErrors(files, options.OnParseError)
+	if err != nil {
+		return nil, err
+	}
+
+	result := testresults.MergeFiles(files, options.MergeStrategy)
+	logDecorateStats(logger.Entry{}, testresults.DecorateStats{Trim: testresults.TrimTotal(result, options.Decorate)})
+	RecordResult(result)
 	return result, nil
 }
 
-func DecorateResults(result *parser.Result, cmd *cobra.Command) error {
-	omitStdoutForPassed, err := cmd.Flags().GetBool("omit-output-for-passed")
-	if err != nil {
-		logger.Error("Reading flag omit-output-for-passed failed with error: %v", err)
-		return err
+// logParseErrors prints summary of files which could not be parsed and what happened with them
+func logParseErrors(files []testresults.File, policy testresults.ErrorPolicy) {
+	failed := testresults.Failed(files)
+	if len(failed) == 0 {
+		return
+	}
+
+	level := logger.WarnLevel
+	outcome := "kept with error status"
+	switch policy {
+	case testresults.SkipOnError:
+		outcome = "skipped"
+	case testresults.FailOnError, "":
+		level = logger.ErrorLevel
+		outcome = "failing the run"
 	}
 
-	if omitStdoutForPassed {
-		for idx := range result.TestResults {
-			for suiteIdx := range result.TestResults[idx].Suites {
-				for caseIdx := range result.TestResults[idx].Suites[suiteIdx].Tests {
-					if result.TestResults[idx].Suites[suiteIdx].Tests[caseIdx].State == "passed" {
-						result.TestResults[idx].Suites[suiteIdx].Tests[caseIdx].SystemErr = ""
-						result.TestResults[idx].Suites[suiteIdx].Tests[caseIdx].SystemOut = ""
-					}
-				}
-			}
+	logger.Log(level, "%d of %d file(s) failed to parse, %s:", len(failed), len(files), outcome)
+	for _, file := range failed {
+		kind := parser.ErrorKindOf(file.Err)
+		if kind == "" {
+			kind = "unknown"
 		}
+
+		message := file.Err.Error()
+		var fileError *parser.FileError
+		if errors.As(file.Err, &fileError) {
+			message = fileError.Err.Error()
+		}
+
+		logger.With(logger.Fields{"file": file.Path, "parser": file.Parser}).Log(level, "  %s (%s parser): %s: %s", file.Path, file.Parser, kind, message)
+	}
+}
+
+// newRedactor creates redactor from `redact.patterns`, `redact.env` and `redact.emails` config keys
+func newRedactor() (*redact.Redactor, error) {
+	return redact.New(redact.Options{
+		Patterns: viper.GetStringSlice("redact.patterns"),
+		Env:      append(append([]string{}, redact.DefaultEnv...), viper.GetStringSlice("redact.env")...),
+		Emails:   viper.GetBool("redact.emails"),
+	})
+}
+
+// DecorateOptions reads options of dropping, redacting and trimming outputs from flags. A single redactor
+// is created for the command, so the same secret gets the same placeholder in every file.
+func DecorateOptions(cmd *cobra.Command) (testresults.DecorateOptions, error) {
+	options := testresults.DecorateOptions{}
+
+	var err error
+	options.OmitOutputForPassed, err = cmd.Flags().GetBool("omit-output-for-passed")
+	if err != nil {
+		logger.Error("Reading flag omit-output-for-passed failed with error: %v", err)
+		return options, err
 	}
 
 	trimStdoutTo, err := cmd.Flags().GetInt32("trim-output-to")
 	if err != nil {
 		logger.Error("Reading flag trim-output-to failed with error: %v", err)
-		return err
+		return options, err
+	}
+
+	noRedact, err := cmd.Flags().GetBool("no-redact")
+	if err != nil {
+		logger.Error("Reading flag no-redact failed with error: %v", err)
+		return options, err
+	}
+
+	if !noRedact {
+		options.Redactor, err = newRedactor()
+		if err != nil {
+			logger.Error("Loading redaction rules failed with error: %v", err)
+			return options, err
+		}
 	}
 
-	if trimStdoutTo > 0 {
-		for idx := range result.TestResults {
-			for suiteIdx := range result.TestResults[idx].Suites {
-				if len(result.TestResults[idx].Suites[suiteIdx].SystemErr) > int(trimStdoutTo) {
-					result.TestResults[idx].Suites[suiteIdx].SystemErr = result.TestResults[idx].Suites[suiteIdx].SystemErr[:trimStdoutTo]
-				}
+	trimPassedTo, err := cmd.Flags().GetInt("trim-passed-output-to")
+	if err != nil {
+		logger.Error("Reading flag trim-passed-output-to failed with error: %v", err)
+		return options, err
+	}
 
-				if len(result.TestResults[idx].Suites[suiteIdx].SystemOut) > int(trimStdoutTo) {
-					result.TestResults[idx].Suites[suiteIdx].SystemOut = result.TestResults[idx].Suites[suiteIdx].SystemOut[:trimStdoutTo]
-				}
+	trimFailedTo, err := cmd.Flags().GetInt("trim-failed-output-to")
+	if err != nil {
+		logger.Error("Reading flag trim-failed-output-to failed with error: %v", err)
+		return options, err
+	}
 
-				for caseIdx := range result.TestResults[idx].Suites[suiteIdx].Tests {
-					if len(result.TestResults[idx].Suites[suiteIdx].Tests[caseIdx].SystemErr) > int(trimStdoutTo) {
-						result.TestResults[idx].Suites[suiteIdx].Tests[caseIdx].SystemErr = result.TestResults[idx].Suites[suiteIdx].Tests[caseIdx].SystemErr[:trimStdoutTo]
-					}
+	maxOutputSize, err := cmd.Flags().GetString("max-output-size")
+	if err != nil {
+		logger.Error("Reading flag max-output-size failed with error: %v", err)
+		return options, err
+	}
 
-					if len(result.TestResults[idx].Suites[suiteIdx].Tests[caseIdx].SystemOut) > int(trimStdoutTo) {
-						result.TestResults[idx].Suites[suiteIdx].Tests[caseIdx].SystemOut = result.TestResults[idx].Suites[suiteIdx].Tests[caseIdx].SystemOut[:trimStdoutTo]
-					}
-				}
-			}
+	options.Trim = trim.Options{Passed: int(trimStdoutTo), Failed: int(trimStdoutTo)}
+	if trimPassedTo > 0 {
+		options.Trim.Passed = trimPassedTo
+	}
+	if trimFailedTo > 0 {
+		options.Trim.Failed = trimFailedTo
+	}
+	if maxOutputSize != "" {
+		options.Trim.Total, err = trim.ParseSize(maxOutputSize)
+		if err != nil {
+			logger.Error("Parsing max-output-size failed with error: %v", err)
+			return options, err
 		}
 	}
 
+	return options, nil
+}
+
+// DecorateResults drops, redacts and trims outputs according to flags
+func DecorateResults(result *parser.Result, cmd *cobra.Command) error {
+	options, err := DecorateOptions(cmd)
+	if err != nil {
+		return err
+	}
+
+	stats, err := testresults.Decorate(commandContext(cmd), result, options)
+	if err != nil {
+		return err
+	}
+
+	logDecorateStats(logger.Entry{}, stats)
 	return nil
 }
 
+func logDecorateStats(log logger.Entry, stats testresults.DecorateStats) {
+	for _, count := range stats.Redactions {
+		if count.Test == "" {
+			log.Info("Redacted %d secret(s) in output of %s", count.Redactions, count.Suite)
+		} else {
+			log.Info("Redacted %d secret(s) in %s › %s", count.Redactions, count.Suite, count.Test)
+		}
+	}
+
+	if stats.Trim.Trimmed > 0 {
+		log.Info("Trimmed %d output(s), %s omitted", stats.Trim.Trimmed, trim.Size(stats.Trim.Omitted))
+	}
+}
+
 // Marshal provides json output for given test results
 func Marshal(testResults parser.Result) ([]byte, error) {
 	jsonData, err := json.Marshal(testResults)
@@ -202,6 +351,26 @@ func Marshal(testResults parser.Result) ([]byte, error) {
 	return jsonData, nil
 }
 
+// WriteResult saves result as JSON report, compressed unless --no-compress flag is set
+func WriteResult(result *parser.Result, path string, cmd *cobra.Command) error {
+	skipCompression, err := cmd.Flags().GetBool("no-compress")
+	if err != nil {
+		logger.Error("Reading flag error: %v", err)
+		return err
+	}
+
+	RecordResult(result)
+
+	logger.Info("Saving results to %s", path)
+	err = testresults.Write(commandContext(cmd), result, path, testresults.WriteOptions{Compress: !skipCompression})
+	if err != nil {
+		logger.Error("Output file write failed: %v", err)
+		return err
+	}
+
+	return nil
+}
+
 func WriteToFile(data []byte, file *os.File, compress bool) (string, error) {
 	return writeToFile(data, file, compress)
 }
@@ -218,6 +387,21 @@ func WriteToFilePath(data []byte, path string, compress bool) (string, error) {
 	return writeToFile(data, file, compress)
 }
 
+// AppendToFile appends text to given file, creating it when missing, e.g. a Markdown report built by several commands
+func AppendToFile(path string, text string) error {
+	file, err := os.OpenFile(filepath.Clean(path), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to open output file: %w", err)
+	}
+	defer file.Close()
+
+	if _, err := file.WriteString(text); err != nil {
+		return fmt.Errorf("failed to append to output file: %w", err)
+	}
+
+	return nil
+}
+
 // WriteToTmpFile saves data to temporary file
 func WriteToTmpFile(data []byte, compress bool) (string, error) {
 	file, err := os.CreateTemp("", "test-results")
@@ -257,227 +441,537 @@ func writeToFile(data []byte, file *os.File, compress bool) (string, error) {
 	return file.Name(), nil
 }
 
-// PushArtifacts publishes artifacts to semaphore artifact storage
-func PushArtifacts(level string, file string, destination string, cmd *cobra.Command) (string, error) {
+// NewStorage creates artifact storage selected by --storage flag or `storage` config key
+func NewStorage(cmd *cobra.Command, options storage.Options) (storage.Storage, error) {
+	storageURL, err := cmd.Flags().GetString("storage")
+	if err != nil {
+		logger.Error("Reading flag error: %v", err)
+		return nil, err
+	}
+
+	options.Verbose, err = cmd.Flags().GetBool("verbose")
+	if err != nil {
+		logger.Error("Reading flag error: %v", err)
+		return nil, err
+	}
+
+	s, err := storage.New(storageURL, options)
+	if err != nil {
+		logger.Error("Creating storage failed: %v", err)
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// UploadArtifacts pushes artifacts concurrently, retrying failed uploads, and logs a report of uploaded and failed artifacts
+func UploadArtifacts(cmd *cobra.Command, uploads []storage.Upload) error {
 	force, err := cmd.Flags().GetBool("force")
 	if err != nil {
 		logger.Error("Reading flag error: %v", err)
-		return "", err
+		return err
 	}
 
-	verbose, err := cmd.Flags().GetBool("verbose")
+	concurrency, err := cmd.Flags().GetInt("upload-concurrency")
 	if err != nil {
 		logger.Error("Reading flag error: %v", err)
-		return "", err
+		return err
+	}
+
+	retries, err := cmd.Flags().GetInt("upload-retries")
+	if err != nil {
+		logger.Error("Reading flag error: %v", err)
+		return err
+	}
+
+	timeout, err := cmd.Flags().GetDuration("upload-timeout")
+	if err != nil {
+		logger.Error("Reading flag error: %v", err)
+		return err
+	}
+
+	dryRun, err := DryRun(cmd)
+	if err != nil {
+		return err
 	}
 
-	artifactsPush := exec.Command("artifact")
-	artifactsPush.Args = append(artifactsPush.Args, "push", level, file, "-d", destination)
-	if verbose {
-		artifactsPush.Args = append(artifactsPush.Args, "-v")
+	var s storage.Storage = dryRun
+	if dryRun == nil {
+		s, err = NewStorage(cmd, storage.Options{Force: force})
+		if err != nil {
+			return err
+		}
+	}
+
+	ctx, stop := signal.NotifyContext(commandContext(cmd), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	uploader := &storage.Uploader{
+		Storage:     s,
+		Concurrency: concurrency,
+		Retries:     retries,
+		Timeout:     timeout,
+		Backoff:     time.Second,
+		MaxBackoff:  30 * time.Second,
 	}
 
-	if force {
-		artifactsPush.Args = append(artifactsPush.Args, "-f")
+	stopTiming := timePhase("upload")
+	report := uploader.Upload(ctx, uploads)
+	stopTiming()
+
+	for _, result := range report.Results {
+		recordArtifact(result)
+
+		log := logger.With(logger.Fields{"file": result.File, "artifact": result.Destination})
+		if result.Err != nil {
+			log.Error("Pushing %s to %s failed after %d attempt(s): %v", result.File, result.Destination, result.Attempts, result.Err)
+			continue
+		}
+		log.Debug("Pushed %s to %s in %s, %d attempt(s)", result.File, result.Destination, result.Duration.Round(time.Millisecond), result.Attempts)
 	}
 
-	output, err := artifactsPush.CombinedOutput()
+	verb := "uploaded"
+	if dryRun != nil {
+		verb = "written"
+	}
+
+	uploaded := len(report.Results) - len(report.Failed())
+	logger.Info("%d of %d artifact(s) %s", uploaded, len(report.Results), verb)
 
-	logger.Info("Pushing artifacts:\n$ %s", artifactsPush.String())
+	if dryRun != nil {
+		manifest, err := dryRun.WriteManifest()
+		if err != nil {
+			logger.Error("Writing manifest failed: %v", err)
+			return err
+		}
+		logger.Info("Dry run: manifest written to %s", manifest)
+	}
 
+	err = report.Err()
 	if err != nil {
-		logger.Error("Pushing artifacts failed: %v\n%s", err, string(output))
-		return "", err
+		logger.Error("Pushing artifacts failed: %v", err)
+		return err
 	}
-	return destination, nil
+
+	return nil
 }
 
-// PullArtifacts fetches artifacts from semaphore artifact storage
-func PullArtifacts(level string, remotePath string, localPath string, cmd *cobra.Command) (string, error) {
-	verbose, err := cmd.Flags().GetBool("verbose")
+// DryRun returns storage writing artifacts into --dry-run-dir when --dry-run is set, nil otherwise
+func DryRun(cmd *cobra.Command) (*storage.DryRun, error) {
+	if cmd.Flags().Lookup("dry-run") == nil {
+		return nil, nil
+	}
+
+	dryRun, err := cmd.Flags().GetBool("dry-run")
 	if err != nil {
 		logger.Error("Reading flag error: %v", err)
+		return nil, err
+	}
+
+	if !dryRun {
+		return nil, nil
+	}
+
+	dir, err := cmd.Flags().GetString("dry-run-dir")
+	if err != nil {
+		logger.Error("Reading flag error: %v", err)
+		return nil, err
+	}
+
+	return &storage.DryRun{Root: dir}, nil
+}
+
+// SemaphoreID returns value of environment variable holding a Semaphore ID, e.g. SEMAPHORE_JOB_ID.
+// In dry run a missing ID is replaced with a placeholder, so the command can be run end-to-end offline.
+func SemaphoreID(cmd *cobra.Command, env string) (string, bool) {
+	if id, found := os.LookupEnv(env); found {
+		return id, true
+	}
+
+	if dryRun, err := DryRun(cmd); err != nil || dryRun == nil {
+		return "", false
+	}
+
+	placeholder := "dry-run-" + strings.ToLower(strings.ReplaceAll(strings.TrimPrefix(env, "SEMAPHORE_"), "_", "-"))
+	logger.Info("Dry run: %s env is missing, using %s", env, placeholder)
+
+	return placeholder, true
+}
+
+// commandContext returns context of the command, cobra leaves it unset when command is executed without one
+func commandContext(cmd *cobra.Command) context.Context {
+	if ctx := cmd.Context(); ctx != nil {
+		return ctx
+	}
+
+	return context.Background()
+}
+
+// PullArtifacts fetches artifacts from artifact storage
+func PullArtifacts(level string, remotePath string, localPath string, cmd *cobra.Command) (string, error) {
+	dryRun, err := DryRun(cmd)
+	if err != nil {
 		return "", err
 	}
 
-	artifactsPush := exec.Command("artifact")
-	artifactsPush.Args = append(artifactsPush.Args, "pull", level, remotePath, "-d", localPath)
-	if verbose {
-		artifactsPush.Args = append(artifactsPush.Args, "-v")
+	if dryRun != nil {
+		err = fmt.Errorf("pulling %s is not possible in dry run, pass a directory of reports instead", remotePath)
+		logger.Error("Pulling artifacts failed: %v", err)
+		return "", err
 	}
 
-	output, err := artifactsPush.CombinedOutput()
+	s, err := NewStorage(cmd, storage.Options{})
+	if err != nil {
+		return "", err
+	}
 
-	logger.Info("Pulling artifacts:\n$ %s", artifactsPush.String())
+	ctx, stop := signal.NotifyContext(commandContext(cmd), os.Interrupt, syscall.SIGTERM)
+	defer stop()
 
+	err = s.Pull(ctx, level, remotePath, localPath)
 	if err != nil {
-		logger.Error("Pulling artifacts failed: %v\n%s", err, string(output))
+		logger.Error("Pulling artifacts failed: %v", err)
 		return "", err
 	}
 
 	return localPath, nil
 }
 
-// SetLogLevel sets log level according to flags
-func SetLogLevel(cmd *cobra.Command) error {
-	trace, err := cmd.Flags().GetBool("trace")
+// ParseQualityGates reads quality gate rules from flags
+func ParseQualityGates(cmd *cobra.Command) ([]gates.Rule, error) {
+	specs, err := cmd.Flags().GetStringSlice("fail-on")
+	if err != nil {
+		logger.Error("Reading flag error: %v", err)
+		return nil, err
+	}
+
+	rules, err := gates.ParseRules(specs)
+	if err != nil {
+		logger.Error("Parsing quality gates failed: %v", err)
+		return nil, err
+	}
+
+	return rules, nil
+}
+
+// FilterTags removes tests not matching --include-tags and --exclude-tags flags
+func FilterTags(cmd *cobra.Command, result *parser.Result) error {
+	include, err := cmd.Flags().GetStringSlice("include-tags")
 	if err != nil {
 		logger.Error("Reading flag error: %v", err)
 		return err
 	}
 
-	verbose, err := cmd.Flags().GetBool("verbose")
+	exclude, err := cmd.Flags().GetStringSlice("exclude-tags")
 	if err != nil {
 		logger.Error("Reading flag error: %v", err)
 		return err
 	}
 
-	if trace {
-		logger.SetLevel(logger.TraceLevel)
-	} else if verbose {
-		logger.SetLevel(logger.DebugLevel)
+	if len(include) == 0 && len(exclude) == 0 {
+		return nil
 	}
+
+	logger.Info("%d test(s) filtered out by tags", result.FilterTags(include, exclude))
 	return nil
 }
 
-// MergeFiles merges all json files found in path into one big blob
-func MergeFiles(path string, cmd *cobra.Command) (*parser.Result, error) {
-	verbose, err := cmd.Flags().GetBool("verbose")
+// LoadQuarantine reads quarantine list from file given by flag, nil is returned when flag is not set
+func LoadQuarantine(cmd *cobra.Command) (*quarantine.List, error) {
+	path, err := cmd.Flags().GetString("quarantine")
 	if err != nil {
+		logger.Error("Reading flag error: %v", err)
 		return nil, err
 	}
 
-	_, err = CheckFile(path)
+	if path == "" {
+		return nil, nil
+	}
+
+	list, err := quarantine.Load(path)
 	if err != nil {
-		logger.Error(err.Error())
+		logger.Error("Loading quarantine list failed: %v", err)
+		return nil, err
 	}
 
-	result := parser.NewResult()
-	fun := func(p string, d fs.DirEntry, err error) error {
-		if verbose {
-			logger.Info("[verbose] Checking file: %s", p)
-		}
+	for _, entry := range list.Expired(time.Now()) {
+		logger.Warn("Quarantine entry expired, failures are reported again: %s", entry)
+	}
 
-		if err != nil {
-			logger.Info(err.Error())
-			return err
-		}
+	return list, nil
+}
 
-		if d.Type().IsDir() {
-			return nil
-		}
+// ApplyQuarantine downgrades failures of quarantined tests, does nothing when list is nil
+func ApplyQuarantine(list *quarantine.List, result *parser.Result) {
+	if list == nil {
+		return
+	}
 
-		fs, err := d.Info()
-		if err != nil {
-			logger.Error(err.Error())
-			return err
-		}
+	matches := list.Apply(result, time.Now())
+	for _, match := range matches {
+		logger.Info("Quarantined failure of %s › %s (owner: %s)", match.Suite, match.Test, match.Entry.Owner)
+	}
 
-		if filepath.Ext(fs.Name()) != ".json" {
-			return nil
-		}
+	logger.Info("%d failure(s) quarantined", len(matches))
+}
+
+// LoadOwners builds owners resolver from CODEOWNERS file and overrides given by flags.
+// CODEOWNERS file is looked up in the working directory when flag is not set, nil is returned when nothing is found.
+func LoadOwners(cmd *cobra.Command) (*owners.Resolver, error) {
+	codeOwnersPath, err := cmd.Flags().GetString("codeowners")
+	if err != nil {
+		logger.Error("Reading flag error: %v", err)
+		return nil, err
+	}
+
+	overridesPath, err := cmd.Flags().GetString("owners")
+	if err != nil {
+		logger.Error("Reading flag error: %v", err)
+		return nil, err
+	}
+
+	root, err := os.Getwd()
+	if err != nil {
+		logger.Error("Reading working directory failed: %v", err)
+		return nil, err
+	}
+
+	if codeOwnersPath == "" {
+		codeOwnersPath = owners.FindCodeOwners(root)
+	}
 
-		inFile, err := CheckFile(p)
+	resolver := &owners.Resolver{Root: root}
+	if codeOwnersPath != "" {
+		logger.Debug("Using CODEOWNERS file: %s", codeOwnersPath)
+		resolver.CodeOwners, err = owners.LoadCodeOwners(codeOwnersPath)
 		if err != nil {
-			logger.Error(err.Error())
-			return err
+			logger.Error("Loading CODEOWNERS failed: %v", err)
+			return nil, err
 		}
+	}
 
-		newResult, err := Load(inFile)
+	if overridesPath != "" {
+		resolver.Overrides, err = owners.LoadOverrides(overridesPath)
 		if err != nil {
-			logger.Error(err.Error())
-			return err
+			logger.Error("Loading owner overrides failed: %v", err)
+			return nil, err
 		}
+	}
 
-		if verbose {
-			logger.Info("[verbose] File loaded: %s", p)
-		}
+	if resolver.CodeOwners == nil && resolver.Overrides == nil {
+		return nil, nil
+	}
 
-		result.Combine(*newResult)
-		return nil
+	return resolver, nil
+}
+
+// ApplyOwners annotates tests with their owners, does nothing when resolver is nil
+func ApplyOwners(resolver *owners.Resolver, result *parser.Result) {
+	if resolver == nil {
+		return
 	}
 
-	err = filepath.WalkDir(path, fun)
+	logger.Info("%d test(s) annotated with owners", resolver.Apply(result))
+}
+
+// PrintFailOwners writes failed tests grouped by owner when --fail-owners flag is set
+func PrintFailOwners(cmd *cobra.Command, result *parser.Result) error {
+	failOwners, err := cmd.Flags().GetBool("fail-owners")
 	if err != nil {
-		logger.Error("Test results dir listing failed: %v", err)
-		return nil, err
+		logger.Error("Reading flag error: %v", err)
+		return err
+	}
+
+	if !failOwners {
+		return nil
 	}
 
-	return &result, nil
+	_, err = fmt.Fprint(cmd.OutOrStdout(), report.OwnersText(report.FailuresByOwner(result)))
+	return err
 }
 
-// Load ...
-func Load(path string) (*parser.Result, error) {
-	var result parser.Result
-	jsonFile, err := os.Open(filepath.Clean(path))
+// SetLogLevel sets log level according to flags
+func SetLogLevel(cmd *cobra.Command) error {
+	trace, err := cmd.Flags().GetBool("trace")
 	if err != nil {
-		return nil, err
+		logger.Error("Reading flag error: %v", err)
+		return err
 	}
-	defer jsonFile.Close()
 
-	bytes, err := io.ReadAll(jsonFile)
+	verbose, err := cmd.Flags().GetBool("verbose")
 	if err != nil {
-		return nil, err
+		logger.Error("Reading flag error: %v", err)
+		return err
+	}
+
+	if trace {
+		logger.SetLevel(logger.TraceLevel)
+	} else if verbose {
+		logger.SetLevel(logger.DebugLevel)
 	}
+	return nil
+}
+
+var (
+	runReport     *runreport.Report
+	runReportFile string
+)
 
-	decompressedBytes, err := GzipDecompress(bytes)
+// StartRun applies --log-format and, when --report-file is set, starts recording run report of cmd.
+// Every log line of JSON format carries name of the command.
+func StartRun(cmd *cobra.Command, args []string, version string) error {
+	format, err := cmd.Flags().GetString("log-format")
 	if err != nil {
-		return nil, err
+		logger.Error("Reading flag error: %v", err)
+		return err
+	}
+
+	err = logger.SetFormat(logger.Format(format))
+	if err != nil {
+		logger.Error("%v", err)
+		return err
 	}
 
-	err = json.Unmarshal(decompressedBytes, &result)
+	command := strings.TrimPrefix(cmd.CommandPath(), cmd.Root().Name()+" ")
+	logger.SetFields(logger.Fields{"command": command})
 
+	reportFile, err := cmd.Flags().GetString("report-file")
 	if err != nil {
-		return nil, err
+		logger.Error("Reading flag error: %v", err)
+		return err
+	}
+
+	if reportFile != "" {
+		runReport = runreport.New(command, args, version)
+		runReportFile = reportFile
+		logger.GetLogger().AddHook(runReport)
 	}
 
-	return &result, nil
+	return nil
 }
 
-func IsGzipCompressed(bytes []byte) bool {
-	if len(bytes) < 2 {
-		return false
+// FinishRun writes run report with outcome of the command, if it was started
+func FinishRun(err error) error {
+	if runReport == nil {
+		return nil
+	}
+
+	runReport.Finish(err)
+	if writeErr := runReport.Write(runReportFile); writeErr != nil {
+		logger.Error("Writing run report to %s failed: %v", runReportFile, writeErr)
+		return writeErr
 	}
 
-	return bytes[0] == 0x1f && bytes[1] == 0x8b
+	logger.Debug("Run report written to %s", runReportFile)
+	return nil
 }
 
-// takes a slice of data bytes, compresses it and replaces with compressed bytes
-func GzipCompress(data []byte) ([]byte, error) {
-	var buf bytes.Buffer
+// RecordResult records test counts of result in run report, the last recorded result is reported
+func RecordResult(result *parser.Result) {
+	if runReport != nil {
+		runReport.SetResult(result)
+	}
+}
 
-	writer := gzip.NewWriter(&buf)
+// timePhase starts timing a phase of the run, the returned function stops it
+func timePhase(name string) func() {
+	if runReport == nil {
+		return func() {}
+	}
 
-	_, err := writer.Write(data)
-	if err != nil {
-		return data, err
+	return runReport.Time(name)
+}
+
+func recordFile(file testresults.File) {
+	if runReport == nil {
+		return
 	}
 
-	err = writer.Close()
-	if err != nil {
-		return data, err
+	recorded := runreport.File{Path: file.Path, Parser: file.Parser}
+	if file.Rule != nil {
+		recorded.Rule = file.Rule.String()
+	}
+	if file.Result != nil {
+		summary := runreport.Summarize(file.Result)
+		recorded.Summary = &summary
+	}
+	if file.Err != nil {
+		recorded.Error = file.Err.Error()
+		recorded.ErrorKind = string(parser.ErrorKindOf(file.Err))
 	}
 
-	return buf.Bytes(), nil
+	runReport.AddFile(recorded)
 }
 
-func GzipDecompress(data []byte) ([]byte, error) {
-	if !IsGzipCompressed(data) {
-		return data, nil
+func recordArtifact(result storage.UploadResult) {
+	if runReport == nil {
+		return
 	}
 
-	reader, err := gzip.NewReader(bytes.NewReader(data))
+	artifact := runreport.Artifact{
+		Level:       result.Level,
+		File:        result.File,
+		Destination: result.Destination,
+		Attempts:    result.Attempts,
+		DurationMs:  runreport.Milliseconds(result.Duration),
+	}
+	if result.Err != nil {
+		artifact.Error = result.Err.Error()
+	}
+
+	runReport.AddArtifact(artifact)
+}
+
+// MergeFiles merges all json files found in path into one big blob
+func MergeFiles(path string, cmd *cobra.Command) (*parser.Result, error) {
+	strategy, err := MergeStrategy(cmd)
 	if err != nil {
-		logger.Error("Decompression failed: %v", err)
-		return data, err
+		return nil, err
+	}
+
+	stop := timePhase("merge")
+	result, err := testresults.Merge(commandContext(cmd), path, strategy)
+	stop()
+	if err != nil {
+		logger.Error("Merging test results in %s failed: %v", path, err)
+		return nil, err
+	}
+
+	RecordResult(result)
+	return result, nil
+}
+
+// LoadResult loads json report from file or merges all json reports found in directory
+func LoadResult(path string, cmd *cobra.Command) (*parser.Result, error) {
+	result, err := testresults.Load(commandContext(cmd), path)
+	if err != nil {
+		logger.Error("Loading %s failed: %v", path, err)
+		return nil, err
 	}
-	defer reader.Close()
 
-	newData, err := io.ReadAll(reader)
+	return result, nil
+}
+
+// Load ...
+func Load(path string) (*parser.Result, error) {
+	return testresults.LoadFile(path)
+}
+
+func IsGzipCompressed(bytes []byte) bool {
+	return testresults.IsCompressed(bytes)
+}
+
+// takes a slice of data bytes, compresses it and replaces with compressed bytes
+func GzipCompress(data []byte) ([]byte, error) {
+	return testresults.Compress(data)
+}
+
+func GzipDecompress(data []byte) ([]byte, error) {
+	data, err := testresults.Decompress(data)
 	if err != nil {
 		logger.Error("Decompression failed: %v", err)
 		return data, err
 	}
 
-	return newData, nil
+	return data, nil
 }
@@ -0,0 +1,406 @@
+// Package config resolves options of test-results commands. Every flag can be set in a config file,
+// environment variables override the file and flags given on the command line override both.
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	homedir "github.com/mitchellh/go-homedir"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// Name is the name of config files, without extension
+	Name = ".test-results"
+	// EnvPrefix is prepended to environment variables, e.g. TEST_RESULTS_PARSER sets --parser
+	EnvPrefix = "TEST_RESULTS"
+)
+
+// Source tells where the effective value of an option comes from
+type Source string
+
+const (
+	// SourceFlag is a flag given on the command line
+	SourceFlag Source = "flag"
+	// SourceEnv is an environment variable
+	SourceEnv Source = "env"
+	// SourceFile is a config file
+	SourceFile Source = "file"
+	// SourceDefault is the default value of a flag
+	SourceDefault Source = "default"
+)
+
+// ignored flags are not read from config, they either locate it or don't change behaviour of commands
+var ignored = map[string]bool{"config": true, "help": true, "version": true}
+
+// Setting is the effective value of a flag
+type Setting struct {
+	Name   string
+	Value  interface{}
+	Source Source
+}
+
+// Init makes v read environment variables prefixed with EnvPrefix and reads config files into it.
+// When path is empty, $HOME/.test-results.yaml is read first and .test-results.yml of the working directory
+// is merged on top of it, so repository settings override user ones. Paths of read files are returned.
+func Init(v *viper.Viper, path string) ([]string, error) {
+	v.SetEnvPrefix(EnvPrefix)
+	v.SetEnvKeyReplacer(strings.NewReplacer("-", "_", ".", "_"))
+	v.AutomaticEnv()
+
+	files := []string{}
+	if path != "" {
+		files = append(files, path)
+	} else {
+		if home, err := homedir.Dir(); err == nil {
+			files = appendFound(files, home)
+		}
+		files = appendFound(files, ".")
+	}
+
+	for _, file := range files {
+		v.SetConfigFile(file)
+		if err := v.MergeInConfig(); err != nil {
+			return nil, fmt.Errorf("reading config file %s: %w", file, err)
+		}
+	}
+
+	return files, nil
+}
+
+// appendFound appends config file found in dir, unless it is already in files
+func appendFound(files []string, dir string) []string {
+	for _, ext := range []string{".yml", ".yaml"} {
+		path, err := filepath.Abs(filepath.Join(dir, Name+ext))
+		if err != nil {
+			continue
+		}
+
+		if _, err := os.Stat(path); err != nil {
+			continue
+		}
+
+		for _, file := range files {
+			if file == path {
+				return files
+			}
+		}
+
+		return append(files, path)
+	}
+
+	return files
+}
+
+// BindFlags sets flags of cmd which were not given on the command line from environment or config.
+// A flag is looked up under sections named after cmd and its parents up to the command defining it,
+// e.g. `publish.no-raw`. Only persistent flags of root are read from the top level, e.g. `parser`.
+func BindFlags(v *viper.Viper, cmd *cobra.Command) error {
+	var err error
+	cmd.Flags().VisitAll(func(flag *pflag.Flag) {
+		if err != nil || ignored[flag.Name] || flag.Changed {
+			return
+		}
+
+		value, source, key := lookup(v, keys(cmd, flag.Name))
+		if source == SourceDefault {
+			return
+		}
+
+		if setErr := set(flag, value); setErr != nil {
+			err = fmt.Errorf("invalid value of %s from %s: %v", key, source, setErr)
+		}
+	})
+
+	return err
+}
+
+// Settings returns effective values of flags, sorted by name. Flags are looked up as in BindFlags.
+func Settings(v *viper.Viper, cmd *cobra.Command, flags *pflag.FlagSet) ([]Setting, error) {
+	settings := []Setting{}
+
+	var err error
+	flags.VisitAll(func(flag *pflag.Flag) {
+		if err != nil || ignored[flag.Name] {
+			return
+		}
+
+		if flag.Changed {
+			settings = append(settings, Setting{Name: flag.Name, Value: typed(flag, raw(flag)), Source: SourceFlag})
+			return
+		}
+
+		value, source, key := lookup(v, keys(cmd, flag.Name))
+		if source == SourceDefault {
+			value = raw(flag)
+		}
+
+		typedValue := typed(flag, value)
+		if typedValue == nil {
+			err = fmt.Errorf("invalid value of %s from %s: %v", key, source, value)
+			return
+		}
+
+		settings = append(settings, Setting{Name: flag.Name, Value: typedValue, Source: source})
+	})
+
+	sort.SliceStable(settings, func(i, j int) bool { return settings[i].Name < settings[j].Name })
+	return settings, err
+}
+
+// Print writes effective configuration of commands as YAML. Top level holds persistent flags of root
+// and keys which are not flags, e.g. `redact` or `rules`, sections hold flags of each command.
+// Values are commented with their source.
+func Print(v *viper.Viper, root *cobra.Command, commands []*cobra.Command) ([]byte, error) {
+	document := &yaml.Node{Kind: yaml.MappingNode}
+
+	settings, err := Settings(v, root, root.PersistentFlags())
+	if err != nil {
+		return nil, err
+	}
+	if err := appendSettings(document, settings); err != nil {
+		return nil, err
+	}
+
+	// Sections of commands are printed below, other keys are printed as they are
+	known := map[string]bool{}
+	for _, cmd := range commands {
+		known[strings.SplitN(section(cmd), ".", 2)[0]] = true
+	}
+	root.PersistentFlags().VisitAll(func(flag *pflag.Flag) { known[flag.Name] = true })
+
+	others := v.AllSettings()
+	names := []string{}
+	for name := range others {
+		if !known[name] {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		if err := appendValue(document, name, others[name], ""); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, cmd := range commands {
+		settings, err := Settings(v, cmd, cmd.LocalNonPersistentFlags())
+		if err != nil {
+			return nil, err
+		}
+		if len(settings) == 0 {
+			continue
+		}
+
+		if err := appendSettings(sectionNode(document, strings.Split(section(cmd), ".")), settings); err != nil {
+			return nil, err
+		}
+	}
+
+	return yaml.Marshal(document)
+}
+
+// sectionNode returns mapping of nested section, e.g. `ingest` in `history`, creating it when missing
+func sectionNode(node *yaml.Node, path []string) *yaml.Node {
+	for _, name := range path {
+		var found *yaml.Node
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if node.Content[i].Value == name && node.Content[i+1].Kind == yaml.MappingNode {
+				found = node.Content[i+1]
+				break
+			}
+		}
+
+		if found == nil {
+			found = &yaml.Node{Kind: yaml.MappingNode}
+			node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: name}, found)
+		}
+
+		node = found
+	}
+
+	return node
+}
+
+func appendSettings(node *yaml.Node, settings []Setting) error {
+	for _, setting := range settings {
+		if err := appendValue(node, setting.Name, setting.Value, string(setting.Source)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func appendValue(node *yaml.Node, name string, value interface{}, comment string) error {
+	valueNode := &yaml.Node{}
+	if err := valueNode.Encode(value); err != nil {
+		return fmt.Errorf("encoding %s: %w", name, err)
+	}
+
+	// Empty lists are printed inline, so they can be commented like scalars
+	if valueNode.Kind == yaml.SequenceNode && len(valueNode.Content) == 0 {
+		valueNode.Style = yaml.FlowStyle
+	}
+
+	keyNode := &yaml.Node{Kind: yaml.ScalarNode, Value: name}
+	if comment != "" {
+		if valueNode.Kind == yaml.ScalarNode || valueNode.Style == yaml.FlowStyle {
+			valueNode.LineComment = comment
+		} else {
+			keyNode.LineComment = comment
+		}
+	}
+
+	node.Content = append(node.Content, keyNode, valueNode)
+	return nil
+}
+
+// section is the config key of cmd, e.g. `history.ingest`, empty for root command
+func section(cmd *cobra.Command) string {
+	names := []string{}
+	for c := cmd; c.HasParent(); c = c.Parent() {
+		names = append([]string{c.Name()}, names...)
+	}
+
+	return strings.Join(names, ".")
+}
+
+// keys are config keys of a flag of cmd, from the most specific one. Sections stop at the command
+// defining the flag, so flags of commands don't leak into other commands through the top level.
+func keys(cmd *cobra.Command, name string) []string {
+	keys := []string{}
+	for c := cmd; c.HasParent(); c = c.Parent() {
+		keys = append(keys, section(c)+"."+name)
+		if c.LocalFlags().Lookup(name) != nil {
+			return keys
+		}
+	}
+
+	return append(keys, name)
+}
+
+// EnvName is the environment variable of config key, e.g. TEST_RESULTS_PUBLISH_NO_RAW for `publish.no-raw`
+func EnvName(key string) string {
+	return EnvPrefix + "_" + strings.ToUpper(strings.NewReplacer("-", "_", ".", "_").Replace(key))
+}
+
+// lookup returns the value of the first key set in environment or, when none is, in config file
+func lookup(v *viper.Viper, keys []string) (interface{}, Source, string) {
+	for _, key := range keys {
+		if value, found := os.LookupEnv(EnvName(key)); found {
+			return value, SourceEnv, EnvName(key)
+		}
+	}
+
+	for _, key := range keys {
+		if v.InConfig(key) {
+			return v.Get(key), SourceFile, key
+		}
+	}
+
+	return nil, SourceDefault, ""
+}
+
+// raw returns value of flag as string, or list of strings for slice flags
+func raw(flag *pflag.Flag) interface{} {
+	if slice, ok := flag.Value.(pflag.SliceValue); ok {
+		return slice.GetSlice()
+	}
+
+	return flag.Value.String()
+}
+
+// toStrings converts value from config or environment into flag arguments. Lists in environment are comma separated.
+func toStrings(flag *pflag.Flag, value interface{}) ([]string, error) {
+	switch value := value.(type) {
+	case []string:
+		return value, nil
+	case []interface{}:
+		values := []string{}
+		for _, item := range value {
+			if _, ok := item.(map[string]interface{}); ok {
+				return nil, fmt.Errorf("expected a list of values")
+			}
+			values = append(values, fmt.Sprint(item))
+		}
+		return values, nil
+	case map[string]interface{}:
+		return nil, fmt.Errorf("expected a value, got a mapping")
+	case string:
+		if _, ok := flag.Value.(pflag.SliceValue); ok {
+			if value == "" {
+				return []string{}, nil
+			}
+			return strings.Split(value, ","), nil
+		}
+		return []string{value}, nil
+	default:
+		return []string{fmt.Sprint(value)}, nil
+	}
+}
+
+func set(flag *pflag.Flag, value interface{}) error {
+	values, err := toStrings(flag, value)
+	if err != nil {
+		return err
+	}
+
+	if slice, ok := flag.Value.(pflag.SliceValue); ok {
+		return slice.Replace(values)
+	}
+
+	if len(values) != 1 {
+		return fmt.Errorf("expected a single value, got %d", len(values))
+	}
+
+	return flag.Value.Set(values[0])
+}
+
+// typed converts value into the type of flag, so it is printed as YAML scalar of that type. Invalid values are nil.
+func typed(flag *pflag.Flag, value interface{}) interface{} {
+	values, err := toStrings(flag, value)
+	if err != nil {
+		return nil
+	}
+
+	if _, ok := flag.Value.(pflag.SliceValue); ok {
+		return values
+	}
+
+	if len(values) != 1 {
+		return nil
+	}
+
+	switch flag.Value.Type() {
+	case "bool":
+		b, err := strconv.ParseBool(values[0])
+		if err != nil {
+			return nil
+		}
+		return b
+	case "int", "int8", "int16", "int32", "int64", "uint", "uint8", "uint16", "uint32", "uint64":
+		i, err := strconv.ParseInt(values[0], 10, 64)
+		if err != nil {
+			return nil
+		}
+		return i
+	case "float32", "float64":
+		f, err := strconv.ParseFloat(values[0], 64)
+		if err != nil {
+			return nil
+		}
+		return f
+	default:
+		return values[0]
+	}
+}
@@ -0,0 +1,165 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeConfig(t *testing.T, dir, name, content string) string {
+	t.Helper()
+
+	path := filepath.Join(dir, name)
+	require.NoError(t, os.WriteFile(path, []byte(content), 0600))
+	return path
+}
+
+func newCommands() (*cobra.Command, *cobra.Command) {
+	root := &cobra.Command{Use: "test-results"}
+	root.PersistentFlags().String("parser", "auto", "")
+	root.PersistentFlags().String("config", "", "")
+
+	publish := &cobra.Command{Use: "publish", RunE: func(cmd *cobra.Command, args []string) error { return nil }}
+	publish.Flags().Bool("no-raw", false, "")
+	publish.Flags().Int("trim-output-to", 0, "")
+	publish.Flags().StringSlice("fail-on", []string{}, "")
+	publish.Flags().Duration("upload-timeout", time.Minute, "")
+	root.AddCommand(publish)
+
+	return root, publish
+}
+
+func Test_Init(t *testing.T) {
+	home := t.TempDir()
+	repo := t.TempDir()
+	t.Setenv("HOME", home)
+	wd, err := os.Getwd()
+	require.NoError(t, err)
+	require.NoError(t, os.Chdir(repo))
+	defer func() { require.NoError(t, os.Chdir(wd)) }()
+
+	writeConfig(t, home, ".test-results.yaml", "parser: generic\nstorage: dir:///tmp/artifacts\n")
+	writeConfig(t, repo, ".test-results.yml", "parser: golang\n")
+
+	v := viper.New()
+	files, err := Init(v, "")
+	require.NoError(t, err)
+	assert.Len(t, files, 2)
+	assert.Equal(t, "golang", v.GetString("parser"), "repository config overrides user config")
+	assert.Equal(t, "dir:///tmp/artifacts", v.GetString("storage"))
+
+	explicit := writeConfig(t, t.TempDir(), "custom.yml", "parser: mocha\n")
+	v = viper.New()
+	files, err = Init(v, explicit)
+	require.NoError(t, err)
+	assert.Equal(t, []string{explicit}, files)
+	assert.Equal(t, "mocha", v.GetString("parser"))
+
+	broken := writeConfig(t, t.TempDir(), "broken.yml", "parser: [")
+	_, err = Init(viper.New(), broken)
+	assert.Error(t, err)
+}
+
+func Test_BindFlags(t *testing.T) {
+	path := writeConfig(t, t.TempDir(), "config.yml", `
+parser: generic
+trim-output-to: 100
+upload-timeout: 30s
+publish:
+  no-raw: true
+  trim-output-to: 200
+  fail-on: [failed, "pass-rate=90"]
+`)
+
+	v := viper.New()
+	_, err := Init(v, path)
+	require.NoError(t, err)
+
+	t.Setenv("TEST_RESULTS_PARSER", "golang")
+	t.Setenv("TEST_RESULTS_TRIM_OUTPUT_TO", "400")
+	t.Setenv("TEST_RESULTS_PUBLISH_TRIM_OUTPUT_TO", "300")
+
+	root, publish := newCommands()
+	root.SetArgs([]string{"publish", "--parser", "mocha"})
+	root.PersistentPreRunE = func(cmd *cobra.Command, args []string) error { return BindFlags(v, cmd) }
+	require.NoError(t, root.Execute())
+
+	parser, _ := publish.Flags().GetString("parser")
+	assert.Equal(t, "mocha", parser, "flag overrides env and file")
+
+	trimOutputTo, _ := publish.Flags().GetInt("trim-output-to")
+	assert.Equal(t, 300, trimOutputTo, "env overrides file, even its command section")
+
+	noRaw, _ := publish.Flags().GetBool("no-raw")
+	assert.True(t, noRaw)
+
+	failOn, _ := publish.Flags().GetStringSlice("fail-on")
+	assert.Equal(t, []string{"failed", "pass-rate=90"}, failOn)
+
+	timeout, _ := publish.Flags().GetDuration("upload-timeout")
+	assert.Equal(t, time.Minute, timeout, "flags of commands are not read from the top level")
+
+	t.Setenv("TEST_RESULTS_PUBLISH_NO_RAW", "maybe")
+	root, _ = newCommands()
+	root.SetArgs([]string{"publish"})
+	root.PersistentPreRunE = func(cmd *cobra.Command, args []string) error { return BindFlags(v, cmd) }
+	root.SilenceErrors = true
+	root.SilenceUsage = true
+	err = root.Execute()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "TEST_RESULTS_PUBLISH_NO_RAW")
+}
+
+func Test_Print(t *testing.T) {
+	path := writeConfig(t, t.TempDir(), "config.yml", `
+parser: generic
+redact:
+  patterns: ["secret-[0-9]+"]
+publish:
+  fail-on: [failed]
+`)
+
+	v := viper.New()
+	_, err := Init(v, path)
+	require.NoError(t, err)
+	t.Setenv("TEST_RESULTS_PUBLISH_TRIM_OUTPUT_TO", "500")
+
+	root, publish := newCommands()
+	data, err := Print(v, root, []*cobra.Command{publish})
+	require.NoError(t, err)
+
+	assert.Equal(t, `parser: generic # file
+redact:
+    patterns:
+        - secret-[0-9]+
+publish:
+    fail-on: # file
+        - failed
+    no-raw: false # default
+    trim-output-to: 500 # env
+    upload-timeout: 1m0s # default
+`, string(data))
+}
+
+func Test_EnvName(t *testing.T) {
+	assert.Equal(t, "TEST_RESULTS_PUBLISH_NO_RAW", EnvName("publish.no-raw"))
+
+	root := &cobra.Command{Use: "test-results"}
+	history := &cobra.Command{Use: "history"}
+	ingest := &cobra.Command{Use: "ingest"}
+	history.AddCommand(ingest)
+	root.AddCommand(history)
+	root.PersistentFlags().String("parser", "auto", "")
+	history.PersistentFlags().String("store", "", "")
+	ingest.Flags().String("since", "", "")
+	assert.Equal(t, []string{"history.ingest.since"}, keys(ingest, "since"))
+	assert.Equal(t, []string{"history.ingest.store", "history.store"}, keys(ingest, "store"))
+	assert.Equal(t, []string{"history.ingest.parser", "history.parser", "parser"}, keys(ingest, "parser"))
+	assert.Equal(t, []string{"parser"}, keys(root, "parser"))
+}
@@ -0,0 +1,283 @@
+package diff
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/semaphoreci/test-results/pkg/logger"
+	"github.com/semaphoreci/test-results/pkg/parser"
+	"github.com/semaphoreci/test-results/pkg/report"
+)
+
+// Options ...
+type Options struct {
+	// SlowerPercent is minimal relative slowdown for a test to be reported as slower, 0 disables the check
+	SlowerPercent float64
+	// SlowerMin is minimal absolute slowdown for a test to be reported as slower, both thresholds have to be reached
+	SlowerMin time.Duration
+}
+
+// DefaultOptions ...
+func DefaultOptions() Options {
+	return Options{SlowerPercent: 50, SlowerMin: time.Second}
+}
+
+// Change describes single test present in at least one of compared results
+type Change struct {
+	ID           string        `json:"id"`
+	Name         string        `json:"name"`
+	Classname    string        `json:"classname"`
+	File         string        `json:"file"`
+	Suite        string        `json:"suite"`
+	BaseState    parser.State  `json:"baseState,omitempty"`
+	HeadState    parser.State  `json:"headState,omitempty"`
+	BaseDuration time.Duration `json:"baseDuration"`
+	HeadDuration time.Duration `json:"headDuration"`
+}
+
+// Diff holds differences between two results
+type Diff struct {
+	NewlyFailing []Change `json:"newlyFailing"`
+	NewlyPassing []Change `json:"newlyPassing"`
+	NewlySkipped []Change `json:"newlySkipped"`
+	Added        []Change `json:"added"`
+	Removed      []Change `json:"removed"`
+	Slower       []Change `json:"slower"`
+}
+
+type entry struct {
+	suite string
+	test  parser.Test
+}
+
+// Compare finds differences between base and head results, tests are matched by their IDs
+func Compare(base, head parser.Result, options Options) Diff {
+	d := Diff{
+		NewlyFailing: []Change{},
+		NewlyPassing: []Change{},
+		NewlySkipped: []Change{},
+		Added:        []Change{},
+		Removed:      []Change{},
+		Slower:       []Change{},
+	}
+
+	baseTests := index(base)
+	headTests := index(head)
+
+	for id, h := range headTests {
+		b, found := baseTests[id]
+		if !found {
+			d.Added = append(d.Added, newChange(nil, &h))
+			continue
+		}
+
+		change := newChange(&b, &h)
+
+		switch {
+		case !isFailing(b.test.State) && isFailing(h.test.State):
+			d.NewlyFailing = append(d.NewlyFailing, change)
+		case isFailing(b.test.State) && h.test.State == parser.StatePassed:
+			d.NewlyPassing = append(d.NewlyPassing, change)
+		case !isSkipped(b.test.State) && isSkipped(h.test.State):
+			d.NewlySkipped = append(d.NewlySkipped, change)
+		}
+
+		if !isSkipped(h.test.State) && report.Slower(b.test.Duration, h.test.Duration, options.SlowerPercent, options.SlowerMin) {
+			d.Slower = append(d.Slower, change)
+		}
+	}
+
+	for id, b := range baseTests {
+		if _, found := headTests[id]; !found {
+			d.Removed = append(d.Removed, newChange(&b, nil))
+		}
+	}
+
+	for _, changes := range [][]Change{d.NewlyFailing, d.NewlyPassing, d.NewlySkipped, d.Added, d.Removed, d.Slower} {
+		sortChanges(changes)
+	}
+
+	// Tests slowed down by the same amount keep the order by name
+	sort.SliceStable(d.Slower, func(i, j int) bool {
+		return d.Slower[i].HeadDuration-d.Slower[i].BaseDuration > d.Slower[j].HeadDuration-d.Slower[j].BaseDuration
+	})
+
+	return d
+}
+
+// Regressions returns newly failing tests and added tests that fail
+func (d *Diff) Regressions() []Change {
+	regressions := append([]Change{}, d.NewlyFailing...)
+	for _, change := range d.Added {
+		if isFailing(change.HeadState) {
+			regressions = append(regressions, change)
+		}
+	}
+
+	return regressions
+}
+
+// HasChanges ...
+func (d *Diff) HasChanges() bool {
+	return len(d.NewlyFailing)+len(d.NewlyPassing)+len(d.NewlySkipped)+len(d.Added)+len(d.Removed)+len(d.Slower) > 0
+}
+
+// JSON renders diff as json
+func (d *Diff) JSON() ([]byte, error) {
+	data, err := json.MarshalIndent(d, "", "  ")
+	if err != nil {
+		logger.Error("Marshaling diff failed with: %v", err)
+		return nil, err
+	}
+
+	return append(data, '\n'), nil
+}
+
+// Text renders diff as plain text
+func (d *Diff) Text() string {
+	if !d.HasChanges() {
+		return "No changes found\n"
+	}
+
+	out := ""
+	for _, section := range d.sections() {
+		if len(section.changes) == 0 {
+			continue
+		}
+
+		out += fmt.Sprintf("%s (%d):\n", section.title, len(section.changes))
+		for _, change := range section.changes {
+			out += fmt.Sprintf("  - %s › %s (%s)\n", change.Suite, change.DisplayName(), section.detail(change))
+		}
+		out += "\n"
+	}
+
+	return out
+}
+
+// Markdown renders diff as markdown
+func (d *Diff) Markdown() string {
+	out := "## 🔍 Test Results Diff\n\n"
+	if !d.HasChanges() {
+		return out + "No changes found\n"
+	}
+
+	out += "| Change | Tests |\n| --- | --- |\n"
+	for _, section := range d.sections() {
+		out += fmt.Sprintf("| %s | %d |\n", section.title, len(section.changes))
+	}
+	out += "\n"
+
+	for _, section := range d.sections() {
+		if len(section.changes) == 0 {
+			continue
+		}
+
+		out += fmt.Sprintf("### %s\n\n", section.title)
+		out += "| Test | Suite | Change |\n| --- | --- | --- |\n"
+		for _, change := range section.changes {
+			out += fmt.Sprintf("| %s | %s | %s |\n", report.EscapeCell(change.DisplayName()), report.EscapeCell(change.Suite), section.detail(change))
+		}
+		out += "\n"
+	}
+
+	return out
+}
+
+// DisplayName returns test name prefixed with its classname
+func (c *Change) DisplayName() string {
+	if c.Classname != "" && !strings.HasPrefix(c.Name, c.Classname) {
+		return c.Classname + " " + c.Name
+	}
+
+	return c.Name
+}
+
+type section struct {
+	title   string
+	changes []Change
+	detail  func(Change) string
+}
+
+func (d *Diff) sections() []section {
+	states := func(c Change) string { return fmt.Sprintf("%s → %s", c.BaseState, c.HeadState) }
+
+	return []section{
+		{"Newly failing", d.NewlyFailing, states},
+		{"Newly passing", d.NewlyPassing, states},
+		{"Newly skipped", d.NewlySkipped, states},
+		{"Added", d.Added, func(c Change) string { return string(c.HeadState) }},
+		{"Removed", d.Removed, func(c Change) string { return string(c.BaseState) }},
+		{"Slower", d.Slower, func(c Change) string {
+			return fmt.Sprintf("%s → %s", c.BaseDuration.Round(time.Millisecond), c.HeadDuration.Round(time.Millisecond))
+		}},
+	}
+}
+
+func index(result parser.Result) map[string]entry {
+	tests := map[string]entry{}
+
+	for _, testResults := range result.TestResults {
+		for _, suite := range testResults.Suites {
+			for _, test := range suite.Tests {
+				found, exists := tests[test.ID]
+				if exists && isFailing(found.test.State) {
+					continue
+				}
+				tests[test.ID] = entry{suite: suite.Name, test: test}
+			}
+		}
+	}
+
+	return tests
+}
+
+func newChange(base, head *entry) Change {
+	e := head
+	if e == nil {
+		e = base
+	}
+
+	change := Change{
+		ID:        e.test.ID,
+		Name:      e.test.Name,
+		Classname: e.test.Classname,
+		File:      e.test.File,
+		Suite:     e.suite,
+	}
+
+	if base != nil {
+		change.BaseState = base.test.State
+		change.BaseDuration = base.test.Duration
+	}
+
+	if head != nil {
+		change.HeadState = head.test.State
+		change.HeadDuration = head.test.Duration
+	}
+
+	return change
+}
+
+func sortChanges(changes []Change) {
+	sort.SliceStable(changes, func(i, j int) bool {
+		if changes[i].Suite != changes[j].Suite {
+			return changes[i].Suite < changes[j].Suite
+		}
+		if changes[i].DisplayName() != changes[j].DisplayName() {
+			return changes[i].DisplayName() < changes[j].DisplayName()
+		}
+		return changes[i].ID < changes[j].ID
+	})
+}
+
+func isFailing(state parser.State) bool {
+	return state == parser.StateFailed || state == parser.StateError
+}
+
+func isSkipped(state parser.State) bool {
+	return state == parser.StateSkipped || state == parser.StateDisabled
+}
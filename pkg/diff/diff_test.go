@@ -0,0 +1,146 @@
+package diff_test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/semaphoreci/test-results/pkg/diff"
+	"github.com/semaphoreci/test-results/pkg/parser"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testCase struct {
+	name     string
+	state    parser.State
+	duration time.Duration
+}
+
+func newResult(tests ...testCase) parser.Result {
+	testResults := parser.NewTestResults()
+	testResults.Name = "Unit Tests"
+	testResults.EnsureID()
+
+	suite := parser.NewSuite()
+	suite.Name = "foo"
+	suite.EnsureID(testResults)
+
+	for _, tc := range tests {
+		test := parser.NewTest()
+		test.Name = tc.name
+		test.State = tc.state
+		test.Duration = tc.duration
+		test.EnsureID(suite)
+		suite.AppendTest(test)
+	}
+
+	testResults.Suites = append(testResults.Suites, suite)
+	testResults.Aggregate()
+
+	result := parser.NewResult()
+	result.TestResults = append(result.TestResults, testResults)
+
+	return result
+}
+
+func names(changes []diff.Change) []string {
+	result := []string{}
+	for _, change := range changes {
+		result = append(result, change.Name)
+	}
+	return result
+}
+
+func Test_Compare(t *testing.T) {
+	base := newResult(
+		testCase{"breaks", parser.StatePassed, time.Second},
+		testCase{"fixed", parser.StateFailed, time.Second},
+		testCase{"gets skipped", parser.StatePassed, time.Second},
+		testCase{"removed", parser.StatePassed, time.Second},
+		testCase{"slows down", parser.StatePassed, time.Second},
+		testCase{"slows down a bit", parser.StatePassed, time.Second},
+		testCase{"slows down relatively", parser.StatePassed, 10 * time.Millisecond},
+		testCase{"unchanged", parser.StatePassed, time.Second},
+	)
+
+	head := newResult(
+		testCase{"breaks", parser.StateError, time.Second},
+		testCase{"fixed", parser.StatePassed, time.Second},
+		testCase{"gets skipped", parser.StateSkipped, 0},
+		testCase{"added", parser.StatePassed, time.Second},
+		testCase{"added broken", parser.StateFailed, time.Second},
+		testCase{"slows down", parser.StatePassed, 3 * time.Second},
+		testCase{"slows down a bit", parser.StatePassed, 1200 * time.Millisecond},
+		testCase{"slows down relatively", parser.StatePassed, 100 * time.Millisecond},
+		testCase{"unchanged", parser.StatePassed, time.Second},
+	)
+
+	d := diff.Compare(base, head, diff.DefaultOptions())
+
+	assert.Equal(t, []string{"breaks"}, names(d.NewlyFailing))
+	assert.Equal(t, []string{"fixed"}, names(d.NewlyPassing))
+	assert.Equal(t, []string{"gets skipped"}, names(d.NewlySkipped))
+	assert.Equal(t, []string{"added", "added broken"}, names(d.Added))
+	assert.Equal(t, []string{"removed"}, names(d.Removed))
+	assert.Equal(t, []string{"slows down"}, names(d.Slower))
+	assert.Equal(t, []string{"breaks", "added broken"}, names(d.Regressions()))
+
+	assert.Equal(t, parser.StatePassed, d.NewlyFailing[0].BaseState)
+	assert.Equal(t, parser.StateError, d.NewlyFailing[0].HeadState)
+	assert.Equal(t, 3*time.Second, d.Slower[0].HeadDuration)
+
+	t.Run("thresholds are configurable", func(t *testing.T) {
+		d := diff.Compare(base, head, diff.Options{SlowerPercent: 10, SlowerMin: 50 * time.Millisecond})
+		assert.Equal(t, []string{"slows down", "slows down a bit", "slows down relatively"}, names(d.Slower))
+	})
+}
+
+func Test_Compare_SlowerTies(t *testing.T) {
+	base, head := []testCase{}, []testCase{}
+	for _, name := range []string{"e", "b", "d", "a", "c"} {
+		base = append(base, testCase{name, parser.StatePassed, time.Second})
+		head = append(head, testCase{name, parser.StatePassed, 3 * time.Second})
+	}
+	head[4].duration = 4 * time.Second
+
+	for i := 0; i < 10; i++ {
+		d := diff.Compare(newResult(base...), newResult(head...), diff.DefaultOptions())
+		assert.Equal(t, []string{"c", "a", "b", "d", "e"}, names(d.Slower))
+	}
+}
+
+func Test_Compare_NoChanges(t *testing.T) {
+	result := newResult(testCase{"foo", parser.StatePassed, time.Second})
+	d := diff.Compare(result, result, diff.DefaultOptions())
+
+	assert.False(t, d.HasChanges())
+	assert.Empty(t, d.Regressions())
+	assert.Equal(t, "No changes found\n", d.Text())
+	assert.Contains(t, d.Markdown(), "No changes found")
+}
+
+func Test_Diff_Render(t *testing.T) {
+	base := newResult(testCase{"breaks", parser.StatePassed, time.Second}, testCase{"removed", parser.StatePassed, 0})
+	head := newResult(testCase{"breaks", parser.StateFailed, time.Second})
+	d := diff.Compare(base, head, diff.DefaultOptions())
+
+	t.Run("text", func(t *testing.T) {
+		assert.Equal(t, "Newly failing (1):\n  - foo › breaks (passed → failed)\n\nRemoved (1):\n  - foo › removed (passed)\n\n", d.Text())
+	})
+
+	t.Run("markdown", func(t *testing.T) {
+		out := d.Markdown()
+		assert.Contains(t, out, "| Newly failing | 1 |\n| Newly passing | 0 |")
+		assert.Contains(t, out, "### Newly failing\n\n| Test | Suite | Change |\n| --- | --- | --- |\n| breaks | foo | passed → failed |")
+	})
+
+	t.Run("json", func(t *testing.T) {
+		data, err := d.JSON()
+		require.NoError(t, err)
+
+		decoded := diff.Diff{}
+		require.NoError(t, json.Unmarshal(data, &decoded))
+		assert.Equal(t, d, decoded)
+	})
+}
@@ -2,17 +2,82 @@ package fileloader
 
 import (
 	"bytes"
-	"io"
 	"os"
+	"path/filepath"
+	"sync"
+	"time"
 
 	"github.com/semaphoreci/test-results/pkg/logger"
 )
 
-var readers map[string]*bytes.Reader = make(map[string]*bytes.Reader)
+// entry is cached content of a file, valid as long as size and modification time of the file are unchanged
+type entry struct {
+	data    []byte
+	size    int64
+	modTime time.Time
+}
+
+var (
+	mu    sync.Mutex
+	files = map[string]entry{}
+)
 
-// Load reader from internal buffer if path was already loaded or create new one if not
+// Load reader from internal buffer if path was already loaded or create new one if not.
+// Every call returns a new reader, so readers can be used from multiple goroutines.
 func Load(path string, reader *bytes.Reader) (*bytes.Reader, bool) {
-	return decode(path, reader)
+	data := make([]byte, reader.Size())
+	_, _ = reader.ReadAt(data, 0)
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	cached, exists := files[path]
+	if exists && int64(len(cached.data)) == reader.Size() {
+		logger.Debug("Path read from cache")
+		return bytes.NewReader(cached.data), true
+	}
+
+	files[path] = entry{data: data, size: int64(len(data))}
+	logger.Debug("No path in cache")
+	return bytes.NewReader(data), false
+}
+
+// ReadFile returns content of file at path. Content is cached until the file changes or Forget is called,
+// so parsers checking whether they are applicable don't read the file again.
+func ReadFile(path string) ([]byte, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+
+	mu.Lock()
+	cached, exists := files[path]
+	mu.Unlock()
+
+	if exists && cached.size == info.Size() && cached.modTime.Equal(info.ModTime()) {
+		logger.Debug("Path read from cache")
+		return cached.data, nil
+	}
+
+	data, err := os.ReadFile(filepath.Clean(path))
+	if err != nil {
+		return nil, err
+	}
+
+	mu.Lock()
+	files[path] = entry{data: data, size: info.Size(), modTime: info.ModTime()}
+	mu.Unlock()
+
+	logger.Debug("No path in cache")
+	return data, nil
+}
+
+// Forget drops cached content of path
+func Forget(path string) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	delete(files, path)
 }
 
 // Ensure puts reader data into temporary created file.
@@ -37,19 +102,3 @@ func Ensure(reader *bytes.Reader) (fileName string) {
 
 	return
 }
-
-func decode(path string, reader *bytes.Reader) (*bytes.Reader, bool) {
-	foundReader, exists := readers[path]
-	if exists && foundReader != nil && foundReader.Size() == reader.Size() {
-		logger.Debug("Path read from cache")
-		_, err := foundReader.Seek(0, io.SeekStart)
-		if err != nil {
-			logger.Error("Cannot seek to start of reader: %v", err)
-		}
-
-		return foundReader, true
-	}
-	readers[path] = reader
-	logger.Debug("No path in cache")
-	return reader, false
-}
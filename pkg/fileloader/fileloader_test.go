@@ -2,9 +2,14 @@ package fileloader
 
 import (
 	"bytes"
+	"os"
+	"path/filepath"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestLoad(t *testing.T) {
@@ -19,3 +24,33 @@ func TestLoad(t *testing.T) {
 	assert.Equal(t, true, found2, "Decoders should be the same")
 	assert.Equal(t, false, found3, "Decoders should be the same")
 }
+
+func TestReadFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "junit.xml")
+	require.NoError(t, os.WriteFile(path, []byte("<testsuite/>"), 0600))
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			data, err := ReadFile(path)
+			assert.NoError(t, err)
+			assert.Equal(t, "<testsuite/>", string(data))
+		}()
+	}
+	wg.Wait()
+
+	modTime := time.Now().Add(time.Minute)
+	require.NoError(t, os.WriteFile(path, []byte("<testsuites/>"), 0600))
+	require.NoError(t, os.Chtimes(path, modTime, modTime))
+
+	data, err := ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "<testsuites/>", string(data), "changed file should be read again")
+
+	Forget(path)
+	require.NoError(t, os.Remove(path))
+	_, err = ReadFile(path)
+	assert.Error(t, err)
+}
@@ -0,0 +1,131 @@
+package flaky
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/semaphoreci/test-results/pkg/history"
+	"github.com/semaphoreci/test-results/pkg/parser"
+	"github.com/semaphoreci/test-results/pkg/report"
+)
+
+// Options ...
+type Options struct {
+	// Threshold is minimal flip rate in percent for a test to be reported as flaky
+	Threshold float64
+	// MinRuns is minimal number of executed runs needed to judge flip rate
+	MinRuns int
+	// Window is number of most recent runs of each test taken into account, all runs when zero
+	Window int
+	// OrganizationURL is used to build links to jobs, e.g. https://org.semaphoreci.com
+	OrganizationURL string
+}
+
+// DefaultOptions ...
+func DefaultOptions() Options {
+	return Options{Threshold: 20, MinRuns: 3, Window: 30}
+}
+
+// Test is a test detected as flaky
+type Test struct {
+	history.Analysis
+	Framework string `json:"framework"`
+	// FlakeRate is percentage of consecutive recent runs with different outcome
+	FlakeRate float64 `json:"flakeRate"`
+	// LastSeenURL links to the job of the most recent run
+	LastSeenURL string `json:"lastSeenUrl"`
+	// Reasons explains why the test was reported
+	Reasons []string `json:"reasons"`
+}
+
+// Detect groups records by test ID and returns flaky tests ordered by flake rate
+func Detect(records []parser.TestResult, options Options) []Test {
+	byID := map[string][]parser.TestResult{}
+	for _, record := range records {
+		byID[record.TestId] = append(byID[record.TestId], record)
+	}
+
+	tests := []Test{}
+	for _, runs := range byID {
+		history.Sort(runs)
+		if options.Window > 0 && len(runs) > options.Window {
+			runs = runs[len(runs)-options.Window:]
+		}
+
+		analysis := history.Analyze(runs)
+		test := Test{
+			Analysis:    analysis,
+			Framework:   runs[len(runs)-1].Framework,
+			FlakeRate:   analysis.FlipRate,
+			LastSeenURL: JobURL(options.OrganizationURL, analysis.LastJobId),
+			Reasons:     []string{},
+		}
+
+		if len(analysis.FlakyShas) > 0 {
+			test.Reasons = append(test.Reasons, fmt.Sprintf("passed and failed on the same commit (%d)", len(analysis.FlakyShas)))
+		}
+
+		if analysis.Passed+analysis.Failed >= options.MinRuns && analysis.FlipRate > 0 && analysis.FlipRate >= options.Threshold {
+			test.Reasons = append(test.Reasons, fmt.Sprintf("outcome flipped in %.1f%% of recent runs", analysis.FlipRate))
+		}
+
+		if len(test.Reasons) > 0 {
+			tests = append(tests, test)
+		}
+	}
+
+	sort.SliceStable(tests, func(i, j int) bool {
+		if tests[i].FlakeRate != tests[j].FlakeRate {
+			return tests[i].FlakeRate > tests[j].FlakeRate
+		}
+		if len(tests[i].FlakyShas) != len(tests[j].FlakyShas) {
+			return len(tests[i].FlakyShas) > len(tests[j].FlakyShas)
+		}
+		if tests[i].Name != tests[j].Name {
+			return tests[i].Name < tests[j].Name
+		}
+		return tests[i].TestId < tests[j].TestId
+	})
+
+	return tests
+}
+
+// JobURL returns link to the job in Semaphore UI, empty when it can't be built
+func JobURL(organizationURL, jobID string) string {
+	if organizationURL == "" || jobID == "" {
+		return ""
+	}
+
+	return strings.TrimSuffix(organizationURL, "/") + "/jobs/" + jobID
+}
+
+// JSON renders flaky tests as json
+func JSON(tests []Test) ([]byte, error) {
+	return json.MarshalIndent(tests, "", "  ")
+}
+
+// Markdown renders flaky tests as markdown table
+func Markdown(tests []Test) string {
+	out := "## ❄️ Flaky tests\n\n"
+	if len(tests) == 0 {
+		return out + "No flaky tests found\n"
+	}
+
+	out += fmt.Sprintf("Found %d flaky test(s).\n\n", len(tests))
+	out += "| Test | Flake rate | Failure rate | Runs | Reasons | Last seen |\n| --- | --- | --- | --- | --- | --- |\n"
+	for _, test := range tests {
+		lastSeen := test.LastSeen.Format(time.RFC3339)
+		if test.LastSeenURL != "" {
+			lastSeen = fmt.Sprintf("[%s](%s)", lastSeen, test.LastSeenURL)
+		}
+
+		out += fmt.Sprintf("| %s<br>`%s` | %.1f%% | %.1f%% | %d | %s | %s |\n",
+			report.EscapeCell(test.Name), test.TestId, test.FlakeRate, test.FailureRate, test.Runs,
+			report.EscapeCell(strings.Join(test.Reasons, "; ")), lastSeen)
+	}
+
+	return out
+}
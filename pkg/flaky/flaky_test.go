@@ -0,0 +1,78 @@
+package flaky_test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/semaphoreci/test-results/pkg/flaky"
+	"github.com/semaphoreci/test-results/pkg/parser"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func runs(id string, sha string, states ...parser.State) []parser.TestResult {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	records := []parser.TestResult{}
+	for i, state := range states {
+		records = append(records, parser.TestResult{
+			TestId:    id,
+			Name:      "Suite › " + id,
+			Framework: "rspec",
+			GitSha:    sha,
+			JobId:     id + "-job",
+			State:     state,
+			Timestamp: start.Add(time.Duration(i) * time.Hour),
+		})
+	}
+
+	return records
+}
+
+func Test_Detect(t *testing.T) {
+	p, f, s := parser.StatePassed, parser.StateFailed, parser.StateSkipped
+
+	records := []parser.TestResult{}
+	records = append(records, runs("stable", "", p, p, p, p)...)
+	records = append(records, runs("broken", "", p, p, p, p, f, f, f, f)...)
+	records = append(records, runs("flipping", "", p, f, p, f, p)...)
+	records = append(records, runs("retried", "abc", f, p)...)
+	records = append(records, runs("skipped", "", p, s, f)...)
+
+	options := flaky.DefaultOptions()
+	options.OrganizationURL = "https://org.semaphoreci.com/"
+	tests := flaky.Detect(records, options)
+
+	require.Len(t, tests, 2)
+	assert.Equal(t, "retried", tests[0].TestId, "same commit outcome change should be reported regardless of the run count")
+	assert.Equal(t, 100.0, tests[0].FlakeRate)
+	assert.Equal(t, []string{"abc"}, tests[0].FlakyShas)
+	assert.Equal(t, "https://org.semaphoreci.com/jobs/retried-job", tests[0].LastSeenURL)
+
+	assert.Equal(t, "flipping", tests[1].TestId)
+	assert.Equal(t, 100.0, tests[1].FlakeRate)
+	assert.Equal(t, "rspec", tests[1].Framework)
+	assert.Len(t, tests[1].Reasons, 1)
+
+	options.Window = 2
+	tests = flaky.Detect(records, options)
+	require.Len(t, tests, 1, "flip rate should be judged on recent runs only")
+	assert.Equal(t, "retried", tests[0].TestId)
+}
+
+func Test_Output(t *testing.T) {
+	tests := flaky.Detect(runs("retried", "abc", parser.StateFailed, parser.StatePassed), flaky.DefaultOptions())
+
+	markdown := flaky.Markdown(tests)
+	assert.Contains(t, markdown, "Found 1 flaky test(s).")
+	assert.Contains(t, markdown, "| Suite › retried<br>`retried` | 100.0% | 50.0% | 2 | passed and failed on the same commit (1) | 2024-01-01T01:00:00Z |")
+	assert.Equal(t, "## ❄️ Flaky tests\n\nNo flaky tests found\n", flaky.Markdown(nil))
+
+	data, err := flaky.JSON(tests)
+	require.NoError(t, err)
+
+	decoded := []map[string]interface{}{}
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	assert.Equal(t, "retried", decoded[0]["testId"])
+	assert.Equal(t, 100.0, decoded[0]["flakeRate"])
+}
@@ -0,0 +1,177 @@
+package gates
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/semaphoreci/test-results/pkg/parser"
+)
+
+// Kind identifies class of a quality gate rule
+type Kind string
+
+const (
+	// KindFailed trips when number of failed tests exceeds the threshold
+	KindFailed Kind = "failed"
+	// KindErrors trips when number of errored tests exceeds the threshold
+	KindErrors Kind = "errors"
+	// KindPassRate trips when pass rate in percent drops below the threshold
+	KindPassRate Kind = "pass-rate"
+	// KindSkippedRatio trips when percentage of skipped and disabled tests exceeds the threshold
+	KindSkippedRatio Kind = "skipped-ratio"
+	// KindDuration trips when total duration exceeds the budget
+	KindDuration Kind = "duration"
+	// KindNoTests trips when no tests were found
+	KindNoTests Kind = "no-tests"
+)
+
+// exitCodes maps each rule class to a distinct process exit code
+var exitCodes = map[Kind]int{
+	KindFailed:       2,
+	KindErrors:       3,
+	KindPassRate:     4,
+	KindSkippedRatio: 5,
+	KindDuration:     6,
+	KindNoTests:      7,
+}
+
+// Rule ...
+type Rule struct {
+	Kind      Kind
+	Threshold float64
+	Budget    time.Duration
+	raw       string
+}
+
+// Violation describes a rule that tripped
+type Violation struct {
+	Rule    Rule
+	Message string
+}
+
+// ParseRules parses list of rules in `<kind>[=<value>]` form
+func ParseRules(specs []string) ([]Rule, error) {
+	rules := []Rule{}
+	for _, spec := range specs {
+		rule, err := ParseRule(spec)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, rule)
+	}
+
+	return rules, nil
+}
+
+// ParseRule parses single rule in `<kind>[=<value>]` form
+func ParseRule(spec string) (Rule, error) {
+	name, value, hasValue := strings.Cut(strings.TrimSpace(spec), "=")
+	rule := Rule{Kind: Kind(strings.TrimSpace(name)), raw: spec}
+	value = strings.TrimSpace(value)
+
+	var err error
+	switch rule.Kind {
+	case KindFailed, KindErrors:
+		if hasValue {
+			rule.Threshold, err = strconv.ParseFloat(value, 64)
+		}
+	case KindPassRate, KindSkippedRatio:
+		if !hasValue {
+			return rule, fmt.Errorf("rule %q requires a percentage value, e.g. %s=90", spec, rule.Kind)
+		}
+		rule.Threshold, err = strconv.ParseFloat(strings.TrimSuffix(value, "%"), 64)
+	case KindDuration:
+		if !hasValue {
+			return rule, fmt.Errorf("rule %q requires a duration value, e.g. %s=10m", spec, rule.Kind)
+		}
+		rule.Budget, err = time.ParseDuration(value)
+	case KindNoTests:
+		if hasValue {
+			return rule, fmt.Errorf("rule %q does not accept a value", spec)
+		}
+	default:
+		return rule, fmt.Errorf("unknown rule %q, must be one of: failed, errors, pass-rate, skipped-ratio, duration, no-tests", spec)
+	}
+
+	if err != nil {
+		return rule, fmt.Errorf("invalid value in rule %q: %w", spec, err)
+	}
+
+	return rule, nil
+}
+
+// String ...
+func (r Rule) String() string {
+	return r.raw
+}
+
+// ExitCode returns process exit code for the rule class
+func (r Rule) ExitCode() int {
+	return exitCodes[r.Kind]
+}
+
+// Check returns violation if rule trips for given summary
+func (r Rule) Check(summary parser.Summary) *Violation {
+	var message string
+
+	switch r.Kind {
+	case KindFailed:
+		if float64(summary.Failed) > r.Threshold {
+			message = fmt.Sprintf("%d test(s) failed, allowed: %g", summary.Failed, r.Threshold)
+		}
+	case KindErrors:
+		if float64(summary.Error) > r.Threshold {
+			message = fmt.Sprintf("%d test(s) errored, allowed: %g", summary.Error, r.Threshold)
+		}
+	case KindPassRate:
+		// Without executed tests there is no pass rate, such reports are caught by the no-tests rule
+		if summary.Executed() > 0 && summary.PassRate() < r.Threshold {
+			message = fmt.Sprintf("pass rate %.2f%% is below %g%%", summary.PassRate(), r.Threshold)
+		}
+	case KindSkippedRatio:
+		if ratio := skippedRatio(summary); ratio > r.Threshold {
+			message = fmt.Sprintf("%.2f%% of tests were skipped, allowed: %g%%", ratio, r.Threshold)
+		}
+	case KindDuration:
+		if summary.Duration > r.Budget {
+			message = fmt.Sprintf("tests took %s, budget: %s", summary.Duration, r.Budget)
+		}
+	case KindNoTests:
+		if summary.Total == 0 {
+			message = "no tests were found"
+		}
+	}
+
+	if message == "" {
+		return nil
+	}
+
+	return &Violation{Rule: r, Message: message}
+}
+
+// Evaluate checks all rules against merged summary of the result
+func Evaluate(result parser.Result, rules []Rule) []Violation {
+	summary := parser.Summary{}
+	for i := range result.TestResults {
+		summary.Merge(&result.TestResults[i].Summary)
+	}
+
+	violations := []Violation{}
+	for _, rule := range rules {
+		if violation := rule.Check(summary); violation != nil {
+			violations = append(violations, *violation)
+		}
+	}
+
+	return violations
+}
+
+func skippedRatio(summary parser.Summary) float64 {
+	if summary.Total == 0 {
+		return 0
+	}
+
+	return float64(summary.Skipped+summary.Disabled) / float64(summary.Total) * 100
+}
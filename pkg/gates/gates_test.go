@@ -0,0 +1,102 @@
+package gates_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/semaphoreci/test-results/pkg/gates"
+	"github.com/semaphoreci/test-results/pkg/parser"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_ParseRule(t *testing.T) {
+	testCases := []struct {
+		spec string
+		want gates.Rule
+		err  string
+	}{
+		{spec: "failed", want: gates.Rule{Kind: gates.KindFailed}},
+		{spec: "failed=3", want: gates.Rule{Kind: gates.KindFailed, Threshold: 3}},
+		{spec: "errors=1", want: gates.Rule{Kind: gates.KindErrors, Threshold: 1}},
+		{spec: "pass-rate=95.5", want: gates.Rule{Kind: gates.KindPassRate, Threshold: 95.5}},
+		{spec: "skipped-ratio=10%", want: gates.Rule{Kind: gates.KindSkippedRatio, Threshold: 10}},
+		{spec: "duration=10m", want: gates.Rule{Kind: gates.KindDuration, Budget: 10 * time.Minute}},
+		{spec: "no-tests", want: gates.Rule{Kind: gates.KindNoTests}},
+		{spec: "pass-rate", err: `rule "pass-rate" requires a percentage value, e.g. pass-rate=90`},
+		{spec: "duration=fast", err: `invalid value in rule "duration=fast"`},
+		{spec: "no-tests=1", err: `rule "no-tests=1" does not accept a value`},
+		{spec: "flaky", err: `unknown rule "flaky"`},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.spec, func(t *testing.T) {
+			rule, err := gates.ParseRule(tc.spec)
+			if tc.err != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tc.err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.want.Kind, rule.Kind)
+			assert.Equal(t, tc.want.Threshold, rule.Threshold)
+			assert.Equal(t, tc.want.Budget, rule.Budget)
+			assert.Equal(t, tc.spec, rule.String())
+		})
+	}
+}
+
+func Test_Rule_ExitCode(t *testing.T) {
+	codes := map[int]bool{}
+	for _, spec := range []string{"failed", "errors", "pass-rate=1", "skipped-ratio=1", "duration=1s", "no-tests"} {
+		rule, err := gates.ParseRule(spec)
+		require.NoError(t, err)
+		assert.Greater(t, rule.ExitCode(), 1, "exit codes 0 and 1 are reserved")
+		codes[rule.ExitCode()] = true
+	}
+
+	assert.Len(t, codes, 6, "each rule class should have a distinct exit code")
+}
+
+func Test_Evaluate(t *testing.T) {
+	result := parser.NewResult()
+	result.TestResults = []parser.TestResults{
+		{Summary: parser.Summary{Total: 10, Passed: 6, Failed: 1, Skipped: 2, Error: 1, Duration: time.Minute}},
+		{Summary: parser.Summary{Total: 10, Passed: 10, Duration: time.Minute}},
+	}
+
+	rules, err := gates.ParseRules([]string{"failed", "errors=1", "pass-rate=90", "skipped-ratio=5", "duration=90s", "no-tests"})
+	require.NoError(t, err)
+
+	violations := gates.Evaluate(result, rules)
+	require.Len(t, violations, 4)
+
+	assert.Equal(t, gates.KindFailed, violations[0].Rule.Kind)
+	assert.Equal(t, "1 test(s) failed, allowed: 0", violations[0].Message)
+	assert.Equal(t, gates.KindPassRate, violations[1].Rule.Kind)
+	assert.Equal(t, "pass rate 88.89% is below 90%", violations[1].Message)
+	assert.Equal(t, gates.KindSkippedRatio, violations[2].Rule.Kind)
+	assert.Equal(t, "10.00% of tests were skipped, allowed: 5%", violations[2].Message)
+	assert.Equal(t, gates.KindDuration, violations[3].Rule.Kind)
+	assert.Equal(t, "tests took 2m0s, budget: 1m30s", violations[3].Message)
+
+	t.Run("no tests", func(t *testing.T) {
+		rules, err := gates.ParseRules([]string{"no-tests", "failed"})
+		require.NoError(t, err)
+
+		violations := gates.Evaluate(parser.NewResult(), rules)
+		require.Len(t, violations, 1)
+		assert.Equal(t, "no tests were found", violations[0].Message)
+	})
+
+	t.Run("pass rate without executed tests", func(t *testing.T) {
+		rules, err := gates.ParseRules([]string{"pass-rate=90"})
+		require.NoError(t, err)
+
+		skipped := parser.NewResult()
+		skipped.TestResults = []parser.TestResults{{Summary: parser.Summary{Total: 3, Skipped: 2, Disabled: 1}}}
+
+		assert.Empty(t, gates.Evaluate(skipped, rules), "pass rate is not checked when all tests were skipped")
+	})
+}
@@ -0,0 +1,50 @@
+// Package glob translates glob patterns into regular expressions
+package glob
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Name compiles pattern matching a whole name, where `*` matches any sequence of characters
+// and `?` matches a single character, e.g. `Test*` or `spec/*_spec.rb`
+func Name(pattern string) *regexp.Regexp {
+	return regexp.MustCompile("^" + translate(pattern, false) + "$")
+}
+
+// Path compiles pattern matching a whole slash separated path, where `*` and `?` don't match `/`
+// and `**` matches any number of directories, e.g. `reports/**/junit.xml`
+func Path(pattern string) *regexp.Regexp {
+	return regexp.MustCompile("^" + PathExpr(pattern) + "$")
+}
+
+// PathExpr translates path pattern as in Path into an unanchored regexp, so it can be embedded in a larger one
+func PathExpr(pattern string) string {
+	return translate(pattern, true)
+}
+
+func translate(pattern string, path bool) string {
+	var b strings.Builder
+	for i := 0; i < len(pattern); i++ {
+		switch {
+		case path && strings.HasPrefix(pattern[i:], "**/"):
+			b.WriteString("(?:.*/)?")
+			i += 2
+		case path && strings.HasPrefix(pattern[i:], "**"):
+			b.WriteString(".*")
+			i++
+		case path && pattern[i] == '*':
+			b.WriteString("[^/]*")
+		case path && pattern[i] == '?':
+			b.WriteString("[^/]")
+		case pattern[i] == '*':
+			b.WriteString(".*")
+		case pattern[i] == '?':
+			b.WriteString(".")
+		default:
+			b.WriteString(regexp.QuoteMeta(pattern[i : i+1]))
+		}
+	}
+
+	return b.String()
+}
@@ -0,0 +1,49 @@
+package glob_test
+
+import (
+	"testing"
+
+	"github.com/semaphoreci/test-results/pkg/glob"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Name(t *testing.T) {
+	assert.True(t, glob.Name("spec/*_spec.rb").MatchString("spec/models/user_spec.rb"))
+	assert.True(t, glob.Name("Test?").MatchString("TestA"))
+	assert.False(t, glob.Name("Test?").MatchString("TestAB"))
+	assert.False(t, glob.Name("a.b").MatchString("axb"))
+}
+
+func Test_Path(t *testing.T) {
+	testCases := map[string]map[string]bool{
+		"reports/*.xml": {
+			"reports/junit.xml":    true,
+			"reports/go/junit.xml": false,
+		},
+		"reports/**/junit.xml": {
+			"reports/junit.xml":       true,
+			"reports/go/a/junit.xml":  true,
+			"other/reports/junit.xml": false,
+		},
+		"reports/**": {
+			"reports/php/unit.xml": true,
+			"reports":              false,
+		},
+		"junit-?.xml": {
+			"junit-1.xml":   true,
+			"junit-/.xml":   false,
+			"junit-12.xml":  false,
+			"junitx1.xml":   false,
+			"junit-1.xmls":  false,
+			"a/junit-1.xml": false,
+		},
+	}
+
+	for pattern, paths := range testCases {
+		for path, expected := range paths {
+			assert.Equal(t, expected, glob.Path(pattern).MatchString(path), "%s: %s", pattern, path)
+		}
+	}
+
+	assert.Equal(t, `docs/(?:.*/)?[^/]*\.md`, glob.PathExpr("docs/**/*.md"))
+}
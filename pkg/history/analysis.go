@@ -0,0 +1,203 @@
+package history
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/semaphoreci/test-results/pkg/parser"
+)
+
+// Analysis summarizes history of a single test
+type Analysis struct {
+	TestId string `json:"testId"`
+	Name   string `json:"name"`
+
+	Runs    int `json:"runs"`
+	Passed  int `json:"passed"`
+	Failed  int `json:"failed"`
+	Skipped int `json:"skipped"`
+
+	// FailureRate is percentage of executed runs that failed
+	FailureRate float64 `json:"failureRate"`
+	// FlipRate is percentage of consecutive executed runs with different outcome
+	FlipRate float64 `json:"flipRate"`
+	// FlakyShas lists commits on which the test both passed and failed
+	FlakyShas []string `json:"flakyShas"`
+
+	AverageDuration time.Duration `json:"averageDuration"`
+	LastDuration    time.Duration `json:"lastDuration"`
+	// DurationTrend is relative change of the average duration of newer half of runs compared to the older half
+	DurationTrend float64 `json:"durationTrend"`
+
+	// FirstFailingSha is the commit where the current failure streak started, empty when last run did not fail
+	FirstFailingSha string       `json:"firstFailingSha"`
+	FailingSince    *time.Time   `json:"failingSince,omitempty"`
+	LastState       parser.State `json:"lastState"`
+	LastSeen        time.Time    `json:"lastSeen"`
+	LastJobId       string       `json:"lastJobId"`
+}
+
+// IsFailure returns true for states that count as a failed execution, including quarantined failures
+func IsFailure(state parser.State) bool {
+	return state == parser.StateFailed || state == parser.StateError || state == parser.StateQuarantined
+}
+
+// IsExecuted returns true for states where the test actually ran
+func IsExecuted(state parser.State) bool {
+	return state == parser.StatePassed || IsFailure(state)
+}
+
+// Analyze computes flakiness, duration trend and first failing commit from test history
+func Analyze(records []parser.TestResult) Analysis {
+	records = append([]parser.TestResult{}, records...)
+	Sort(records)
+
+	analysis := Analysis{FlakyShas: []string{}}
+	if len(records) == 0 {
+		return analysis
+	}
+
+	last := records[len(records)-1]
+	analysis.TestId = last.TestId
+	analysis.Name = last.Name
+	analysis.LastState = last.State
+	analysis.LastSeen = last.Timestamp
+	analysis.LastJobId = last.JobId
+	analysis.LastDuration = last.Duration
+	analysis.Runs = len(records)
+
+	executed := []parser.TestResult{}
+	outcomes := map[string]map[bool]bool{}
+	for _, record := range records {
+		switch {
+		case IsFailure(record.State):
+			analysis.Failed++
+		case record.State == parser.StatePassed:
+			analysis.Passed++
+		default:
+			analysis.Skipped++
+		}
+
+		if !IsExecuted(record.State) {
+			continue
+		}
+		executed = append(executed, record)
+
+		if record.GitSha != "" {
+			if outcomes[record.GitSha] == nil {
+				outcomes[record.GitSha] = map[bool]bool{}
+			}
+			outcomes[record.GitSha][IsFailure(record.State)] = true
+		}
+	}
+
+	for sha, outcome := range outcomes {
+		if outcome[true] && outcome[false] {
+			analysis.FlakyShas = append(analysis.FlakyShas, sha)
+		}
+	}
+	sort.Strings(analysis.FlakyShas)
+
+	if len(executed) == 0 {
+		return analysis
+	}
+
+	analysis.FailureRate = float64(analysis.Failed) / float64(len(executed)) * 100
+	analysis.FlipRate = FlipRate(executed)
+	analysis.AverageDuration = averageDuration(executed)
+
+	half := len(executed) / 2
+	if half > 0 {
+		older := averageDuration(executed[:half])
+		newer := averageDuration(executed[len(executed)-half:])
+		if older > 0 {
+			analysis.DurationTrend = float64(newer-older) / float64(older) * 100
+		}
+	}
+
+	if IsFailure(executed[len(executed)-1].State) {
+		first := len(executed) - 1
+		for first > 0 && IsFailure(executed[first-1].State) {
+			first--
+		}
+
+		analysis.FirstFailingSha = executed[first].GitSha
+		since := executed[first].Timestamp
+		analysis.FailingSince = &since
+	}
+
+	return analysis
+}
+
+// FlipRate returns percentage of consecutive executed runs where the outcome changed
+func FlipRate(records []parser.TestResult) float64 {
+	previous := -1
+	flips := 0
+	transitions := 0
+	for _, record := range records {
+		if !IsExecuted(record.State) {
+			continue
+		}
+
+		failed := 0
+		if IsFailure(record.State) {
+			failed = 1
+		}
+
+		if previous != -1 {
+			transitions++
+			if previous != failed {
+				flips++
+			}
+		}
+		previous = failed
+	}
+
+	if transitions == 0 {
+		return 0
+	}
+
+	return float64(flips) / float64(transitions) * 100
+}
+
+// Text renders analysis as human readable text
+func (a *Analysis) Text() string {
+	if a.Runs == 0 {
+		return "No history found\n"
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s (%s)\n", a.Name, a.TestId)
+	fmt.Fprintf(&b, "  Runs:          %d (%d passed, %d failed, %d skipped)\n", a.Runs, a.Passed, a.Failed, a.Skipped)
+	fmt.Fprintf(&b, "  Failure rate:  %.1f%%\n", a.FailureRate)
+	fmt.Fprintf(&b, "  Flip rate:     %.1f%%\n", a.FlipRate)
+	if len(a.FlakyShas) > 0 {
+		fmt.Fprintf(&b, "  Flaky on:      %s\n", strings.Join(a.FlakyShas, ", "))
+	}
+	fmt.Fprintf(&b, "  Duration:      avg %s, last %s, trend %+.1f%%\n", a.AverageDuration, a.LastDuration, a.DurationTrend)
+	fmt.Fprintf(&b, "  Last run:      %s at %s", a.LastState, a.LastSeen.Format(time.RFC3339))
+	if a.LastJobId != "" {
+		fmt.Fprintf(&b, " in job %s", a.LastJobId)
+	}
+	b.WriteString("\n")
+	if a.FirstFailingSha != "" {
+		fmt.Fprintf(&b, "  Failing since: %s (%s)\n", a.FirstFailingSha, a.FailingSince.Format(time.RFC3339))
+	}
+
+	return b.String()
+}
+
+func averageDuration(records []parser.TestResult) time.Duration {
+	if len(records) == 0 {
+		return 0
+	}
+
+	var total time.Duration
+	for _, record := range records {
+		total += record.Duration
+	}
+
+	return total / time.Duration(len(records))
+}
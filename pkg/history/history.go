@@ -0,0 +1,174 @@
+package history
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/semaphoreci/test-results/pkg/parser"
+)
+
+// DefaultPath is the location of history store used when none is given
+const DefaultPath = ".test-results/history.jsonl"
+
+// Store is an append-only history of test executions kept as JSON lines
+type Store struct {
+	Path string
+}
+
+// NewStore ...
+func NewStore(path string) *Store {
+	return &Store{Path: path}
+}
+
+// Records converts every test found in result into a history row
+func Records(result parser.Result, timestamp time.Time) []parser.TestResult {
+	records := []parser.TestResult{}
+	for _, testResults := range result.TestResults {
+		for _, suite := range testResults.Suites {
+			for _, test := range suite.Tests {
+				records = append(records, parser.NewTestResult(testResults, suite, test, timestamp))
+			}
+		}
+	}
+
+	return records
+}
+
+// Load reads all rows from the store, missing store is treated as empty history
+func (s *Store) Load() ([]parser.TestResult, error) {
+	records := []parser.TestResult{}
+
+	file, err := os.Open(filepath.Clean(s.Path))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return records, nil
+		}
+		return nil, err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+
+	line := 0
+	for scanner.Scan() {
+		line++
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+
+		record := parser.TestResult{}
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			return nil, fmt.Errorf("%s:%d: %v", s.Path, line, err)
+		}
+		records = append(records, record)
+	}
+
+	return records, scanner.Err()
+}
+
+// Append adds records to the store, rows already ingested from the same job are skipped.
+// Rows without job ID are skipped when the same test was ingested with the same timestamp.
+// Number of appended rows is returned.
+func (s *Store) Append(records []parser.TestResult) (int, error) {
+	existing, err := s.Load()
+	if err != nil {
+		return 0, err
+	}
+
+	seen := map[string]bool{}
+	for _, record := range existing {
+		seen[key(record)] = true
+	}
+
+	err = os.MkdirAll(filepath.Dir(s.Path), 0755)
+	if err != nil {
+		return 0, err
+	}
+
+	// #nosec
+	file, err := os.OpenFile(s.Path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+
+	writer := bufio.NewWriter(file)
+	appended := 0
+	for _, record := range records {
+		k := key(record)
+		if seen[k] {
+			continue
+		}
+		seen[k] = true
+
+		data, err := json.Marshal(record)
+		if err != nil {
+			return appended, err
+		}
+
+		_, err = writer.Write(append(data, '\n'))
+		if err != nil {
+			return appended, err
+		}
+		appended++
+	}
+
+	return appended, writer.Flush()
+}
+
+// Find returns history of tests matching given ID or name, oldest first
+func (s *Store) Find(idOrName string) ([]parser.TestResult, error) {
+	records, err := s.Load()
+	if err != nil {
+		return nil, err
+	}
+
+	found := []parser.TestResult{}
+	for _, record := range records {
+		if record.TestId == idOrName || record.Name == idOrName {
+			found = append(found, record)
+		}
+	}
+
+	Sort(found)
+	return found, nil
+}
+
+// Dedup drops repeated rows of the same test from the same job, keeping the first one.
+// Rows without job ID are repeated when they have the same timestamp, as in Append.
+func Dedup(records []parser.TestResult) []parser.TestResult {
+	seen := map[string]bool{}
+	unique := []parser.TestResult{}
+	for _, record := range records {
+		k := key(record)
+		if seen[k] {
+			continue
+		}
+		seen[k] = true
+		unique = append(unique, record)
+	}
+
+	return unique
+}
+
+// Sort orders records chronologically
+func Sort(records []parser.TestResult) {
+	sort.SliceStable(records, func(i, j int) bool {
+		return records[i].Timestamp.Before(records[j].Timestamp)
+	})
+}
+
+// key identifies a row for deduplication, rows without job ID are identified by their timestamp
+func key(record parser.TestResult) string {
+	if record.JobId == "" {
+		return record.TestId + "@" + record.Timestamp.Format(time.RFC3339Nano)
+	}
+
+	return record.TestId + "/" + record.JobId
+}
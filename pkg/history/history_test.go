@@ -0,0 +1,158 @@
+package history_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/semaphoreci/test-results/pkg/history"
+	"github.com/semaphoreci/test-results/pkg/parser"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newResult(jobID, sha, startedAt string, states ...parser.State) parser.Result {
+	testResults := parser.NewTestResults()
+	testResults.Name = "Unit"
+	testResults.Framework = "golang"
+	testResults.EnsureID()
+
+	suite := parser.NewSuite()
+	suite.Name = "pkg/foo"
+	suite.EnsureID(testResults)
+
+	for i, state := range states {
+		test := parser.NewTest()
+		test.Name = []string{"TestA", "TestB", "TestC"}[i]
+		test.State = state
+		test.Duration = time.Duration(i+1) * time.Second
+		test.SemEnv.JobId = jobID
+		test.SemEnv.GitRefSha = sha
+		test.SemEnv.JobStartedAt = startedAt
+		test.EnsureID(suite)
+		suite.AppendTest(test)
+	}
+
+	testResults.Suites = append(testResults.Suites, suite)
+	return parser.Result{TestResults: []parser.TestResults{testResults}}
+}
+
+func Test_Records(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	records := history.Records(newResult("job-1", "abc", "1700000000", parser.StatePassed, parser.StateFailed), now)
+	require.Len(t, records, 2)
+	assert.Equal(t, "pkg/foo › TestB", records[1].Name)
+	assert.Equal(t, "golang", records[1].Framework)
+	assert.Equal(t, "abc", records[1].GitSha)
+	assert.Equal(t, "job-1", records[1].JobId)
+	assert.Equal(t, parser.StateFailed, records[1].State)
+	assert.Equal(t, 2*time.Second, records[1].Duration)
+	assert.Equal(t, time.Unix(1700000000, 0).UTC(), records[1].Timestamp)
+
+	records = history.Records(newResult("job-1", "abc", "", parser.StatePassed), now)
+	assert.Equal(t, now, records[0].Timestamp, "should fall back to given timestamp")
+}
+
+func Test_Store(t *testing.T) {
+	store := history.NewStore(filepath.Join(t.TempDir(), "nested", "history.jsonl"))
+
+	records, err := store.Load()
+	require.NoError(t, err)
+	assert.Empty(t, records, "missing store should be empty")
+
+	first := history.Records(newResult("job-1", "abc", "1700000000", parser.StatePassed, parser.StateFailed), time.Now())
+	appended, err := store.Append(first)
+	require.NoError(t, err)
+	assert.Equal(t, 2, appended)
+
+	appended, err = store.Append(first)
+	require.NoError(t, err)
+	assert.Equal(t, 0, appended, "rows from already ingested job should be skipped")
+
+	second := history.Records(newResult("job-2", "def", "1600000000", parser.StatePassed, parser.StatePassed), time.Now())
+	appended, err = store.Append(second)
+	require.NoError(t, err)
+	assert.Equal(t, 2, appended)
+
+	withoutJob := history.Records(newResult("", "abc", "", parser.StatePassed), time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	appended, err = store.Append(append(withoutJob, withoutJob...))
+	require.NoError(t, err)
+	assert.Equal(t, 1, appended, "rows without job ID should be deduplicated by timestamp")
+
+	appended, err = store.Append(history.Records(newResult("", "abc", "", parser.StatePassed), time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)))
+	require.NoError(t, err)
+	assert.Equal(t, 1, appended)
+
+	found, err := store.Find(first[1].TestId)
+	require.NoError(t, err)
+	require.Len(t, found, 2)
+	assert.Equal(t, "job-2", found[0].JobId, "history should be sorted chronologically")
+	assert.Equal(t, "job-1", found[1].JobId)
+
+	found, err = store.Find("pkg/foo › TestA")
+	require.NoError(t, err)
+	assert.Len(t, found, 4)
+
+	require.NoError(t, os.WriteFile(store.Path, []byte("{not json}\n"), 0600))
+	_, err = store.Load()
+	assert.Error(t, err)
+}
+
+func Test_Analyze(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	record := func(i int, sha string, state parser.State, duration time.Duration) parser.TestResult {
+		return parser.TestResult{TestId: "id", Name: "TestA", GitSha: sha, JobId: sha + "-job", State: state, Duration: duration, Timestamp: start.Add(time.Duration(i) * time.Hour)}
+	}
+
+	analysis := history.Analyze([]parser.TestResult{
+		record(5, "e", parser.StateFailed, 4*time.Second),
+		record(0, "a", parser.StatePassed, time.Second),
+		record(1, "b", parser.StateFailed, time.Second),
+		record(2, "b", parser.StatePassed, time.Second),
+		record(3, "c", parser.StateSkipped, 0),
+		record(4, "d", parser.StateError, 4*time.Second),
+	})
+
+	assert.Equal(t, 6, analysis.Runs)
+	assert.Equal(t, 2, analysis.Passed)
+	assert.Equal(t, 3, analysis.Failed)
+	assert.Equal(t, 1, analysis.Skipped)
+	assert.Equal(t, 60.0, analysis.FailureRate)
+	assert.Equal(t, 75.0, analysis.FlipRate, "passed→failed→passed→failed→failed: 3 of 4 transitions flip")
+	assert.Equal(t, []string{"b"}, analysis.FlakyShas)
+	assert.Equal(t, "d", analysis.FirstFailingSha)
+	assert.Equal(t, start.Add(4*time.Hour), *analysis.FailingSince)
+	assert.Equal(t, parser.StateFailed, analysis.LastState)
+	assert.Equal(t, "e-job", analysis.LastJobId)
+	assert.Equal(t, 4*time.Second, analysis.LastDuration)
+	assert.InDelta(t, 300.0, analysis.DurationTrend, 0.001)
+	assert.Contains(t, analysis.Text(), "Failing since: d")
+}
+
+func Test_Analyze_Passing(t *testing.T) {
+	analysis := history.Analyze([]parser.TestResult{{TestId: "id", State: parser.StatePassed}})
+	assert.Empty(t, analysis.FirstFailingSha)
+	assert.Nil(t, analysis.FailingSince)
+	assert.Equal(t, 0.0, analysis.FlipRate)
+
+	empty := history.Analyze(nil)
+	assert.Equal(t, "No history found\n", empty.Text())
+}
+
+func Test_Dedup(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	stored := history.Records(newResult("job-1", "abc", "1700000000", parser.StatePassed, parser.StateFailed), now)
+	report := history.Records(newResult("job-1", "abc", "1700000000", parser.StatePassed, parser.StateFailed), now)
+	other := history.Records(newResult("job-2", "abc", "1700000100", parser.StatePassed), now)
+	withoutJob := history.Records(newResult("", "abc", "", parser.StatePassed), now)
+
+	records := append(append(append(append(stored, report...), other...), withoutJob...), withoutJob...)
+	unique := history.Dedup(records)
+
+	require.Len(t, unique, 4)
+	assert.Equal(t, stored, unique[:2])
+	assert.Equal(t, "job-2", unique[2].JobId)
+	assert.Equal(t, "", unique[3].JobId)
+}
@@ -0,0 +1,259 @@
+// Package jobreport combines commands, resource usage and test results of a job into a single report.
+// The report is a model which can be rendered as Markdown or marshalled to JSON for other renderers.
+package jobreport
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/semaphoreci/test-results/pkg/metrics"
+	"github.com/semaphoreci/test-results/pkg/parser"
+	"github.com/semaphoreci/test-results/pkg/report"
+	"github.com/semaphoreci/test-results/pkg/runreport"
+)
+
+// DefaultTestCommands match commands running tests with common test runners
+var DefaultTestCommands = []string{
+	`(^|[\s;&|/(])(go test|gotestsum|rspec|pytest|jest|mocha|vitest|phpunit|mix test|cargo test|make test|(npm|yarn|pnpm)( run)? test|mvn .*test|gradlew? .*test)($|[\s;&|)])`,
+}
+
+// Options ...
+type Options struct {
+	// TestCommands match directives of commands which run tests
+	TestCommands []*regexp.Regexp
+	// MaxFailures limits number of failed tests listed in the report, 0 lists none
+	MaxFailures int
+	// Top limits number of slowest tests listed in the report, 0 lists none
+	Top int
+	// MessageLength truncates failure messages to N characters, 0 means unlimited
+	MessageLength int
+}
+
+// DefaultOptions ...
+func DefaultOptions() Options {
+	markdown := report.DefaultMarkdownOptions()
+	options := Options{MaxFailures: markdown.MaxFailures, Top: markdown.Top, MessageLength: markdown.MessageLength}
+
+	for _, pattern := range DefaultTestCommands {
+		options.TestCommands = append(options.TestCommands, regexp.MustCompile(pattern))
+	}
+
+	return options
+}
+
+// CompileTestCommands compiles patterns matching commands which run tests
+func CompileTestCommands(patterns []string) ([]*regexp.Regexp, error) {
+	compiled := []*regexp.Regexp{}
+	for _, pattern := range patterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid test command pattern %q: %w", pattern, err)
+		}
+		compiled = append(compiled, re)
+	}
+
+	return compiled, nil
+}
+
+// Report of a job. Durations are in milliseconds, as in run reports.
+type Report struct {
+	StartedAt  time.Time `json:"startedAt"`
+	FinishedAt time.Time `json:"finishedAt"`
+	DurationMs float64   `json:"durationMs"`
+	// TestRunDurationMs is the total duration of commands which run tests
+	TestRunDurationMs float64    `json:"testRunDurationMs"`
+	Commands          []Command  `json:"commands"`
+	Resources         *Resources `json:"resources,omitempty"`
+	Tests             *Tests     `json:"tests,omitempty"`
+}
+
+// Command of the job, annotated with resource usage while it was running
+type Command struct {
+	metrics.Command
+	DurationMs float64 `json:"durationMs"`
+	// TestRun is true for commands which run tests
+	TestRun bool           `json:"testRun"`
+	Usage   *metrics.Usage `json:"usage,omitempty"`
+}
+
+// Resources is resource usage of the job
+type Resources struct {
+	Usage   metrics.Usage `json:"usage"`
+	Peaks   []Peak        `json:"peaks"`
+	Samples []Sample      `json:"samples"`
+}
+
+// Sample is resource usage annotated with the command running at the time
+type Sample struct {
+	metrics.Sample
+	Command string `json:"command,omitempty"`
+	TestRun bool   `json:"testRun,omitempty"`
+}
+
+// Peak is the highest usage of a resource
+type Peak struct {
+	Resource  string    `json:"resource"`
+	Value     float64   `json:"value"`
+	Timestamp time.Time `json:"timestamp"`
+	Command   string    `json:"command,omitempty"`
+}
+
+// Tests sums up test results of the job
+type Tests struct {
+	Summary  parser.Summary `json:"summary"`
+	PassRate float64        `json:"passRate"`
+	Failed   []Test         `json:"failed"`
+	Slowest  []Test         `json:"slowest"`
+}
+
+// Test ...
+type Test struct {
+	Name       string       `json:"name"`
+	Suite      string       `json:"suite"`
+	State      parser.State `json:"state"`
+	DurationMs float64      `json:"durationMs"`
+	Message    string       `json:"message,omitempty"`
+}
+
+// New builds report of a job. Any of commands, samples and result may be empty, its part of the report is left out.
+// Samples without timestamp can't be placed on the timeline and are left out as well.
+func New(commands []metrics.Command, samples []metrics.Sample, result *parser.Result, options Options) Report {
+	r := Report{Commands: []Command{}}
+
+	var testRunDuration time.Duration
+	for _, command := range commands {
+		testRun := isTestRun(command.Directive, options.TestCommands)
+		r.Commands = append(r.Commands, Command{Command: command, DurationMs: runreport.Milliseconds(command.Duration()), TestRun: testRun})
+
+		if testRun {
+			testRunDuration += command.Duration()
+		}
+
+		if r.StartedAt.IsZero() || command.StartedAt.Before(r.StartedAt) {
+			r.StartedAt = command.StartedAt
+		}
+		if command.FinishedAt.After(r.FinishedAt) {
+			r.FinishedAt = command.FinishedAt
+		}
+	}
+	r.DurationMs = runreport.Milliseconds(r.FinishedAt.Sub(r.StartedAt))
+	r.TestRunDurationMs = runreport.Milliseconds(testRunDuration)
+
+	timed := []metrics.Sample{}
+	for _, sample := range samples {
+		if !sample.Timestamp.IsZero() {
+			timed = append(timed, sample)
+		}
+	}
+
+	if len(timed) > 0 {
+		r.Resources = newResources(r.Commands, timed)
+	}
+
+	if result != nil {
+		r.Tests = newTests(result, options)
+	}
+
+	return r
+}
+
+// JSON marshals report for other renderers
+func (r *Report) JSON() ([]byte, error) {
+	return json.MarshalIndent(r, "", "  ")
+}
+
+func isTestRun(directive string, patterns []*regexp.Regexp) bool {
+	for _, pattern := range patterns {
+		if pattern.MatchString(directive) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// newResources annotates samples with running commands and sums up usage of each command
+func newResources(commands []Command, samples []metrics.Sample) *Resources {
+	resources := &Resources{Usage: metrics.Summarize(samples), Peaks: []Peak{}, Samples: []Sample{}}
+
+	byCommand := make([][]metrics.Sample, len(commands))
+	for _, sample := range samples {
+		annotated := Sample{Sample: sample}
+
+		// Commands finishing and starting within the same second overlap, the later one is preferred
+		for i := len(commands) - 1; i >= 0; i-- {
+			if commands[i].Running(sample.Timestamp) {
+				annotated.Command = commands[i].Directive
+				annotated.TestRun = commands[i].TestRun
+				byCommand[i] = append(byCommand[i], sample)
+				break
+			}
+		}
+
+		resources.Samples = append(resources.Samples, annotated)
+	}
+
+	for i := range commands {
+		if len(byCommand[i]) > 0 {
+			usage := metrics.Summarize(byCommand[i])
+			commands[i].Usage = &usage
+		}
+	}
+
+	for _, resource := range []struct {
+		name  string
+		value func(metrics.Sample) float64
+	}{
+		{"cpu", func(s metrics.Sample) float64 { return s.CPU }},
+		{"memory", func(s metrics.Sample) float64 { return s.Memory }},
+	} {
+		peak := resources.Samples[0]
+		for _, sample := range resources.Samples[1:] {
+			if resource.value(sample.Sample) > resource.value(peak.Sample) {
+				peak = sample
+			}
+		}
+
+		resources.Peaks = append(resources.Peaks, Peak{
+			Resource:  resource.name,
+			Value:     resource.value(peak.Sample),
+			Timestamp: peak.Timestamp,
+			Command:   peak.Command,
+		})
+	}
+
+	return resources
+}
+
+func newTests(result *parser.Result, options Options) *Tests {
+	summary := report.Summarize(*result)
+	tests := &Tests{Summary: summary, PassRate: summary.PassRate(), Failed: []Test{}, Slowest: []Test{}}
+
+	for i, entry := range report.FailedTests(result) {
+		if i >= options.MaxFailures {
+			break
+		}
+
+		test := newTest(entry)
+		test.Message = report.Truncate(report.FailureMessage(entry.Test), options.MessageLength)
+		tests.Failed = append(tests.Failed, test)
+	}
+
+	for _, entry := range report.SlowestTests(result, options.Top) {
+		tests.Slowest = append(tests.Slowest, newTest(entry))
+	}
+
+	return tests
+}
+
+func newTest(entry report.TestEntry) Test {
+	name := entry.Test.Name
+	if entry.Test.Classname != "" && !strings.HasPrefix(name, entry.Test.Classname) {
+		name = entry.Test.Classname + " " + name
+	}
+
+	return Test{Name: name, Suite: entry.Suite.Name, State: entry.Test.State, DurationMs: runreport.Milliseconds(entry.Test.Duration)}
+}
@@ -0,0 +1,243 @@
+package jobreport_test
+
+import (
+	"encoding/json"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/semaphoreci/test-results/pkg/jobreport"
+	"github.com/semaphoreci/test-results/pkg/metrics"
+	"github.com/semaphoreci/test-results/pkg/parser"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var start = time.Date(2025, 5, 11, 21, 46, 40, 0, time.UTC)
+
+func at(seconds int) time.Time {
+	return start.Add(time.Duration(seconds) * time.Second)
+}
+
+func newCommands() []metrics.Command {
+	return []metrics.Command{
+		{Directive: "checkout", StartedAt: at(0), FinishedAt: at(5), Finished: true},
+		{Directive: "bundle exec rspec | tee rspec.log", StartedAt: at(5), FinishedAt: at(15), ExitCode: 1, Finished: true},
+		{Directive: "test-results publish junit.xml", StartedAt: at(15), FinishedAt: at(16), Finished: false},
+	}
+}
+
+func newSamples() []metrics.Sample {
+	samples := []metrics.Sample{}
+	for i, cpu := range []float64{5, 10, 80, 95, 60, 4} {
+		samples = append(samples, metrics.Sample{Timestamp: at(i * 4), CPU: cpu, Memory: float64(10 + i), SystemDisk: 20, DockerDisk: 30})
+	}
+
+	return samples
+}
+
+func newResult() *parser.Result {
+	suite := parser.NewSuite()
+	suite.Name = "spec/models"
+
+	for _, tc := range []struct {
+		name     string
+		state    parser.State
+		duration time.Duration
+		failure  *parser.Failure
+	}{
+		{"saves user", parser.StatePassed, 2 * time.Second, nil},
+		{"validates | email", parser.StateFailed, time.Second, &parser.Failure{Message: "expected\nvalid"}},
+		{"skips", parser.StateSkipped, 0, nil},
+	} {
+		test := parser.NewTest()
+		test.Name = tc.name
+		test.State = tc.state
+		test.Duration = tc.duration
+		test.Failure = tc.failure
+		suite.AppendTest(test)
+	}
+
+	testResults := parser.NewTestResults()
+	testResults.Suites = append(testResults.Suites, suite)
+	testResults.Aggregate()
+
+	result := parser.NewResult()
+	result.TestResults = append(result.TestResults, testResults)
+
+	return &result
+}
+
+func Test_New(t *testing.T) {
+	r := jobreport.New(newCommands(), newSamples(), newResult(), jobreport.DefaultOptions())
+
+	assert.Equal(t, start, r.StartedAt)
+	assert.Equal(t, 16000.0, r.DurationMs)
+	assert.Equal(t, 10000.0, r.TestRunDurationMs)
+
+	t.Run("marks commands running tests", func(t *testing.T) {
+		testRuns := []bool{}
+		for _, command := range r.Commands {
+			testRuns = append(testRuns, command.TestRun)
+		}
+		assert.Equal(t, []bool{false, true, false}, testRuns)
+	})
+
+	t.Run("annotates samples with running command", func(t *testing.T) {
+		require.Len(t, r.Resources.Samples, 6)
+
+		commands := []string{}
+		for _, sample := range r.Resources.Samples {
+			commands = append(commands, sample.Command)
+		}
+		assert.Equal(t, []string{
+			"checkout",
+			"checkout",
+			"bundle exec rspec | tee rspec.log",
+			"bundle exec rspec | tee rspec.log",
+			"test-results publish junit.xml",
+			"test-results publish junit.xml",
+		}, commands, "samples after the last event belong to the running command")
+		assert.True(t, r.Resources.Samples[3].TestRun)
+	})
+
+	t.Run("sums up usage of each command", func(t *testing.T) {
+		usage := r.Commands[1].Usage
+		require.NotNil(t, usage)
+		assert.Equal(t, 2, usage.Samples)
+		assert.Equal(t, 87.5, usage.CPU.Avg)
+		assert.Equal(t, 95.0, usage.CPU.Max)
+	})
+
+	t.Run("finds peaks", func(t *testing.T) {
+		assert.Equal(t, []jobreport.Peak{
+			{Resource: "cpu", Value: 95, Timestamp: at(12), Command: "bundle exec rspec | tee rspec.log"},
+			{Resource: "memory", Value: 15, Timestamp: at(20), Command: "test-results publish junit.xml"},
+		}, r.Resources.Peaks)
+	})
+
+	t.Run("sums up tests", func(t *testing.T) {
+		assert.Equal(t, 3, r.Tests.Summary.Total)
+		assert.Equal(t, 50.0, r.Tests.PassRate)
+		require.Len(t, r.Tests.Failed, 1)
+		assert.Equal(t, "expected\nvalid", r.Tests.Failed[0].Message)
+		assert.Equal(t, "saves user", r.Tests.Slowest[0].Name)
+	})
+
+	t.Run("marshals to JSON", func(t *testing.T) {
+		data, err := r.JSON()
+		require.NoError(t, err)
+
+		model := map[string]interface{}{}
+		require.NoError(t, json.Unmarshal(data, &model))
+		command := model["commands"].([]interface{})[1].(map[string]interface{})
+		assert.Equal(t, "bundle exec rspec | tee rspec.log", command["directive"])
+		assert.Equal(t, true, command["testRun"])
+		assert.Equal(t, 10000.0, command["durationMs"])
+		assert.Equal(t, 2000.0, model["tests"].(map[string]interface{})["slowest"].([]interface{})[0].(map[string]interface{})["durationMs"])
+	})
+}
+
+func Test_New_TimeZones(t *testing.T) {
+	input := `Mon 12 May 2025 12:46:41 AM EEST |  cpu:10%,  mem:  10%,  system_disk: 20%,  docker_disk: 30%,  shared_memory: 0 M
+Mon 12 May 2025 12:46:46 AM EEST |  cpu:90%,  mem:  10%,  system_disk: 20%,  docker_disk: 30%,  shared_memory: 0 M
+Sun 11 May 2025 11:46:47 PM CEST |  cpu:50%,  mem:  10%,  system_disk: 20%,  docker_disk: 30%,  shared_memory: 0 M
+`
+	samples, err := metrics.ParseSamples(strings.NewReader(input), time.FixedZone("EEST", 3*60*60))
+	require.NoError(t, err)
+
+	r := jobreport.New(newCommands(), samples, nil, jobreport.DefaultOptions())
+
+	require.Len(t, r.Resources.Samples, 2, "samples in unknown zones are left out")
+	assert.Equal(t, "checkout", r.Resources.Samples[0].Command, "21:46:41 UTC is during checkout")
+	assert.Equal(t, "bundle exec rspec | tee rspec.log", r.Resources.Samples[1].Command)
+	assert.Equal(t, 2, r.Resources.Usage.Samples)
+}
+
+func Test_New_MissingInputs(t *testing.T) {
+	r := jobreport.New(nil, nil, newResult(), jobreport.DefaultOptions())
+
+	assert.Empty(t, r.Commands)
+	assert.Nil(t, r.Resources)
+	assert.NotNil(t, r.Tests)
+
+	out := jobreport.Markdown(r)
+	assert.NotContains(t, out, "### 🧭 Timeline")
+	assert.NotContains(t, out, "### 🎯 Resource usage")
+	assert.Contains(t, out, "### 🧪 Tests")
+
+	r = jobreport.New(newCommands(), newSamples(), nil, jobreport.DefaultOptions())
+	assert.Nil(t, r.Tests)
+	assert.NotContains(t, jobreport.Markdown(r), "### 🧪 Tests")
+}
+
+func Test_DefaultTestCommands(t *testing.T) {
+	options := jobreport.DefaultOptions()
+
+	testCases := map[string]bool{
+		"go test ./...":                      true,
+		"gotestsum --junitfile junit.xml":    true,
+		"bundle exec rspec":                  true,
+		"python -m pytest tests/":            true,
+		"npx jest --ci":                      true,
+		"npm test":                           true,
+		"yarn run test":                      true,
+		"vendor/bin/phpunit":                 true,
+		"mix test":                           true,
+		"./gradlew clean test":               true,
+		"make test":                          true,
+		"test-results publish junit.xml":     false,
+		"make test-results":                  false,
+		"go build ./...":                     false,
+		"cache restore":                      false,
+		"echo testing":                       false,
+		"checkout && cd app && go vet ./...": false,
+	}
+
+	for directive, expected := range testCases {
+		matched := false
+		for _, pattern := range options.TestCommands {
+			matched = matched || pattern.MatchString(directive)
+		}
+		assert.Equal(t, expected, matched, directive)
+	}
+
+	_, err := jobreport.CompileTestCommands([]string{"("})
+	assert.Error(t, err)
+}
+
+func Test_Markdown(t *testing.T) {
+	options := jobreport.DefaultOptions()
+	options.TestCommands = []*regexp.Regexp{regexp.MustCompile(`rspec`)}
+
+	out := jobreport.Markdown(jobreport.New(newCommands(), newSamples(), newResult(), options))
+
+	assert.True(t, strings.HasPrefix(out, "## 📋 Job Report\n\n"))
+	assert.Contains(t, out, "**🕒 Duration:** `16s` | **Commands:** `3` | **🧪 Test runs:** `1` taking `10s` (`62.5%` of the job)")
+	assert.Contains(t, out, "**Peak usage:** 🔥 CPU `95.0%` during `bundle exec rspec | tee rspec.log` | 🧠 Memory `15.0%` during `test-results publish junit.xml`")
+
+	t.Run("highlights test runs in timeline", func(t *testing.T) {
+		assert.Contains(t, out, "    checkout[5s] :step0, 1747000000, 5s\n")
+		assert.Contains(t, out, "    bundle exec rspec | tee rspec.log[10s] :crit, step1, 1747000005, 10s\n")
+	})
+
+	t.Run("lists commands with their usage", func(t *testing.T) {
+		assert.Contains(t, out, "| ✅ | checkout | `5s` | 0 | `7.5%` | `10.0%` | `11.0%` |")
+		assert.Contains(t, out, "| ❌ 🧪 | bundle exec rspec \\| tee rspec.log | `10s` | 1 | `87.5%` | `95.0%` | `13.0%` |")
+		assert.Contains(t, out, "| ⏳ | test-results publish junit.xml | `1s` | running |")
+	})
+
+	t.Run("annotates resource usage with commands", func(t *testing.T) {
+		assert.Contains(t, out, "| 🔥 CPU | `4.00%` | `42.33%` | `95.00%` | at `21:46:52` during `bundle exec rspec \\| tee rspec.log` |")
+		assert.Contains(t, out, "| 💽 System Disk | `20.00%` | `20.00%` | `20.00%` | - |")
+		assert.Contains(t, out, "title \"CPU Usage\"")
+	})
+
+	t.Run("sums up tests", func(t *testing.T) {
+		assert.Contains(t, out, "**Total:** `3` | **✅ Passed:** `1` | **❌ Failed:** `1`")
+		assert.Contains(t, out, "Run by: `bundle exec rspec | tee rspec.log`")
+		assert.Contains(t, out, "| validates \\| email | spec/models | expected valid |")
+		assert.Contains(t, out, "| saves user | spec/models | `2.00s` |")
+	})
+}
@@ -0,0 +1,235 @@
+package jobreport
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/semaphoreci/test-results/pkg/metrics"
+	"github.com/semaphoreci/test-results/pkg/report"
+)
+
+var resourceLabels = map[string]string{"cpu": "🔥 CPU", "memory": "🧠 Memory"}
+
+// Markdown renders report of a job as markdown
+func Markdown(r Report) string {
+	out := "## 📋 Job Report\n\n"
+	out += headerMarkdown(r)
+
+	if len(r.Commands) > 0 {
+		out += timelineMarkdown(r)
+		out += commandsMarkdown(r)
+	}
+
+	if r.Resources != nil {
+		out += resourcesMarkdown(r.Resources)
+	}
+
+	if r.Tests != nil {
+		out += testsMarkdown(r.Tests, r.Commands)
+	}
+
+	out += "---\n\n"
+
+	return out
+}
+
+func headerMarkdown(r Report) string {
+	out := ""
+	if len(r.Commands) > 0 {
+		testRuns := 0
+		for _, command := range r.Commands {
+			if command.TestRun {
+				testRuns++
+			}
+		}
+
+		out += fmt.Sprintf("**🕒 Duration:** `%s` | **Commands:** `%d` | **🧪 Test runs:** `%d`", duration(r.DurationMs), len(r.Commands), testRuns)
+		if testRuns > 0 {
+			out += fmt.Sprintf(" taking `%s`", duration(r.TestRunDurationMs))
+			if r.DurationMs > 0 {
+				out += fmt.Sprintf(" (`%.1f%%` of the job)", r.TestRunDurationMs/r.DurationMs*100)
+			}
+		}
+		out += "  \n"
+	}
+
+	if r.Tests != nil {
+		summary := r.Tests.Summary
+		out += fmt.Sprintf("**Tests:** `%d` | **✅ Passed:** `%d` | **❌ Failed:** `%d` | **💥 Errors:** `%d` | **Pass rate:** `%.1f%%`  \n",
+			summary.Total, summary.Passed, summary.Failed, summary.Error, r.Tests.PassRate)
+	}
+
+	if r.Resources != nil {
+		peaks := []string{}
+		for _, peak := range r.Resources.Peaks {
+			peaks = append(peaks, fmt.Sprintf("%s `%.1f%%`%s", resourceLabels[peak.Resource], peak.Value, during(peak.Command)))
+		}
+		out += fmt.Sprintf("**Peak usage:** %s  \n", strings.Join(peaks, " | "))
+	}
+
+	return out + "\n"
+}
+
+func timelineMarkdown(r Report) string {
+	out := "### 🧭 Timeline\n\n"
+	out += "```mermaid\ngantt\n    title Job Command Timeline\n    dateFormat X\n    axisFormat %X\n"
+	for i, command := range r.Commands {
+		tag := ""
+		if command.TestRun {
+			tag = "crit"
+		}
+		out += metrics.GanttTask(command.Command, fmt.Sprintf("step%d", i), tag)
+	}
+	out += "```\n\n"
+
+	if r.TestRunDurationMs > 0 {
+		out += "_Commands running tests are highlighted._\n\n"
+	}
+
+	return out
+}
+
+func commandsMarkdown(r Report) string {
+	out := "### ⚙️ Commands\n\n"
+	out += "| | Command | Duration | Exit code | CPU avg | CPU max | Memory max |\n"
+	out += "| --- | --- | --- | --- | --- | --- | --- |\n"
+	for _, command := range r.Commands {
+		status := "✅"
+		exitCode := fmt.Sprint(command.ExitCode)
+		switch {
+		case !command.Finished:
+			status, exitCode = "⏳", "running"
+		case command.ExitCode != 0:
+			status = "❌"
+		}
+		if command.TestRun {
+			status += " 🧪"
+		}
+
+		cpuAvg, cpuMax, memoryMax := "-", "-", "-"
+		if command.Usage != nil {
+			cpuAvg = fmt.Sprintf("`%.1f%%`", command.Usage.CPU.Avg)
+			cpuMax = fmt.Sprintf("`%.1f%%`", command.Usage.CPU.Max)
+			memoryMax = fmt.Sprintf("`%.1f%%`", command.Usage.Memory.Max)
+		}
+
+		out += fmt.Sprintf("| %s | %s | `%s` | %s | %s | %s | %s |\n",
+			status, report.EscapeCell(command.Directive), duration(command.DurationMs), exitCode, cpuAvg, cpuMax, memoryMax)
+	}
+
+	return out + "\n"
+}
+
+func resourcesMarkdown(resources *Resources) string {
+	samples := make([]metrics.Sample, 0, len(resources.Samples))
+	for _, sample := range resources.Samples {
+		samples = append(samples, sample.Sample)
+	}
+
+	first, last := samples[0], samples[len(samples)-1]
+	peaks := map[string]Peak{}
+	for _, peak := range resources.Peaks {
+		peaks[peak.Resource] = peak
+	}
+
+	out := "### 🎯 Resource usage\n\n"
+	out += fmt.Sprintf("**Datapoints:** `%d` | **🕒 Time Range:** `%s` → `%s`\n\n",
+		resources.Usage.Samples, first.Timestamp.Format(metrics.TimestampLayout), last.Timestamp.Format(metrics.TimestampLayout))
+
+	out += "| Resource | Min | Avg | Max | Peak |\n"
+	out += "| --- | --- | --- | --- | --- |\n"
+	for _, row := range []struct {
+		name  string
+		label string
+		usage metrics.Range
+	}{
+		{"cpu", resourceLabels["cpu"], resources.Usage.CPU},
+		{"memory", resourceLabels["memory"], resources.Usage.Memory},
+		{"system-disk", "💽 System Disk", resources.Usage.SystemDisk},
+		{"docker-disk", "🐳 Docker Disk", resources.Usage.DockerDisk},
+	} {
+		peak := "-"
+		if p, ok := peaks[row.name]; ok {
+			peak = fmt.Sprintf("at `%s`%s", p.Timestamp.Format("15:04:05"), strings.ReplaceAll(during(p.Command), "|", "\\|"))
+		}
+
+		out += fmt.Sprintf("| %s | `%.2f%%` | `%.2f%%` | `%.2f%%` | %s |\n", row.label, row.usage.Min, row.usage.Avg, row.usage.Max, peak)
+	}
+	out += "\n"
+
+	out += metrics.Chart("CPU Usage", "Usage (%)", samples, func(s metrics.Sample) float64 { return s.CPU }) + "\n"
+	out += metrics.Chart("Memory Usage", "Usage (%)", samples, func(s metrics.Sample) float64 { return s.Memory }) + "\n"
+
+	return out
+}
+
+func testsMarkdown(tests *Tests, commands []Command) string {
+	summary := tests.Summary
+
+	out := "### 🧪 Tests\n\n"
+	out += fmt.Sprintf("**Total:** `%d` | **✅ Passed:** `%d` | **❌ Failed:** `%d` | **💥 Errors:** `%d` | **⏭️ Skipped:** `%d` | **🚫 Disabled:** `%d`  \n",
+		summary.Total, summary.Passed, summary.Failed, summary.Error, summary.Skipped, summary.Disabled)
+	if summary.Quarantined > 0 {
+		out += fmt.Sprintf("**🔒 Quarantined:** `%d`  \n", summary.Quarantined)
+	}
+	out += fmt.Sprintf("**🕒 Duration:** `%s` | **Pass rate:** `%.1f%%`\n\n", report.FormatDuration(summary.Duration), tests.PassRate)
+
+	testRuns := []string{}
+	for _, command := range commands {
+		if command.TestRun {
+			testRuns = append(testRuns, code(command.Directive))
+		}
+	}
+	if len(testRuns) > 0 {
+		out += fmt.Sprintf("Run by: %s\n\n", strings.Join(testRuns, ", "))
+	}
+
+	if len(tests.Failed) > 0 {
+		failed := summary.Failed + summary.Error
+		out += fmt.Sprintf("#### ❌ Failed tests (%d)\n\n", failed)
+		out += "| Test | Suite | Message |\n"
+		out += "| --- | --- | --- |\n"
+		for _, test := range tests.Failed {
+			out += fmt.Sprintf("| %s | %s | %s |\n", report.EscapeCell(test.Name), report.EscapeCell(test.Suite), report.EscapeCell(test.Message))
+		}
+		if failed > len(tests.Failed) {
+			out += fmt.Sprintf("\n_...and %d more_\n", failed-len(tests.Failed))
+		}
+		out += "\n"
+	}
+
+	if len(tests.Slowest) > 0 {
+		out += "#### 🐢 Slowest tests\n\n"
+		out += "| Test | Suite | Duration |\n"
+		out += "| --- | --- | --- |\n"
+		for _, test := range tests.Slowest {
+			out += fmt.Sprintf("| %s | %s | `%s` |\n", report.EscapeCell(test.Name), report.EscapeCell(test.Suite), report.FormatDuration(duration(test.DurationMs)))
+		}
+		out += "\n"
+	}
+
+	return out
+}
+
+func during(command string) string {
+	if command == "" {
+		return ""
+	}
+
+	return " during " + code(command)
+}
+
+// code formats s as inline code, on a single line
+func code(s string) string {
+	s = strings.ReplaceAll(s, "\r\n", " ")
+	s = strings.ReplaceAll(s, "\n", " ")
+	s = strings.ReplaceAll(s, "`", "'")
+
+	return "`" + strings.TrimSpace(s) + "`"
+}
+
+// duration converts milliseconds of the report model back into duration
+func duration(ms float64) time.Duration {
+	return time.Duration(ms * float64(time.Millisecond))
+}
@@ -0,0 +1,216 @@
+package junit
+
+import (
+	"encoding/xml"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/semaphoreci/test-results/pkg/logger"
+	"github.com/semaphoreci/test-results/pkg/parser"
+)
+
+// TestSuites maps root <testsuites> element
+type TestSuites struct {
+	XMLName  xml.Name    `xml:"testsuites"`
+	Name     string      `xml:"name,attr,omitempty"`
+	Tests    int         `xml:"tests,attr"`
+	Failures int         `xml:"failures,attr"`
+	Errors   int         `xml:"errors,attr"`
+	Skipped  int         `xml:"skipped,attr"`
+	Disabled int         `xml:"disabled,attr"`
+	Time     string      `xml:"time,attr"`
+	Suites   []TestSuite `xml:"testsuite"`
+}
+
+// TestSuite maps <testsuite> element
+type TestSuite struct {
+	ID         string      `xml:"id,attr,omitempty"`
+	Name       string      `xml:"name,attr"`
+	Package    string      `xml:"package,attr,omitempty"`
+	Tests      int         `xml:"tests,attr"`
+	Failures   int         `xml:"failures,attr"`
+	Errors     int         `xml:"errors,attr"`
+	Skipped    int         `xml:"skipped,attr"`
+	Disabled   int         `xml:"disabled,attr"`
+	Time       string      `xml:"time,attr"`
+	Timestamp  string      `xml:"timestamp,attr,omitempty"`
+	Hostname   string      `xml:"hostname,attr,omitempty"`
+	Properties *Properties `xml:"properties,omitempty"`
+	TestCases  []TestCase  `xml:"testcase"`
+	SystemOut  string      `xml:"system-out,omitempty"`
+	SystemErr  string      `xml:"system-err,omitempty"`
+}
+
+// Properties maps <properties> element
+type Properties struct {
+	Properties []Property `xml:"property"`
+}
+
+// Property maps <property> element
+type Property struct {
+	Name  string `xml:"name,attr"`
+	Value string `xml:"value,attr"`
+}
+
+// TestCase maps <testcase> element
+type TestCase struct {
+	Name       string      `xml:"name,attr"`
+	Classname  string      `xml:"classname,attr,omitempty"`
+	File       string      `xml:"file,attr,omitempty"`
+	Time       string      `xml:"time,attr"`
+	Properties *Properties `xml:"properties,omitempty"`
+	Failure    *Result     `xml:"failure,omitempty"`
+	Error      *Result     `xml:"error,omitempty"`
+	Skipped    *Skipped    `xml:"skipped,omitempty"`
+	SystemOut  string      `xml:"system-out,omitempty"`
+	SystemErr  string      `xml:"system-err,omitempty"`
+}
+
+// Result maps <failure> and <error> elements
+type Result struct {
+	Message string `xml:"message,attr,omitempty"`
+	Type    string `xml:"type,attr,omitempty"`
+	Body    string `xml:",chardata"`
+}
+
+// Skipped maps <skipped> element
+type Skipped struct {
+	Message string `xml:"message,attr,omitempty"`
+}
+
+// FromResult builds <testsuites> document out of test results
+func FromResult(result parser.Result) TestSuites {
+	doc := TestSuites{Suites: []TestSuite{}}
+	summary := parser.Summary{}
+
+	if len(result.TestResults) == 1 {
+		doc.Name = result.TestResults[0].Name
+	}
+
+	for _, testResults := range result.TestResults {
+		summary.Merge(&testResults.Summary)
+
+		for _, suite := range testResults.Suites {
+			doc.Suites = append(doc.Suites, newTestSuite(suite))
+		}
+	}
+
+	doc.Tests = summary.Total
+	doc.Failures = summary.Failed
+	doc.Errors = summary.Error
+	doc.Skipped = summary.Skipped + summary.Quarantined
+	doc.Disabled = summary.Disabled
+	doc.Time = formatTime(summary.Duration)
+
+	return doc
+}
+
+// Marshal provides JUnit XML output for given test results
+func Marshal(result parser.Result) ([]byte, error) {
+	data, err := xml.MarshalIndent(FromResult(result), "", "  ")
+	if err != nil {
+		logger.Error("Marshaling JUnit XML failed with: %v", err)
+		return nil, err
+	}
+
+	return append([]byte(xml.Header), append(data, '\n')...), nil
+}
+
+func newTestSuite(suite parser.Suite) TestSuite {
+	testSuite := TestSuite{
+		ID:        suite.ID,
+		Name:      suite.Name,
+		Package:   suite.Package,
+		Tests:     suite.Summary.Total,
+		Failures:  suite.Summary.Failed,
+		Errors:    suite.Summary.Error,
+		Skipped:   suite.Summary.Skipped + suite.Summary.Quarantined,
+		Disabled:  suite.Summary.Disabled,
+		Time:      formatTime(suite.Summary.Duration),
+		Timestamp: suite.Timestamp,
+		Hostname:  suite.Hostname,
+		SystemOut: suite.SystemOut,
+		SystemErr: suite.SystemErr,
+		TestCases: []TestCase{},
+	}
+
+	testSuite.Properties = newProperties(suite.Properties)
+
+	for _, test := range suite.Tests {
+		testSuite.TestCases = append(testSuite.TestCases, newTestCase(test))
+	}
+
+	return testSuite
+}
+
+func newProperties(properties parser.Properties) *Properties {
+	if len(properties) == 0 {
+		return nil
+	}
+
+	names := make([]string, 0, len(properties))
+	for name := range properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	result := &Properties{}
+	for _, name := range names {
+		result.Properties = append(result.Properties, Property{Name: name, Value: properties[name]})
+	}
+
+	return result
+}
+
+func newTestCase(test parser.Test) TestCase {
+	testCase := TestCase{
+		Name:       test.Name,
+		Classname:  test.Classname,
+		File:       test.File,
+		Time:       formatTime(test.Duration),
+		SystemOut:  test.SystemOut,
+		SystemErr:  test.SystemErr,
+		Properties: newProperties(test.Properties),
+	}
+
+	if test.Failure != nil {
+		testCase.Failure = &Result{Message: test.Failure.Message, Type: test.Failure.Type, Body: test.Failure.Body}
+	}
+
+	if test.Error != nil {
+		testCase.Error = &Result{Message: test.Error.Message, Type: test.Error.Type, Body: test.Error.Body}
+	}
+
+	switch test.State {
+	case parser.StateFailed:
+		if testCase.Failure == nil {
+			testCase.Failure = &Result{}
+		}
+	case parser.StateError:
+		if testCase.Error == nil {
+			testCase.Error = &Result{}
+		}
+	case parser.StateSkipped:
+		testCase.Skipped = &Skipped{}
+	case parser.StateDisabled:
+		testCase.Skipped = &Skipped{Message: "disabled"}
+	case parser.StateQuarantined:
+		// Quarantined failures should not fail consumers of the report, the original message is kept.
+		// They are counted as skipped, so counts of the suite add up to its tests.
+		testCase.Skipped = &Skipped{Message: "quarantined"}
+		for _, result := range []*Result{testCase.Failure, testCase.Error} {
+			if result != nil && result.Message != "" {
+				testCase.Skipped.Message += ": " + result.Message
+			}
+		}
+		testCase.Failure = nil
+		testCase.Error = nil
+	}
+
+	return testCase
+}
+
+func formatTime(d time.Duration) string {
+	return strconv.FormatFloat(d.Seconds(), 'f', -1, 64)
+}
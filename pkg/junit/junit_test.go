@@ -0,0 +1,177 @@
+package junit_test
+
+import (
+	"bytes"
+	"encoding/xml"
+	"testing"
+	"time"
+
+	"github.com/semaphoreci/test-results/pkg/fileloader"
+	"github.com/semaphoreci/test-results/pkg/junit"
+	"github.com/semaphoreci/test-results/pkg/parser"
+	"github.com/semaphoreci/test-results/pkg/parsers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newResult() parser.Result {
+	testResults := parser.NewTestResults()
+	testResults.Name = "Unit Tests"
+	testResults.EnsureID()
+
+	suite := parser.NewSuite()
+	suite.Name = "foo"
+	suite.Properties = parser.Properties{"go.version": "1.20", "arch": "amd64"}
+	suite.SystemOut = "suite <out>"
+	suite.EnsureID(testResults)
+
+	passed := parser.NewTest()
+	passed.Name = "passes"
+	passed.Classname = "Foo"
+	passed.File = "foo_test.go"
+	passed.Duration = 1500 * time.Millisecond
+	passed.SystemOut = "some output"
+	passed.EnsureID(suite)
+	suite.AppendTest(passed)
+
+	failed := parser.NewTest()
+	failed.Name = "fails"
+	failed.Classname = "Foo"
+	failed.State = parser.StateFailed
+	failed.Failure = &parser.Failure{Message: "expected 1 got 2", Type: "assert", Body: "foo_test.go:12\n\tx < y && y > z"}
+	failed.EnsureID(suite)
+	suite.AppendTest(failed)
+
+	errored := parser.NewTest()
+	errored.Name = "errors"
+	errored.State = parser.StateError
+	errored.Error = &parser.Error{Message: "boom"}
+	errored.SystemErr = "panic"
+	errored.EnsureID(suite)
+	suite.AppendTest(errored)
+
+	skipped := parser.NewTest()
+	skipped.Name = "skips"
+	skipped.State = parser.StateSkipped
+	skipped.EnsureID(suite)
+	suite.AppendTest(skipped)
+
+	testResults.Suites = append(testResults.Suites, suite)
+	testResults.Aggregate()
+
+	result := parser.NewResult()
+	result.TestResults = append(result.TestResults, testResults)
+
+	return result
+}
+
+func Test_Marshal(t *testing.T) {
+	data, err := junit.Marshal(newResult())
+	require.NoError(t, err)
+
+	assert.True(t, bytes.HasPrefix(data, []byte(xml.Header)), "should start with XML header")
+
+	doc := junit.TestSuites{}
+	require.NoError(t, xml.Unmarshal(data, &doc), "should produce well-formed XML")
+
+	assert.Equal(t, "Unit Tests", doc.Name)
+	assert.Equal(t, 4, doc.Tests)
+	assert.Equal(t, 1, doc.Failures)
+	assert.Equal(t, 1, doc.Errors)
+	assert.Equal(t, 1, doc.Skipped)
+	assert.Equal(t, "1.5", doc.Time)
+
+	require.Len(t, doc.Suites, 1)
+	suite := doc.Suites[0]
+	assert.Equal(t, "foo", suite.Name)
+	assert.Equal(t, "suite <out>", suite.SystemOut)
+	require.NotNil(t, suite.Properties)
+	assert.Equal(t, []junit.Property{{Name: "arch", Value: "amd64"}, {Name: "go.version", Value: "1.20"}}, suite.Properties.Properties, "properties should be sorted by name")
+
+	require.Len(t, suite.TestCases, 4)
+	assert.Equal(t, "some output", suite.TestCases[0].SystemOut)
+	assert.Equal(t, "1.5", suite.TestCases[0].Time)
+	assert.Equal(t, &junit.Result{Message: "expected 1 got 2", Type: "assert", Body: "foo_test.go:12\n\tx < y && y > z"}, suite.TestCases[1].Failure)
+	assert.Equal(t, &junit.Result{Message: "boom"}, suite.TestCases[2].Error)
+	assert.Equal(t, "panic", suite.TestCases[2].SystemErr)
+	assert.NotNil(t, suite.TestCases[3].Skipped)
+}
+
+func Test_Marshal_MultipleTestResults(t *testing.T) {
+	result := newResult()
+	other := newResult()
+	other.TestResults[0].Name = "Integration Tests"
+	other.TestResults[0].RegenerateID()
+	result.Combine(other)
+
+	data, err := junit.Marshal(result)
+	require.NoError(t, err)
+
+	doc := junit.TestSuites{}
+	require.NoError(t, xml.Unmarshal(data, &doc))
+
+	assert.Equal(t, "", doc.Name, "should not pick a name when results are merged")
+	assert.Equal(t, 8, doc.Tests)
+	assert.Len(t, doc.Suites, 2)
+}
+
+func Test_FromResult_Quarantined(t *testing.T) {
+	result := newResult()
+	result.TestResults[0].Suites[0].Tests[1].State = parser.StateQuarantined
+	result.TestResults[0].Suites[0].Aggregate()
+	result.TestResults[0].Aggregate()
+
+	doc := junit.FromResult(result)
+	testCase := doc.Suites[0].TestCases[1]
+
+	assert.Nil(t, testCase.Failure)
+	require.NotNil(t, testCase.Skipped)
+	assert.Equal(t, "quarantined: "+result.TestResults[0].Suites[0].Tests[1].Failure.Message, testCase.Skipped.Message)
+
+	suite := doc.Suites[0]
+	assert.Equal(t, 0, suite.Failures)
+	assert.Equal(t, 2, suite.Skipped)
+	assert.Equal(t, suite.Tests, suite.Failures+suite.Errors+suite.Skipped+suite.Disabled+1, "counts should add up to tests, with one passed test")
+	assert.Equal(t, doc.Tests, doc.Failures+doc.Errors+doc.Skipped+doc.Disabled+1)
+}
+
+func Test_Marshal_RoundTrip(t *testing.T) {
+	result := newResult()
+
+	data, err := junit.Marshal(result)
+	require.NoError(t, err)
+
+	path := fileloader.Ensure(bytes.NewReader(data))
+	parsed, err := parsers.NewGeneric().Parse(path)
+	require.NoError(t, err)
+
+	assert.Equal(t, parser.StatusSuccess, parsed.Status)
+	assert.Equal(t, result.TestResults[0].Name, parsed.Name)
+	assert.Equal(t, result.TestResults[0].Summary, parsed.Summary)
+	require.Len(t, parsed.Suites, 1)
+
+	for i, test := range parsed.Suites[0].Tests {
+		want := result.TestResults[0].Suites[0].Tests[i]
+		assert.Equal(t, want.Name, test.Name)
+		assert.Equal(t, want.State, test.State)
+		assert.Equal(t, want.Failure, test.Failure)
+		assert.Equal(t, want.Error, test.Error)
+		assert.Equal(t, want.Duration, test.Duration)
+	}
+}
+
+func Test_FromResult_TestProperties(t *testing.T) {
+	result := newResult()
+	result.TestResults[0].Suites[0].Tests[0].Properties = parser.Properties{"tags": "smoke", "browser": "firefox"}
+
+	data, err := junit.Marshal(result)
+	require.NoError(t, err)
+
+	parsed, err := parsers.NewGeneric().Parse(fileloader.Ensure(bytes.NewReader(data)))
+	require.NoError(t, err)
+	require.Len(t, parsed.Suites, 1)
+
+	test := parsed.Suites[0].Tests[0]
+	assert.Equal(t, parser.Properties{"tags": "smoke", "browser": "firefox"}, test.Properties)
+	assert.Equal(t, []string{"smoke"}, test.Tags)
+}
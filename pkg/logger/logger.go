@@ -1,6 +1,13 @@
 package logger
 
 import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
 	log "github.com/sirupsen/logrus"
 )
 
@@ -11,6 +18,43 @@ func (f *logFormatter) Format(entry *log.Entry) ([]byte, error) {
 	return append([]byte("* "), entry.Message...), nil
 }
 
+// jsonFormatter prints one JSON object per line with level, timestamp, message and fields of the entry
+type jsonFormatter struct {
+}
+
+func (f *jsonFormatter) Format(entry *log.Entry) ([]byte, error) {
+	data := make(map[string]interface{}, len(entry.Data)+3)
+	for name, value := range entry.Data {
+		if err, ok := value.(error); ok {
+			value = err.Error()
+		}
+		data[name] = value
+	}
+
+	data["level"] = entry.Level.String()
+	data["timestamp"] = entry.Time.UTC().Format(time.RFC3339Nano)
+	data["message"] = strings.TrimSpace(entry.Message)
+
+	line := &bytes.Buffer{}
+	encoder := json.NewEncoder(line)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(data); err != nil {
+		return nil, err
+	}
+
+	return line.Bytes(), nil
+}
+
+// Format of log lines
+type Format string
+
+const (
+	// FormatText prints messages prefixed with `* `, fields are not printed
+	FormatText Format = "text"
+	// FormatJSON prints one JSON object per line, with fields
+	FormatJSON Format = "json"
+)
+
 // LogEntry ...
 var LogEntry *logEntry = new()
 
@@ -39,14 +83,18 @@ const (
 
 // LogEntry  ...
 type logEntry struct {
-	logger *log.Logger
+	logger    *log.Logger
+	formatter log.Formatter
+
+	mu     sync.RWMutex
+	fields Fields
 }
 
 // new ...
 func new() *logEntry {
 	logger := log.New()
-	logEntry := &logEntry{logger: logger}
-	logEntry.logger.SetFormatter(&logFormatter{})
+	logEntry := &logEntry{logger: logger, formatter: &logFormatter{}, fields: Fields{}}
+	logEntry.logger.SetFormatter(logEntry.formatter)
 	return logEntry
 }
 
@@ -58,7 +106,95 @@ func GetLogger() *log.Logger {
 // SetLogger ...
 func SetLogger(logger *log.Logger) {
 	LogEntry.logger = logger
-	LogEntry.logger.SetFormatter(&logFormatter{})
+	LogEntry.logger.SetFormatter(LogEntry.formatter)
+}
+
+// SetFormat switches format of log lines
+func SetFormat(format Format) error {
+	switch format {
+	case FormatText, "":
+		LogEntry.formatter = &logFormatter{}
+	case FormatJSON:
+		LogEntry.formatter = &jsonFormatter{}
+	default:
+		return fmt.Errorf("unknown log format %q, expected one of: text, json", format)
+	}
+
+	LogEntry.logger.SetFormatter(LogEntry.formatter)
+	return nil
+}
+
+// SetFields adds fields to every following log line, e.g. name of the running command
+func SetFields(fields Fields) {
+	LogEntry.mu.Lock()
+	defer LogEntry.mu.Unlock()
+
+	merged := Fields{}
+	for name, value := range LogEntry.fields {
+		merged[name] = value
+	}
+	for name, value := range fields {
+		merged[name] = value
+	}
+	LogEntry.fields = merged
+}
+
+// Entry logs messages with fields, e.g. file and parser a message is about
+type Entry struct {
+	fields Fields
+}
+
+// With returns entry logging messages with given fields
+func With(fields Fields) Entry {
+	return Entry{fields: fields}
+}
+
+// Error ...
+func (e Entry) Error(s string, args ...interface{}) {
+	e.Log(ErrorLevel, s, args...)
+}
+
+// Warn ...
+func (e Entry) Warn(s string, args ...interface{}) {
+	e.Log(WarnLevel, s, args...)
+}
+
+// Info ...
+func (e Entry) Info(s string, args ...interface{}) {
+	e.Log(InfoLevel, s, args...)
+}
+
+// Debug ...
+func (e Entry) Debug(s string, args ...interface{}) {
+	e.Log(DebugLevel, s, args...)
+}
+
+// Log ...
+func (e Entry) Log(level Level, s string, args ...interface{}) {
+	LogEntry.mu.RLock()
+	fields := make(log.Fields, len(LogEntry.fields)+len(e.fields))
+	for name, value := range LogEntry.fields {
+		fields[name] = value
+	}
+	LogEntry.mu.RUnlock()
+
+	for name, value := range e.fields {
+		fields[name] = value
+	}
+
+	entry := LogEntry.logger.WithFields(fields)
+	switch level {
+	case ErrorLevel:
+		entry.Errorf(s+"\n", args...)
+	case WarnLevel:
+		entry.Warnf(s+"\n", args...)
+	case InfoLevel:
+		entry.Infof(s+"\n", args...)
+	case DebugLevel:
+		entry.Debugf(s+"\n", args...)
+	case TraceLevel:
+		entry.Tracef(s+"\n", args...)
+	}
 }
 
 // SetLevel ...
@@ -100,16 +236,5 @@ func Inspect(i interface{}) {
 
 // Log ...
 func Log(level Level, s string, args ...interface{}) {
-	switch level {
-	case ErrorLevel:
-		LogEntry.logger.Errorf(s+"\n", args...)
-	case WarnLevel:
-		LogEntry.logger.Warnf(s+"\n", args...)
-	case InfoLevel:
-		LogEntry.logger.Infof(s+"\n", args...)
-	case DebugLevel:
-		LogEntry.logger.Debugf(s+"\n", args...)
-	case TraceLevel:
-		LogEntry.logger.Tracef(s+"\n", args...)
-	}
+	Entry{}.Log(level, s, args...)
 }
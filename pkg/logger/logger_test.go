@@ -1,12 +1,18 @@
 package logger_test
 
 import (
+	"bytes"
+	"encoding/json"
 	"fmt"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/semaphoreci/test-results/pkg/logger"
+	"github.com/sirupsen/logrus"
 	"github.com/sirupsen/logrus/hooks/test"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func Test_SetLogger(t *testing.T) {
@@ -88,3 +94,43 @@ func Test_Log(t *testing.T) {
 		}
 	}
 }
+
+func Test_SetFormat(t *testing.T) {
+	out := &bytes.Buffer{}
+	myLogger := logrus.New()
+	myLogger.SetOutput(out)
+	logger.SetLogger(myLogger)
+	logger.SetLevel(logger.InfoLevel)
+	defer func() { require.NoError(t, logger.SetFormat(logger.FormatText)) }()
+
+	require.NoError(t, logger.SetFormat(logger.FormatJSON))
+	logger.SetFields(logger.Fields{"command": "compile"})
+	logger.With(logger.Fields{"file": "junit.xml", "parser": "golang"}).Warn("Parsing <%s> failed", "junit.xml")
+	logger.Info("Done")
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	require.Len(t, lines, 2)
+
+	line := map[string]interface{}{}
+	require.NoError(t, json.Unmarshal([]byte(lines[0]), &line))
+	assert.Equal(t, "warning", line["level"])
+	assert.Equal(t, "Parsing <junit.xml> failed", line["message"])
+	assert.Equal(t, "compile", line["command"])
+	assert.Equal(t, "junit.xml", line["file"])
+	assert.Equal(t, "golang", line["parser"])
+	_, err := time.Parse(time.RFC3339Nano, line["timestamp"].(string))
+	assert.NoError(t, err)
+
+	line = map[string]interface{}{}
+	require.NoError(t, json.Unmarshal([]byte(lines[1]), &line))
+	assert.Equal(t, "info", line["level"])
+	assert.Equal(t, "compile", line["command"])
+	assert.NotContains(t, line, "file")
+
+	out.Reset()
+	require.NoError(t, logger.SetFormat(logger.FormatText))
+	logger.Info("Done")
+	assert.Equal(t, "* Done\n", out.String())
+
+	assert.Error(t, logger.SetFormat("xml"))
+}
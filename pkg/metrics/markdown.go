@@ -0,0 +1,144 @@
+package metrics
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// maxChartPoints limits number of samples plotted in a chart, the rest is skipped evenly
+const maxChartPoints = 100
+
+// TimelineMarkdown renders commands as a gantt chart, directives are written as they are
+func TimelineMarkdown(commands []Command) string {
+	out := "## 🧭 Job Timeline\n\n```mermaid\ngantt\n    title Job Command Timeline\n    dateFormat X\n    axisFormat %X\n"
+	for i, command := range commands {
+		out += fmt.Sprintf("    %s[%ds] :step%d, %d, %ds\n", command.Directive, ganttSeconds(command), i, command.StartedAt.Unix(), ganttSeconds(command))
+	}
+	out += "```\n"
+
+	return out
+}
+
+// GanttTask renders command as a task of mermaid gantt chart with `dateFormat X`, tag is e.g. `crit` or empty.
+// Characters of the directive which would break the chart are replaced.
+func GanttTask(command Command, id string, tag string) string {
+	if tag != "" {
+		id = tag + ", " + id
+	}
+
+	seconds := ganttSeconds(command)
+	return fmt.Sprintf("    %s[%ds] :%s, %d, %ds\n", MermaidText(command.Directive), seconds, id, command.StartedAt.Unix(), seconds)
+}
+
+// ganttSeconds is the duration of command in whole seconds, at least one so the task is visible
+func ganttSeconds(command Command) int64 {
+	seconds := int64(command.Duration().Seconds())
+	if seconds < 1 {
+		seconds = 1
+	}
+
+	return seconds
+}
+
+// ResourcesMarkdown renders summary and charts of resource usage. Timestamps are shown as written in the
+// system metrics file, elapsed time is measured from the first sample and min and max of plotted samples are shown.
+func ResourcesMarkdown(samples []Sample) (string, error) {
+	if len(samples) == 0 {
+		return "", errors.New("no valid data found")
+	}
+
+	start, err := time.Parse(TimestampLayout, samples[0].Raw)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse start time: %w", err)
+	}
+
+	points := plotted(samples)
+	labels := []string{}
+	for _, sample := range points {
+		t, err := time.Parse(TimestampLayout, sample.Raw)
+		if err != nil {
+			labels = append(labels, "\"??:??\"")
+			continue
+		}
+		labels = append(labels, elapsed(t.Sub(start)))
+	}
+
+	usage := Summarize(points)
+
+	out := "## 🎯 System Metrics Summary\n\n"
+	out += fmt.Sprintf("**Total datapoints:** `%d`  \n", len(samples))
+	out += fmt.Sprintf("**🕒 Time Range:** `%s` → `%s`  \n\n", samples[0].Raw, samples[len(samples)-1].Raw)
+	out += fmt.Sprintf("- **🔥 CPU:** `min: %.2f%%`, `max: %.2f%%`  \n", usage.CPU.Min, usage.CPU.Max)
+	out += fmt.Sprintf("- **🧠 Memory:** `min: %.2f%%`, `max: %.2f%%`  \n", usage.Memory.Min, usage.Memory.Max)
+	out += fmt.Sprintf("- **💽 System Disk:** `min: %.2f%%`, `max: %.2f%%`  \n", usage.SystemDisk.Min, usage.SystemDisk.Max)
+	out += fmt.Sprintf("- **🐳 Docker Disk:** `min: %.2f%%`, `max: %.2f%%`\n\n", usage.DockerDisk.Min, usage.DockerDisk.Max)
+	out += "---\n\n"
+
+	out += chart("CPU Usage", "Usage (%)", labels, series(points, func(s Sample) float64 { return s.CPU })) + "\n"
+	out += chart("Memory Usage", "Usage (%)", labels, series(points, func(s Sample) float64 { return s.Memory })) + "\n"
+	out += chart("System Disk Usage", "Disk Usage (%)", labels, series(points, func(s Sample) float64 { return s.SystemDisk }))
+	out += chart("Docker Disk Usage", "Disk Usage (%)", labels, series(points, func(s Sample) float64 { return s.DockerDisk }))
+
+	return out, nil
+}
+
+// Chart renders value of samples as mermaid xy chart, x axis is time elapsed since the first sample
+func Chart(title string, axis string, samples []Sample, value func(Sample) float64) string {
+	points := plotted(samples)
+
+	labels := []string{}
+	for _, sample := range points {
+		labels = append(labels, elapsed(sample.Timestamp.Sub(points[0].Timestamp)))
+	}
+
+	return chart(title, axis, labels, series(points, value))
+}
+
+// plotted returns samples shown in charts, at most about maxChartPoints of them
+func plotted(samples []Sample) []Sample {
+	step := 1
+	if len(samples) > maxChartPoints {
+		step = len(samples) / maxChartPoints
+	}
+
+	points := []Sample{}
+	for i := 0; i < len(samples); i += step {
+		points = append(points, samples[i])
+	}
+
+	return points
+}
+
+func series(samples []Sample, value func(Sample) float64) []string {
+	values := []string{}
+	for _, sample := range samples {
+		values = append(values, fmt.Sprintf("%.2f", value(sample)))
+	}
+
+	return values
+}
+
+func elapsed(d time.Duration) string {
+	seconds := int(d.Seconds())
+	return fmt.Sprintf("\"%02d:%02d\"", seconds/60, seconds%60)
+}
+
+func chart(title string, axis string, labels []string, values []string) string {
+	out := "```mermaid\n"
+	out += "xychart-beta\n"
+	out += fmt.Sprintf("title \"%s\"\n", title)
+	out += fmt.Sprintf("x-axis [%s]\n", strings.Join(labels, ", "))
+	out += fmt.Sprintf("y-axis \"%s\"\n", axis)
+	out += fmt.Sprintf("line [%s]\n", strings.Join(values, ", "))
+	out += fmt.Sprintf("bar [%s]\n", strings.Join(values, ", "))
+	out += "```\n"
+
+	return out
+}
+
+// MermaidText replaces characters which end a task name or a statement in mermaid charts
+func MermaidText(s string) string {
+	return strings.NewReplacer(":", " ", ";", " ", "#", " ", "\n", " ", "\r", " ").Replace(s)
+}
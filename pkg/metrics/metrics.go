@@ -0,0 +1,275 @@
+// Package metrics reads metrics collected by the Semaphore agent during a job:
+// commands from the job log and resource usage from the system metrics file.
+package metrics
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// TimestampLayout is the layout of timestamps in the system metrics file
+const TimestampLayout = "Mon 02 Jan 2006 03:04:05 PM MST"
+
+// Command is a command of the job, as recorded in the job log
+type Command struct {
+	Directive  string    `json:"directive"`
+	StartedAt  time.Time `json:"startedAt"`
+	FinishedAt time.Time `json:"finishedAt"`
+	ExitCode   int       `json:"exitCode"`
+	// Finished is false for a command that was still running when the job log was read,
+	// its FinishedAt is the last timestamp found in the log
+	Finished bool `json:"finished"`
+}
+
+// Duration of the command
+func (c Command) Duration() time.Duration {
+	return c.FinishedAt.Sub(c.StartedAt)
+}
+
+// Running tells whether the command was running at t
+func (c Command) Running(t time.Time) bool {
+	if t.Before(c.StartedAt) {
+		return false
+	}
+
+	return !c.Finished || !t.After(c.FinishedAt)
+}
+
+// Sample is resource usage at a point in time, in percent
+type Sample struct {
+	// Timestamp is zero when the timestamp is invalid or its time zone is unknown, see ParseSamples
+	Timestamp time.Time `json:"timestamp"`
+	// Raw is the timestamp as written in the system metrics file
+	Raw        string  `json:"-"`
+	CPU        float64 `json:"cpu"`
+	Memory     float64 `json:"memory"`
+	SystemDisk float64 `json:"systemDisk"`
+	DockerDisk float64 `json:"dockerDisk"`
+}
+
+// Range of values of a resource
+type Range struct {
+	Min float64 `json:"min"`
+	Avg float64 `json:"avg"`
+	Max float64 `json:"max"`
+}
+
+func (r *Range) add(value float64, n int) {
+	if n == 0 || value < r.Min {
+		r.Min = value
+	}
+	if n == 0 || value > r.Max {
+		r.Max = value
+	}
+	r.Avg += (value - r.Avg) / float64(n+1)
+}
+
+// Usage sums up resource usage of samples
+type Usage struct {
+	Samples    int   `json:"samples"`
+	CPU        Range `json:"cpu"`
+	Memory     Range `json:"memory"`
+	SystemDisk Range `json:"systemDisk"`
+	DockerDisk Range `json:"dockerDisk"`
+}
+
+// Summarize returns usage of samples
+func Summarize(samples []Sample) Usage {
+	usage := Usage{Samples: len(samples)}
+	for i, sample := range samples {
+		usage.CPU.add(sample.CPU, i)
+		usage.Memory.add(sample.Memory, i)
+		usage.SystemDisk.add(sample.SystemDisk, i)
+		usage.DockerDisk.add(sample.DockerDisk, i)
+	}
+
+	return usage
+}
+
+type jobLogEvent struct {
+	Event      string `json:"event"`
+	Timestamp  int64  `json:"timestamp"`
+	Directive  string `json:"directive"`
+	ExitCode   int    `json:"exit_code"`
+	StartedAt  int64  `json:"started_at"`
+	FinishedAt int64  `json:"finished_at"`
+}
+
+// ReadCommands reads finished commands from the first job log matching pattern, e.g. `/tmp/job_log_*.json`
+func ReadCommands(pattern string) ([]Command, error) {
+	return readJobLog(pattern, ParseCommands)
+}
+
+// ReadJobLog reads commands from the first job log matching pattern, including the command still running
+func ReadJobLog(pattern string) ([]Command, error) {
+	return readJobLog(pattern, ParseJobLog)
+}
+
+func readJobLog(pattern string, parse func(io.Reader) ([]Command, error)) ([]Command, error) {
+	matches, err := filepath.Glob(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid job log pattern %s: %w", pattern, err)
+	}
+	if len(matches) == 0 {
+		return nil, fmt.Errorf("no job log matches %s: %w", pattern, os.ErrNotExist)
+	}
+
+	file, err := os.Open(filepath.Clean(matches[0]))
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	return parse(file)
+}
+
+// ParseCommands reads finished commands from job log, one JSON event per line
+func ParseCommands(r io.Reader) ([]Command, error) {
+	commands, err := ParseJobLog(r)
+	if err != nil {
+		return nil, err
+	}
+
+	finished := []Command{}
+	for _, command := range commands {
+		if command.Finished {
+			finished = append(finished, command)
+		}
+	}
+
+	return finished, nil
+}
+
+// ParseJobLog reads commands from job log, one JSON event per line. Lines which are not valid events are skipped.
+// A command which was started but not finished is returned with Finished set to false.
+func ParseJobLog(r io.Reader) ([]Command, error) {
+	commands := []Command{}
+	running := -1
+	var last int64
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		var event jobLogEvent
+		if err := json.Unmarshal([]byte(line), &event); err != nil {
+			continue
+		}
+
+		if event.Timestamp > last {
+			last = event.Timestamp
+		}
+
+		switch event.Event {
+		case "cmd_started":
+			commands = append(commands, Command{Directive: event.Directive, StartedAt: unix(event.Timestamp)})
+			running = len(commands) - 1
+		case "cmd_finished":
+			command := Command{
+				Directive:  event.Directive,
+				StartedAt:  unix(event.StartedAt),
+				FinishedAt: unix(event.FinishedAt),
+				ExitCode:   event.ExitCode,
+				Finished:   true,
+			}
+
+			if running != -1 && commands[running].Directive == event.Directive {
+				commands[running] = command
+			} else {
+				commands = append(commands, command)
+			}
+			running = -1
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("reading job log: %w", err)
+	}
+
+	if running != -1 {
+		commands[running].FinishedAt = unix(last)
+	}
+
+	return commands, nil
+}
+
+func unix(seconds int64) time.Time {
+	return time.Unix(seconds, 0).UTC()
+}
+
+var sampleRegex = regexp.MustCompile(`^(.*?) \|  cpu:(.*)%,  mem:\s*(.*)%,  system_disk:\s*(.*)%,  docker_disk:\s*(.*)%,(.*)$`)
+
+// ReadSamples reads samples from system metrics file, see ParseSamples
+func ReadSamples(path string, location *time.Location) ([]Sample, error) {
+	file, err := os.Open(filepath.Clean(path))
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	return ParseSamples(file, location)
+}
+
+// ParseSamples reads samples from system metrics, one sample per line. Lines which are not valid samples are skipped.
+// Timestamps are written by the agent in its local time zone, e.g. `EEST`, so they are parsed in location.
+// Samples with an invalid timestamp or a zone unknown in location are kept with zero Timestamp.
+func ParseSamples(r io.Reader, location *time.Location) ([]Sample, error) {
+	samples := []Sample{}
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		matches := sampleRegex.FindStringSubmatch(scanner.Text())
+		if len(matches) != 7 {
+			continue
+		}
+
+		sample := Sample{Timestamp: parseTimestamp(matches[1], location), Raw: matches[1]}
+		values := []*float64{&sample.CPU, &sample.Memory, &sample.SystemDisk, &sample.DockerDisk}
+
+		valid := true
+		for i, value := range values {
+			if _, err := fmt.Sscanf(matches[i+2], "%f", value); err != nil {
+				valid = false
+				break
+			}
+		}
+
+		if valid {
+			samples = append(samples, sample)
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("reading system metrics: %w", err)
+	}
+
+	return samples, nil
+}
+
+// parseTimestamp parses timestamp of a sample in location. time.ParseInLocation gives zones unknown
+// in location a zero offset, such timestamps are returned as zero time, as well as invalid ones.
+func parseTimestamp(value string, location *time.Location) time.Time {
+	t, err := time.ParseInLocation(TimestampLayout, value, location)
+	if err != nil {
+		return time.Time{}
+	}
+
+	// UTC and GMT offsets, e.g. `GMT+3`, are known in every location
+	zone, _ := t.Zone()
+	if t.Location() != location && t.Location() != time.UTC && !strings.HasPrefix(zone, "GMT") {
+		return time.Time{}
+	}
+
+	return t
+}
@@ -0,0 +1,171 @@
+package metrics_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/semaphoreci/test-results/pkg/metrics"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const jobLog = `{"event":"job_started","timestamp":1747000000}
+{"event":"cmd_started","timestamp":1747000000,"directive":"checkout"}
+{"event":"cmd_output","timestamp":1747000001,"output":"Cloning\n"}
+{"event":"cmd_finished","timestamp":1747000005,"directive":"checkout","exit_code":0,"started_at":1747000000,"finished_at":1747000005}
+not a json line
+{"event":"cmd_started","timestamp":1747000005,"directive":"go test ./..."}
+{"event":"cmd_finished","timestamp":1747000012,"directive":"go test ./...","exit_code":1,"started_at":1747000005,"finished_at":1747000012}
+{"event":"cmd_started","timestamp":1747000012,"directive":"sleep 3600"}
+{"event":"cmd_output","timestamp":1747000020,"output":"...\n"}
+`
+
+const systemMetrics = `Sun 11 May 2025 09:46:40 PM UTC |  cpu:4.6%,  mem:  3.20%,  system_disk: 25.77%,  docker_disk: 27.93%,  shared_memory: 0 M
+Sun 11 May 2025 09:46:41 PM UTC |  cpu:50%,  mem:  3.40%,  system_disk: 25.78%,  docker_disk: 27.93%,  shared_memory: 0 M
+garbage
+Sun 11 May 2025 09:46:42 PM UTC |  cpu:n/a%,  mem:  3.40%,  system_disk: 25.78%,  docker_disk: 27.93%,  shared_memory: 0 M
+Sun 11 May 2025 09:46:43 PM UTC |  cpu:1.4%,  mem:  4.00%,  system_disk: 25.79%,  docker_disk: 27.95%,  shared_memory: 0 M
+`
+
+func Test_ParseJobLog(t *testing.T) {
+	commands, err := metrics.ParseJobLog(strings.NewReader(jobLog))
+	require.NoError(t, err)
+	require.Len(t, commands, 3)
+
+	assert.Equal(t, metrics.Command{
+		Directive:  "checkout",
+		StartedAt:  time.Unix(1747000000, 0).UTC(),
+		FinishedAt: time.Unix(1747000005, 0).UTC(),
+		Finished:   true,
+	}, commands[0])
+
+	assert.Equal(t, "go test ./...", commands[1].Directive)
+	assert.Equal(t, 1, commands[1].ExitCode)
+	assert.Equal(t, 7*time.Second, commands[1].Duration())
+
+	t.Run("command still running ends at the last event", func(t *testing.T) {
+		assert.Equal(t, "sleep 3600", commands[2].Directive)
+		assert.False(t, commands[2].Finished)
+		assert.Equal(t, 8*time.Second, commands[2].Duration())
+		assert.True(t, commands[2].Running(time.Unix(1747000100, 0)))
+	})
+
+	assert.True(t, commands[0].Running(time.Unix(1747000005, 0)))
+	assert.False(t, commands[0].Running(time.Unix(1747000006, 0)))
+
+	finished, err := metrics.ParseCommands(strings.NewReader(jobLog))
+	require.NoError(t, err)
+	assert.Equal(t, commands[:2], finished, "only finished commands are parsed")
+}
+
+func Test_ReadCommands(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "job_log_1.json"), []byte(jobLog), 0600))
+
+	commands, err := metrics.ReadCommands(filepath.Join(dir, "job_log_*.json"))
+	require.NoError(t, err)
+	assert.Len(t, commands, 2)
+
+	commands, err = metrics.ReadJobLog(filepath.Join(dir, "job_log_*.json"))
+	require.NoError(t, err)
+	assert.Len(t, commands, 3)
+
+	_, err = metrics.ReadCommands(filepath.Join(dir, "missing_*.json"))
+	assert.ErrorIs(t, err, os.ErrNotExist)
+}
+
+func Test_ParseSamples(t *testing.T) {
+	samples, err := metrics.ParseSamples(strings.NewReader(systemMetrics), time.UTC)
+	require.NoError(t, err)
+	require.Len(t, samples, 3, "invalid lines are skipped")
+
+	assert.Equal(t, metrics.Sample{
+		Timestamp:  time.Date(2025, 5, 11, 21, 46, 40, 0, time.UTC),
+		Raw:        "Sun 11 May 2025 09:46:40 PM UTC",
+		CPU:        4.6,
+		Memory:     3.2,
+		SystemDisk: 25.77,
+		DockerDisk: 27.93,
+	}, samples[0])
+
+	usage := metrics.Summarize(samples)
+	assert.Equal(t, 3, usage.Samples)
+	assert.Equal(t, 1.4, usage.CPU.Min)
+	assert.Equal(t, 50.0, usage.CPU.Max)
+	assert.InDelta(t, 18.67, usage.CPU.Avg, 0.01)
+	assert.Equal(t, 27.95, usage.DockerDisk.Max)
+
+	assert.Equal(t, metrics.Usage{}, metrics.Summarize(nil))
+}
+
+func Test_ParseSamples_TimeZones(t *testing.T) {
+	input := `Sun 11 May 2025 11:46:40 PM EEST |  cpu:4.6%,  mem:  3.20%,  system_disk: 25.77%,  docker_disk: 27.93%,  shared_memory: 0 M
+Sun 11 May 2025 08:46:41 PM UTC |  cpu:5.0%,  mem:  3.20%,  system_disk: 25.77%,  docker_disk: 27.93%,  shared_memory: 0 M
+Sun 11 May 2025 08:46:42 PM GMT |  cpu:5.0%,  mem:  3.20%,  system_disk: 25.77%,  docker_disk: 27.93%,  shared_memory: 0 M
+yesterday |  cpu:5.0%,  mem:  3.20%,  system_disk: 25.77%,  docker_disk: 27.93%,  shared_memory: 0 M
+`
+	helsinki := time.FixedZone("EEST", 3*60*60)
+
+	samples, err := metrics.ParseSamples(strings.NewReader(input), helsinki)
+	require.NoError(t, err)
+	require.Len(t, samples, 4, "samples with invalid timestamps are kept")
+
+	assert.True(t, time.Date(2025, 5, 11, 20, 46, 40, 0, time.UTC).Equal(samples[0].Timestamp), "EEST is resolved in the location")
+	assert.Equal(t, "Sun 11 May 2025 11:46:40 PM EEST", samples[0].Timestamp.Format(metrics.TimestampLayout))
+	assert.True(t, time.Date(2025, 5, 11, 20, 46, 41, 0, time.UTC).Equal(samples[1].Timestamp))
+	assert.True(t, time.Date(2025, 5, 11, 20, 46, 42, 0, time.UTC).Equal(samples[2].Timestamp))
+	assert.True(t, samples[3].Timestamp.IsZero())
+	assert.Equal(t, "yesterday", samples[3].Raw)
+
+	samples, err = metrics.ParseSamples(strings.NewReader(input), time.UTC)
+	require.NoError(t, err)
+	assert.True(t, samples[0].Timestamp.IsZero(), "EEST is unknown in UTC and must not get a zero offset")
+	assert.False(t, samples[1].Timestamp.IsZero())
+}
+
+func Test_Markdown(t *testing.T) {
+	commands, err := metrics.ParseJobLog(strings.NewReader(jobLog))
+	require.NoError(t, err)
+
+	commands[0].Directive = "echo a: b; c # d"
+	assert.Equal(t, "    echo a  b  c   d[5s] :crit, step0, 1747000000, 5s\n", metrics.GanttTask(commands[0], "step0", "crit"))
+
+	samples, err := metrics.ParseSamples(strings.NewReader(systemMetrics), time.UTC)
+	require.NoError(t, err)
+
+	assert.Contains(t, metrics.Chart("CPU Usage", "Usage (%)", samples, func(s metrics.Sample) float64 { return s.CPU }),
+		"x-axis [\"00:00\", \"00:01\", \"00:03\"]\ny-axis \"Usage (%)\"\nline [4.60, 50.00, 1.40]\n")
+
+	_, err = metrics.ResourcesMarkdown(nil)
+	assert.EqualError(t, err, "no valid data found")
+
+	samples[0].Raw = "yesterday"
+	_, err = metrics.ResourcesMarkdown(samples)
+	assert.ErrorContains(t, err, "failed to parse start time")
+}
+
+// Test_Golden compares output with output of command-metrics and resource-metrics before they used this package
+func Test_Golden(t *testing.T) {
+	for _, dir := range []string{"../../priv/job-metrics", "../../priv/job-metrics/edge-cases"} {
+		commands, err := metrics.ReadCommands(filepath.Join(dir, "job_log.json"))
+		require.NoError(t, err)
+		assert.Equal(t, golden(t, dir, "command-metrics.md"), metrics.TimelineMarkdown(commands), dir)
+
+		samples, err := metrics.ReadSamples(filepath.Join(dir, "system-metrics"), time.UTC)
+		require.NoError(t, err)
+		resources, err := metrics.ResourcesMarkdown(samples)
+		require.NoError(t, err)
+		assert.Equal(t, golden(t, dir, "resource-metrics.md"), resources, dir)
+	}
+}
+
+func golden(t *testing.T, dir, name string) string {
+	t.Helper()
+
+	data, err := os.ReadFile(filepath.Join(dir, name))
+	require.NoError(t, err)
+	return string(data)
+}
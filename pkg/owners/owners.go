@@ -0,0 +1,287 @@
+package owners
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/semaphoreci/test-results/pkg/glob"
+	"github.com/semaphoreci/test-results/pkg/parser"
+	"gopkg.in/yaml.v3"
+)
+
+// CodeOwnersLocations lists paths where CODEOWNERS file is looked up, relative to repository root
+var CodeOwnersLocations = []string{".github/CODEOWNERS", "CODEOWNERS", "docs/CODEOWNERS"}
+
+// Rule is a single line of CODEOWNERS file
+type Rule struct {
+	Pattern string
+	Owners  []string
+	re      *regexp.Regexp
+}
+
+// CodeOwners ...
+type CodeOwners struct {
+	Rules []Rule
+}
+
+// FindCodeOwners returns path of CODEOWNERS file in repository root, empty when there is none
+func FindCodeOwners(root string) string {
+	for _, location := range CodeOwnersLocations {
+		path := filepath.Join(root, filepath.FromSlash(location))
+		if info, err := os.Stat(path); err == nil && !info.IsDir() {
+			return path
+		}
+	}
+
+	return ""
+}
+
+// LoadCodeOwners ...
+func LoadCodeOwners(path string) (*CodeOwners, error) {
+	data, err := os.ReadFile(filepath.Clean(path))
+	if err != nil {
+		return nil, err
+	}
+
+	return ParseCodeOwners(data)
+}
+
+// ParseCodeOwners parses CODEOWNERS file, see https://docs.github.com/en/repositories/managing-your-repositorys-settings-and-features/customizing-your-repository/about-code-owners
+func ParseCodeOwners(data []byte) (*CodeOwners, error) {
+	codeOwners := &CodeOwners{Rules: []Rule{}}
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := scanner.Text()
+		if i := strings.Index(text, "#"); i >= 0 {
+			text = text[:i]
+		}
+
+		fields := strings.Fields(text)
+		if len(fields) == 0 {
+			continue
+		}
+
+		re, err := patternToRegexp(fields[0])
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %v", line, err)
+		}
+
+		codeOwners.Rules = append(codeOwners.Rules, Rule{Pattern: fields[0], Owners: fields[1:], re: re})
+	}
+
+	return codeOwners, scanner.Err()
+}
+
+// patternToRegexp converts gitignore style pattern into regexp
+func patternToRegexp(pattern string) (*regexp.Regexp, error) {
+	directory := strings.HasSuffix(pattern, "/")
+	pattern = strings.TrimSuffix(pattern, "/")
+	anchored := strings.Contains(pattern, "/")
+	pattern = strings.TrimPrefix(pattern, "/")
+
+	var b strings.Builder
+	if anchored {
+		b.WriteString("^")
+	} else {
+		b.WriteString("^(?:.*/)?")
+	}
+
+	b.WriteString(glob.PathExpr(pattern))
+
+	switch {
+	case directory:
+		b.WriteString("/.*$")
+	case strings.HasSuffix(pattern, "*") && !strings.HasSuffix(pattern, "**"):
+		// `docs/*` matches files in docs directory, but not in its subdirectories
+		b.WriteString("$")
+	default:
+		b.WriteString("(?:/.*)?$")
+	}
+
+	return regexp.Compile(b.String())
+}
+
+// Owners returns owners of the file, the last matching rule wins
+func (c *CodeOwners) Owners(file string) ([]string, bool) {
+	file = strings.TrimPrefix(filepath.ToSlash(filepath.Clean(file)), "./")
+	file = strings.TrimPrefix(file, "/")
+
+	for i := len(c.Rules) - 1; i >= 0; i-- {
+		if c.Rules[i].re.MatchString(file) {
+			return c.Rules[i].Owners, true
+		}
+	}
+
+	return nil, false
+}
+
+// Override assigns owners to tests matched by ID or by suite, classname and name patterns
+type Override struct {
+	ID        string   `yaml:"id"`
+	Suite     string   `yaml:"suite"`
+	Classname string   `yaml:"classname"`
+	Name      string   `yaml:"name"`
+	Owners    []string `yaml:"owners"`
+
+	suite     *regexp.Regexp
+	classname *regexp.Regexp
+	name      *regexp.Regexp
+}
+
+// Overrides ...
+type Overrides struct {
+	Tests []*Override `yaml:"tests"`
+}
+
+// LoadOverrides reads owner overrides from YAML file
+//
+//	tests:
+//	  - suite: "Payments*"
+//	    owners: ["@org/payments"]
+//	  - id: 0f6a6e4e-...
+//	    owners: ["@jane"]
+func LoadOverrides(path string) (*Overrides, error) {
+	data, err := os.ReadFile(filepath.Clean(path))
+	if err != nil {
+		return nil, err
+	}
+
+	overrides := &Overrides{}
+	if err := yaml.Unmarshal(data, overrides); err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+
+	for i, override := range overrides.Tests {
+		if override == nil || override.ID == "" && override.Suite == "" && override.Classname == "" && override.Name == "" {
+			return nil, fmt.Errorf("%s: entry #%d: id, suite, classname or name is required", path, i+1)
+		}
+
+		if len(override.Owners) == 0 {
+			return nil, fmt.Errorf("%s: entry #%d: owners are required", path, i+1)
+		}
+
+		override.suite = namePattern(override.Suite)
+		override.classname = namePattern(override.Classname)
+		override.name = namePattern(override.Name)
+	}
+
+	return overrides, nil
+}
+
+func namePattern(pattern string) *regexp.Regexp {
+	if pattern == "" {
+		return nil
+	}
+
+	return glob.Name(pattern)
+}
+
+func (o *Override) matches(suite parser.Suite, test parser.Test) bool {
+	if o.ID != "" && o.ID != test.ID {
+		return false
+	}
+
+	for _, m := range []struct {
+		re    *regexp.Regexp
+		value string
+	}{{o.suite, suite.Name}, {o.classname, test.Classname}, {o.name, test.Name}} {
+		if m.re != nil && !m.re.MatchString(m.value) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Resolver assigns owners to tests
+type Resolver struct {
+	CodeOwners *CodeOwners
+	Overrides  *Overrides
+	// Root is stripped from absolute test file paths, usually the repository root
+	Root string
+}
+
+// Resolve returns owners of the test. Overrides take precedence, then CODEOWNERS rules
+// are matched against the test file, falling back to the suite's file.
+func (r *Resolver) Resolve(suite parser.Suite, test parser.Test) []string {
+	if r.Overrides != nil {
+		for _, override := range r.Overrides.Tests {
+			if override.matches(suite, test) {
+				return override.Owners
+			}
+		}
+	}
+
+	if r.CodeOwners == nil {
+		return nil
+	}
+
+	for _, file := range []string{test.File, suiteFile(suite)} {
+		if file == "" {
+			continue
+		}
+
+		if owners, found := r.CodeOwners.Owners(r.relative(file)); found {
+			return owners
+		}
+	}
+
+	return nil
+}
+
+func (r *Resolver) relative(file string) string {
+	if r.Root == "" || !filepath.IsAbs(file) {
+		return file
+	}
+
+	if rel, err := filepath.Rel(r.Root, file); err == nil && !strings.HasPrefix(rel, "..") {
+		return rel
+	}
+
+	return file
+}
+
+// suiteFile returns file of the suite, taken from the `file` property or its tests. Suite names are not
+// used, they are rarely paths and could match unrelated CODEOWNERS rules.
+func suiteFile(suite parser.Suite) string {
+	if file := suite.Properties["file"]; file != "" {
+		return file
+	}
+
+	for _, test := range suite.Tests {
+		if test.File != "" {
+			return test.File
+		}
+	}
+
+	return ""
+}
+
+// Apply annotates tests with their owners, number of tests with owners is returned
+func (r *Resolver) Apply(result *parser.Result) int {
+	annotated := 0
+	for i := range result.TestResults {
+		for j := range result.TestResults[i].Suites {
+			suite := &result.TestResults[i].Suites[j]
+			for k := range suite.Tests {
+				owners := r.Resolve(*suite, suite.Tests[k])
+				if len(owners) == 0 {
+					continue
+				}
+
+				suite.Tests[k].Owners = append([]string{}, owners...)
+				annotated++
+			}
+		}
+	}
+
+	return annotated
+}
@@ -0,0 +1,140 @@
+package owners_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/semaphoreci/test-results/pkg/owners"
+	"github.com/semaphoreci/test-results/pkg/parser"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const codeOwnersFile = `
+# Default owners
+*             @org/everyone
+*.go          @org/backend   # Go files
+/docs/*       @org/docs
+apps/         @org/apps
+/spec/models/ @org/models @jane
+**/logs       @org/logs
+/vendor/
+`
+
+func Test_CodeOwners(t *testing.T) {
+	codeOwners, err := owners.ParseCodeOwners([]byte(codeOwnersFile))
+	require.NoError(t, err)
+	require.Len(t, codeOwners.Rules, 7)
+
+	for file, expected := range map[string][]string{
+		"README.md":                   {"@org/everyone"},
+		"pkg/foo/foo.go":              {"@org/backend"},
+		"./docs/index.md":             {"@org/docs"},
+		"docs/guides/intro.md":        {"@org/everyone"},
+		"apps/web/main.js":            {"@org/apps"},
+		"services/apps/api/server.go": {"@org/apps"},
+		"spec/models/user_spec.rb":    {"@org/models", "@jane"},
+		"/spec/models/user_spec.rb":   {"@org/models", "@jane"},
+		"lib/spec/models/x_spec.rb":   {"@org/everyone"},
+		"a/b/logs/out.txt":            {"@org/logs"},
+		"vendor/lib/lib.go":           {},
+	} {
+		found, ok := codeOwners.Owners(file)
+		assert.True(t, ok, file)
+		assert.Equal(t, expected, found, file)
+	}
+
+	codeOwners, err = owners.ParseCodeOwners([]byte("/spec/ @org/qa\n"))
+	require.NoError(t, err)
+	_, ok := codeOwners.Owners("lib/foo.rb")
+	assert.False(t, ok)
+}
+
+func Test_FindCodeOwners(t *testing.T) {
+	root := t.TempDir()
+	assert.Equal(t, "", owners.FindCodeOwners(root))
+
+	require.NoError(t, os.MkdirAll(filepath.Join(root, ".github"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(root, ".github", "CODEOWNERS"), []byte("* @a\n"), 0600))
+	assert.Equal(t, filepath.Join(root, ".github", "CODEOWNERS"), owners.FindCodeOwners(root))
+}
+
+func Test_Resolver(t *testing.T) {
+	root := t.TempDir()
+	overridesPath := filepath.Join(root, "owners.yml")
+	require.NoError(t, os.WriteFile(overridesPath, []byte(`
+tests:
+  - suite: "Payments*"
+    owners: ["@org/payments"]
+  - classname: "*Flaky*"
+    name: "sometimes*"
+    owners: ["@org/qa"]
+`), 0600))
+
+	overrides, err := owners.LoadOverrides(overridesPath)
+	require.NoError(t, err)
+
+	codeOwners, err := owners.ParseCodeOwners([]byte("/spec/ @org/qa\n/spec/models/ @org/models\n"))
+	require.NoError(t, err)
+
+	resolver := owners.Resolver{CodeOwners: codeOwners, Overrides: overrides, Root: root}
+
+	testResults := parser.NewTestResults()
+	testResults.EnsureID()
+
+	newSuite := func(name string, tests ...parser.Test) parser.Suite {
+		suite := parser.NewSuite()
+		suite.Name = name
+		suite.EnsureID(testResults)
+		for _, test := range tests {
+			test.EnsureID(suite)
+			suite.AppendTest(test)
+		}
+		return suite
+	}
+
+	newTest := func(name, classname, file string) parser.Test {
+		test := parser.NewTest()
+		test.Name = name
+		test.Classname = classname
+		test.File = file
+		return test
+	}
+
+	testResults.Suites = []parser.Suite{
+		newSuite("User",
+			newTest("with file", "", filepath.Join(root, "spec", "models", "user_spec.rb")),
+			newTest("without file", "", ""),
+			newTest("sometimes fails", "MyFlakyTest", "spec/models/user_spec.rb"),
+		),
+		newSuite("spec/requests/api_spec.rb", newTest("named like a file", "", "")),
+		newSuite("Payments::Charge", newTest("overridden", "", "spec/models/charge_spec.rb")),
+		newSuite("Other", newTest("unowned", "", "")),
+	}
+
+	result := parser.Result{TestResults: []parser.TestResults{testResults}}
+	assert.Equal(t, 4, resolver.Apply(&result))
+
+	suites := result.TestResults[0].Suites
+	assert.Equal(t, []string{"@org/models"}, suites[0].Tests[0].Owners, "absolute paths should be relative to root")
+	assert.Equal(t, []string{"@org/models"}, suites[0].Tests[1].Owners, "should fall back to the suite's file")
+	assert.Equal(t, []string{"@org/qa"}, suites[0].Tests[2].Owners)
+	assert.Nil(t, suites[1].Tests[0].Owners, "suite name is not a file")
+	assert.Equal(t, []string{"@org/payments"}, suites[2].Tests[0].Owners, "overrides take precedence")
+	assert.Nil(t, suites[3].Tests[0].Owners)
+}
+
+func Test_LoadOverrides_Invalid(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "owners.yml")
+
+	for _, data := range []string{
+		"tests:\n  - owners: [a]\n",
+		"tests:\n  - suite: a\n",
+		"tests: [",
+	} {
+		require.NoError(t, os.WriteFile(path, []byte(data), 0600))
+		_, err := owners.LoadOverrides(path)
+		assert.Error(t, err, data)
+	}
+}
@@ -0,0 +1,61 @@
+package parser
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrorKind classifies why parsing of a file failed
+type ErrorKind string
+
+const (
+	// ErrorIO indicates that file could not be read
+	ErrorIO ErrorKind = "io"
+	// ErrorInvalidXML indicates that file is not a valid XML document
+	ErrorInvalidXML ErrorKind = "invalid-xml"
+	// ErrorUnsupportedRoot indicates that root element is not supported by the parser
+	ErrorUnsupportedRoot ErrorKind = "unsupported-root"
+	// ErrorPartial indicates that document is broken, e.g. truncated, and only elements before the error were parsed
+	ErrorPartial ErrorKind = "partial"
+)
+
+// FileError is returned by parsers when file could not be parsed completely
+type FileError struct {
+	Kind ErrorKind
+	Path string
+	Err  error
+}
+
+// NewFileError ...
+func NewFileError(kind ErrorKind, path string, err error) *FileError {
+	return &FileError{Kind: kind, Path: path, Err: err}
+}
+
+func (e *FileError) Error() string {
+	return fmt.Sprintf("%s: %s: %v", e.Path, e.Kind, e.Err)
+}
+
+func (e *FileError) Unwrap() error {
+	return e.Err
+}
+
+// ErrorKindOf returns kind of FileError wrapped in err, empty when err is not a FileError
+func ErrorKindOf(err error) ErrorKind {
+	var fileError *FileError
+	if errors.As(err, &fileError) {
+		return fileError.Kind
+	}
+
+	return ""
+}
+
+// Fail marks test results as failed to parse with err. Status message is the cause of err, without path and kind.
+func (me *TestResults) Fail(err error) {
+	me.Status = StatusError
+	me.StatusMessage = err.Error()
+
+	var fileError *FileError
+	if errors.As(err, &fileError) {
+		me.StatusMessage = fileError.Err.Error()
+	}
+}
@@ -2,7 +2,10 @@ package parser
 
 import (
 	"fmt"
+	"regexp"
+	"sort"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/semaphoreci/test-results/pkg/logger"
@@ -12,7 +15,12 @@ import (
 func ParseProperties(xml XMLElement) Properties {
 	properties := make(map[string]string)
 	for _, node := range xml.Children {
-		properties[node.Attr("name")] = node.Attr("value")
+		value, found := node.Attributes["value"]
+		if !found {
+			// JUnit 5 and pytest write multiline values as element contents
+			value = strings.TrimSpace(string(node.Contents))
+		}
+		properties[node.Attr("name")] = value
 	}
 
 	return properties
@@ -26,6 +34,52 @@ func PropertyExists(properties Properties, propertyName string) bool {
 	return false
 }
 
+// TagProperties lists test properties holding comma or whitespace separated tags,
+// e.g. JUnit 5 `@Tag`, pytest markers or NUnit categories
+var TagProperties = []string{"tag", "tags", "marker", "markers", "category", "categories"}
+
+var nameTagRegexp = regexp.MustCompile(`(?:^|\s)@([\w:.-]+)`)
+
+// ParseTags extracts tags from test properties and from `@tag` tokens in the test name, as Cucumber writes them
+func ParseTags(name string, properties Properties) []string {
+	tags := []string{}
+	for _, property := range TagProperties {
+		if value, found := properties[property]; found {
+			tags = append(tags, strings.FieldsFunc(value, func(r rune) bool {
+				return r == ',' || r == ' ' || r == '\t' || r == '\n'
+			})...)
+		}
+	}
+
+	for _, match := range nameTagRegexp.FindAllStringSubmatch(name, -1) {
+		tags = append(tags, match[1])
+	}
+
+	return NormalizeTags(tags)
+}
+
+// NormalizeTags lowercases tags, strips `@` and `:` prefixes and removes duplicates. Nil is returned when there are no tags.
+func NormalizeTags(tags []string) []string {
+	seen := map[string]bool{}
+	normalized := []string{}
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimLeft(strings.TrimSpace(tag), "@:"))
+		if tag == "" || seen[tag] {
+			continue
+		}
+
+		seen[tag] = true
+		normalized = append(normalized, tag)
+	}
+
+	if len(normalized) == 0 {
+		return nil
+	}
+
+	sort.Strings(normalized)
+	return normalized
+}
+
 // ParseFailure parses <failure> element from junit schema
 func ParseFailure(xml XMLElement) *Failure {
 	failure := NewFailure()
@@ -119,3 +119,18 @@ func TestParseBool(t *testing.T) {
 		})
 	}
 }
+
+func TestParseTags(t *testing.T) {
+	assert.Nil(t, parser.ParseTags("logs in", nil))
+	assert.Equal(t, []string{"integration", "slow", "smoke"}, parser.ParseTags("logs in @Smoke", parser.Properties{
+		"markers":  "slow,integration",
+		"category": "smoke",
+		"seed":     "1234",
+	}))
+	assert.Nil(t, parser.ParseTags("sends mail to user@example.com", nil), "emails should not be tags")
+}
+
+func TestNormalizeTags(t *testing.T) {
+	assert.Nil(t, parser.NormalizeTags([]string{" ", "@"}))
+	assert.Equal(t, []string{"slow", "smoke"}, parser.NormalizeTags([]string{"@smoke", ":slow", "Smoke", " slow "}))
+}
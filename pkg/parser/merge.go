@@ -0,0 +1,49 @@
+package parser
+
+import "fmt"
+
+// MergeStrategy decides which result is kept when the same test is found in more than one report
+type MergeStrategy string
+
+const (
+	// MergeWorst keeps errors over failures, failures over quarantined failures and those over passed tests.
+	// Any result is kept over a skipped one. It is the default.
+	MergeWorst MergeStrategy = "worst"
+	// MergeBest keeps passed tests over quarantined failures, those over failures and failures over errors,
+	// e.g. when failed tests were retried
+	MergeBest MergeStrategy = "best"
+	// MergeLast keeps the result from the report combined last
+	MergeLast MergeStrategy = "last"
+)
+
+// ParseMergeStrategy validates strategy name, empty name is MergeWorst
+func ParseMergeStrategy(name string) (MergeStrategy, error) {
+	switch strategy := MergeStrategy(name); strategy {
+	case "":
+		return MergeWorst, nil
+	case MergeWorst, MergeBest, MergeLast:
+		return strategy, nil
+	default:
+		return "", fmt.Errorf("unknown merge strategy %q, expected one of: worst, best, last", name)
+	}
+}
+
+// worstRanks and bestRanks order states from the result kept least to the one kept most.
+// Skipped and disabled tests were not run, any other result replaces them. Quarantined failures
+// rank between passed and failed tests, so the outcome doesn't depend on the order of reports.
+var (
+	worstRanks = map[State]int{StateSkipped: 0, StateDisabled: 0, StatePassed: 1, StateQuarantined: 2, StateFailed: 3, StateError: 4}
+	bestRanks  = map[State]int{StateSkipped: 0, StateDisabled: 0, StateError: 1, StateFailed: 2, StateQuarantined: 3, StatePassed: 4}
+)
+
+// replaces tells if other result of the same test should replace existing one
+func (me MergeStrategy) replaces(existing, other Test) bool {
+	switch me {
+	case MergeLast:
+		return true
+	case MergeBest:
+		return bestRanks[other.State] > bestRanks[existing.State]
+	default:
+		return worstRanks[other.State] > worstRanks[existing.State]
+	}
+}
@@ -2,7 +2,9 @@ package parser
 
 // Parser ...
 type Parser interface {
-	Parse(string) TestResults
+	// Parse parses file at path. When it fails, returned test results have StatusError and error is a *FileError.
+	// Results of partially parsed files contain everything parsed before the error.
+	Parse(string) (TestResults, error)
 	IsApplicable(string) bool
 	GetName() string
 }
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"sort"
+	"strconv"
 	"time"
 
 	"github.com/google/uuid"
@@ -26,6 +27,8 @@ const (
 	StateSkipped State = "skipped"
 	// StateDisabled indicates that test was disabled
 	StateDisabled State = "disabled"
+	// StateQuarantined indicates that test failed or errored, but is listed in quarantine so the failure is not counted
+	StateQuarantined State = "quarantined"
 )
 
 // Status stores information about parsing results
@@ -53,12 +56,17 @@ func NewResult() Result {
 
 // Combine test results that are part of result
 func (me *Result) Combine(other Result) {
+	me.CombineWith(other, MergeWorst)
+}
+
+// CombineWith combines test results that are part of result, strategy decides which result of the same test is kept
+func (me *Result) CombineWith(other Result, strategy MergeStrategy) {
 	for i := range other.TestResults {
 		testResult := other.TestResults[i]
 		testResult.Flatten()
 		foundTestResultsIdx, found := me.hasTestResults(testResult)
 		if found {
-			me.TestResults[foundTestResultsIdx].Combine(testResult)
+			me.TestResults[foundTestResultsIdx].CombineWith(testResult, strategy)
 			me.TestResults[foundTestResultsIdx].Aggregate()
 		} else {
 			me.TestResults = append(me.TestResults, testResult)
@@ -72,6 +80,48 @@ func (me *Result) Combine(other Result) {
 	}
 }
 
+// FilterTags keeps tests tagged with any of include tags and drops tests tagged with any of exclude tags.
+// Empty include keeps all tests. Summaries are aggregated again and number of removed tests is returned.
+func (me *Result) FilterTags(include, exclude []string) int {
+	include = NormalizeTags(include)
+	exclude = NormalizeTags(exclude)
+	removed := 0
+
+	for i := range me.TestResults {
+		testResults := &me.TestResults[i]
+		suites := []Suite{}
+
+		for _, suite := range testResults.Suites {
+			tests := []Test{}
+			for _, test := range suite.Tests {
+				if (len(include) == 0 || test.HasTag(include...)) && !test.HasTag(exclude...) {
+					tests = append(tests, test)
+				}
+			}
+
+			if len(tests) == len(suite.Tests) {
+				suites = append(suites, suite)
+				continue
+			}
+
+			removed += len(suite.Tests) - len(tests)
+			if len(tests) == 0 {
+				continue
+			}
+
+			suite.Tests = tests
+			suite.Summary = Summary{}
+			suite.Aggregate()
+			suites = append(suites, suite)
+		}
+
+		testResults.Suites = suites
+		testResults.Aggregate()
+	}
+
+	return removed
+}
+
 // Flatten makes sure we don't have duplicated suites in test results
 func (me *TestResults) Flatten() {
 	testResults := NewTestResults()
@@ -124,11 +174,16 @@ func NewTestResults() TestResults {
 
 // Combine ...
 func (me *TestResults) Combine(other TestResults) {
+	me.CombineWith(other, MergeWorst)
+}
+
+// CombineWith combines suites of test results with the same ID, strategy decides which result of the same test is kept
+func (me *TestResults) CombineWith(other TestResults, strategy MergeStrategy) {
 	if me.ID == other.ID {
 		for i := range other.Suites {
 			foundSuiteIdx, found := me.hasSuite(other.Suites[i])
 			if found {
-				me.Suites[foundSuiteIdx].Combine(other.Suites[i])
+				me.Suites[foundSuiteIdx].CombineWith(other.Suites[i], strategy)
 				me.Suites[foundSuiteIdx].Aggregate()
 			} else {
 				me.Suites = append(me.Suites, other.Suites[i])
@@ -225,13 +280,7 @@ func (me *TestResults) Aggregate() {
 	summary := Summary{}
 
 	for i := range me.Suites {
-		summary.Duration += me.Suites[i].Summary.Duration
-		summary.Skipped += me.Suites[i].Summary.Skipped
-		summary.Error += me.Suites[i].Summary.Error
-		summary.Total += me.Suites[i].Summary.Total
-		summary.Failed += me.Suites[i].Summary.Failed
-		summary.Passed += me.Suites[i].Summary.Passed
-		summary.Disabled += me.Suites[i].Summary.Disabled
+		summary.Merge(&me.Suites[i].Summary)
 	}
 
 	me.Summary = summary
@@ -260,18 +309,22 @@ func NewSuite() Suite {
 
 // Combine ...
 func (me *Suite) Combine(other Suite) {
+	me.CombineWith(other, MergeWorst)
+}
+
+// CombineWith combines tests of suites with the same ID, strategy decides which result of the same test is kept
+func (me *Suite) CombineWith(other Suite, strategy MergeStrategy) {
 	if me.ID == other.ID {
 		for i := range other.Tests {
-			if !me.hasTest(other.Tests[i]) {
+			foundIndex := me.testIndex(other.Tests[i])
+			if foundIndex == -1 {
 				me.Tests = append(me.Tests, other.Tests[i])
+				continue
 			}
 
-			shouldReplace, indexToReplace := me.shouldReplaceTest(other.Tests[i])
-
-			if shouldReplace && indexToReplace != -1 {
-				me.Tests[indexToReplace] = other.Tests[i]
+			if strategy.replaces(me.Tests[foundIndex], other.Tests[i]) {
+				me.Tests[foundIndex] = other.Tests[i]
 			}
-
 		}
 
 		sort.SliceStable(me.Tests, func(i, j int) bool {
@@ -279,40 +332,14 @@ func (me *Suite) Combine(other Suite) {
 		})
 	}
 }
-func (me *Suite) shouldReplaceTest(test Test) (shouldReplace bool, foundIndex int) {
-	foundIndex = -1
-	shouldReplace = false
-	for i := range me.Tests {
-		if me.Tests[i].ID == test.ID {
-			foundIndex = i
-			break
-		}
-	}
-
-	if foundIndex == -1 {
-		return
-	} else {
-		foundTest := me.Tests[foundIndex]
-
-		if foundTest.State == StateSkipped {
-			shouldReplace = true
-			return
-		}
-		if foundTest.State == StatePassed && test.State == StateFailed || test.State == StateError {
-			shouldReplace = true
-			return
-		}
 
-		return
-	}
-}
-func (me *Suite) hasTest(test Test) bool {
+func (me *Suite) testIndex(test Test) int {
 	for i := range me.Tests {
 		if me.Tests[i].ID == test.ID {
-			return true
+			return i
 		}
 	}
-	return false
+	return -1
 }
 
 // Aggregate all tests in suite
@@ -333,6 +360,12 @@ func (me *Suite) Aggregate() {
 			summary.Passed++
 		case StateDisabled:
 			summary.Disabled++
+		case StateQuarantined:
+			summary.Quarantined++
+		}
+
+		for _, tag := range test.Tags {
+			summary.mergeTag(tag, testSummary(test))
 		}
 	}
 
@@ -427,6 +460,24 @@ type Test struct {
 	SystemOut string        `json:"systemOut"`
 	SystemErr string        `json:"systemErr"`
 	SemEnv    SemEnv        `json:"semaphoreEnv"`
+	Owners    []string      `json:"owners,omitempty"`
+	// Properties of the testcase, written by JUnit 5 and pytest
+	Properties Properties `json:"properties,omitempty"`
+	// Tags are normalized tags of the test, see ParseTags
+	Tags []string `json:"tags,omitempty"`
+}
+
+// HasTag checks if test is tagged with any of the tags
+func (me *Test) HasTag(tags ...string) bool {
+	for _, tag := range tags {
+		for _, testTag := range me.Tags {
+			if testTag == tag {
+				return true
+			}
+		}
+	}
+
+	return false
 }
 
 // NewTest ...
@@ -476,6 +527,10 @@ type Summary struct {
 	Failed   int           `json:"failed"`
 	Disabled int           `json:"disabled"`
 	Duration time.Duration `json:"duration"`
+	// Quarantined counts failures of quarantined tests, they are not included in Failed and Error
+	Quarantined int `json:"quarantined,omitempty"`
+	// Tags breaks the summary down by test tags
+	Tags map[string]Summary `json:"tags,omitempty"`
 }
 
 // Merge merges two summaries together summing each field
@@ -487,14 +542,92 @@ func (s *Summary) Merge(withSummary *Summary) {
 	s.Failed += withSummary.Failed
 	s.Disabled += withSummary.Disabled
 	s.Duration += withSummary.Duration
+	s.Quarantined += withSummary.Quarantined
+
+	for tag, tagSummary := range withSummary.Tags {
+		s.mergeTag(tag, tagSummary)
+	}
+}
+
+func (s *Summary) mergeTag(tag string, withSummary Summary) {
+	if s.Tags == nil {
+		s.Tags = map[string]Summary{}
+	}
+
+	tagSummary := s.Tags[tag]
+	tagSummary.Merge(&withSummary)
+	s.Tags[tag] = tagSummary
+}
+
+// testSummary summarizes a single test
+func testSummary(test Test) Summary {
+	s := Summary{Total: 1, Duration: test.Duration}
+	switch test.State {
+	case StateSkipped:
+		s.Skipped++
+	case StateFailed:
+		s.Failed++
+	case StateError:
+		s.Error++
+	case StatePassed:
+		s.Passed++
+	case StateDisabled:
+		s.Disabled++
+	case StateQuarantined:
+		s.Quarantined++
+	}
+
+	return s
 }
 
+// PassRate returns percentage of passed tests, skipped, disabled and quarantined tests are not taken into account
+func (s *Summary) PassRate() float64 {
+	executed := s.Executed()
+	if executed <= 0 {
+		return 0
+	}
+
+	return float64(s.Passed) / float64(executed) * 100
+}
+
+// Executed returns number of tests which were run, i.e. not skipped, disabled or quarantined
+func (s *Summary) Executed() int {
+	return s.Total - s.Skipped - s.Disabled - s.Quarantined
+}
+
+// TestResult is a single execution of a test, used as a row of the test history
 type TestResult struct {
-	TestId   string
-	GitSha   string
-	Duration time.Duration
-	JobId    string
-	State    State
+	TestId    string        `json:"testId"`
+	Name      string        `json:"name"`
+	Framework string        `json:"framework,omitempty"`
+	GitSha    string        `json:"gitSha"`
+	Duration  time.Duration `json:"duration"`
+	JobId     string        `json:"jobId"`
+	State     State         `json:"state"`
+	Timestamp time.Time     `json:"timestamp"`
+}
+
+// NewTestResult creates history row for a test, falling back to timestamp when job start time is unknown
+func NewTestResult(testResults TestResults, suite Suite, test Test, timestamp time.Time) TestResult {
+	if startedAt, err := strconv.ParseInt(test.SemEnv.JobStartedAt, 10, 64); err == nil && startedAt > 0 {
+		timestamp = time.Unix(startedAt, 0)
+	}
+
+	name := test.Name
+	if suite.Name != "" {
+		name = suite.Name + " › " + test.Name
+	}
+
+	return TestResult{
+		TestId:    test.ID,
+		Name:      name,
+		Framework: testResults.Framework,
+		GitSha:    test.SemEnv.GitRefSha,
+		Duration:  test.Duration,
+		JobId:     test.SemEnv.JobId,
+		State:     test.State,
+		Timestamp: timestamp.UTC(),
+	}
 }
 
 func (t *TestResult) String() []string {
@@ -115,6 +115,47 @@ func Test_Suite_Combine(t *testing.T) {
 	assert.Equal(t, true, suite.Tests[len(suite.Tests)-1].State == StateFailed, "If tests are the same, failed state should take priority over passed state")
 }
 
+func Test_Suite_CombineWith(t *testing.T) {
+	combine := func(strategy MergeStrategy, states ...State) State {
+		suite := newSuite("1", "foo")
+		for _, state := range states {
+			other := newSuite("1", "foo")
+			test := NewTest()
+			test.ID = "1"
+			test.State = state
+			other.AppendTest(test)
+			suite.CombineWith(other, strategy)
+		}
+
+		assert.Len(t, suite.Tests, 1)
+		return suite.Tests[0].State
+	}
+
+	assert.Equal(t, StateFailed, combine(MergeWorst, StatePassed, StateFailed, StatePassed))
+	assert.Equal(t, StatePassed, combine(MergeBest, StateFailed, StatePassed, StateFailed))
+	assert.Equal(t, StateFailed, combine(MergeBest, StateSkipped, StateFailed))
+	assert.Equal(t, StateSkipped, combine(MergeLast, StateFailed, StatePassed, StateSkipped))
+
+	t.Run("quarantined ranks between passed and failed in any order", func(t *testing.T) {
+		assert.Equal(t, StateQuarantined, combine(MergeWorst, StatePassed, StateQuarantined))
+		assert.Equal(t, StateQuarantined, combine(MergeWorst, StateQuarantined, StatePassed))
+		assert.Equal(t, StateFailed, combine(MergeWorst, StateQuarantined, StateFailed))
+		assert.Equal(t, StateFailed, combine(MergeWorst, StateFailed, StateQuarantined))
+		assert.Equal(t, StatePassed, combine(MergeBest, StatePassed, StateQuarantined))
+		assert.Equal(t, StatePassed, combine(MergeBest, StateQuarantined, StatePassed))
+		assert.Equal(t, StateQuarantined, combine(MergeBest, StateFailed, StateQuarantined))
+		assert.Equal(t, StateQuarantined, combine(MergeBest, StateQuarantined, StateFailed))
+		assert.Equal(t, StateQuarantined, combine(MergeWorst, StateSkipped, StateQuarantined))
+	})
+
+	strategy, err := ParseMergeStrategy("")
+	assert.NoError(t, err)
+	assert.Equal(t, MergeWorst, strategy)
+
+	_, err = ParseMergeStrategy("first")
+	assert.Error(t, err)
+}
+
 func Test_NewTest_Results(t *testing.T) {
 	testResults := NewTestResults()
 
@@ -141,7 +182,7 @@ func Test_TestResults_Aggregate(t *testing.T) {
 	testResults.Suites = append(testResults.Suites, suite)
 
 	testResults.Aggregate()
-	assert.Equal(t, testResults.Summary, Summary{6, 1, 2, 2, 1, 1, 1})
+	assert.Equal(t, testResults.Summary, Summary{6, 1, 2, 2, 1, 1, 1, 0, nil})
 
 	suite = NewSuite()
 	suite.Summary.Total = 12
@@ -155,7 +196,7 @@ func Test_TestResults_Aggregate(t *testing.T) {
 	testResults.Suites = append(testResults.Suites, suite)
 	testResults.Aggregate()
 
-	assert.Equal(t, testResults.Summary, Summary{18, 3, 6, 4, 3, 3, 11})
+	assert.Equal(t, testResults.Summary, Summary{18, 3, 6, 4, 3, 3, 11, 0, nil})
 }
 
 func Test_TestResults_ArrangeSuitesByTestFile(t *testing.T) {
@@ -311,13 +352,20 @@ func Test_Suite_Aggregate(t *testing.T) {
 	suite.Summary.Duration = -100
 	suite.Aggregate()
 	assert.Equal(t, Summary{Total: 5, Passed: 1, Failed: 1, Skipped: 1, Error: 1, Disabled: 1, Duration: 110}, suite.Summary, "should sum up tests duration when there duration is invalid")
+
+	test = NewTest()
+	test.State = StateQuarantined
+	suite.Tests = append(suite.Tests, test)
+	suite.Aggregate()
+
+	assert.Equal(t, Summary{Total: 6, Passed: 1, Failed: 1, Skipped: 1, Error: 1, Disabled: 1, Quarantined: 1, Duration: 110}, suite.Summary)
 }
 
 func Test_Summary_Merge(t *testing.T) {
 	summary1 := Summary{Total: 10, Passed: 6, Failed: 1, Skipped: 1, Error: 1, Disabled: 1, Duration: 10}
 	summary2 := Summary{Total: 20, Passed: 1, Failed: 16, Skipped: 1, Error: 1, Disabled: 1, Duration: 100}
 	summary3 := Summary{Total: 15, Passed: 10, Failed: 2, Skipped: 1, Error: 1, Disabled: 1, Duration: 10}
-	summary4 := Summary{Total: 25, Passed: 2, Failed: 1, Skipped: 20, Error: 1, Disabled: 1, Duration: 105}
+	summary4 := Summary{Total: 25, Passed: 2, Failed: 1, Skipped: 20, Error: 1, Disabled: 1, Duration: 105, Quarantined: 2}
 
 	result := Summary{}
 	for _, s := range []Summary{summary1, summary2, summary3, summary4} {
@@ -325,13 +373,14 @@ func Test_Summary_Merge(t *testing.T) {
 	}
 
 	assert.Equal(t, Summary{
-		Total:    70,
-		Passed:   19,
-		Skipped:  23,
-		Error:    4,
-		Failed:   20,
-		Disabled: 4,
-		Duration: 225,
+		Total:       70,
+		Passed:      19,
+		Skipped:     23,
+		Error:       4,
+		Failed:      20,
+		Disabled:    4,
+		Duration:    225,
+		Quarantined: 2,
 	}, result)
 
 	result = Summary{}
@@ -340,6 +389,13 @@ func Test_Summary_Merge(t *testing.T) {
 
 }
 
+func Test_Summary_PassRate(t *testing.T) {
+	assert.Equal(t, float64(0), (&Summary{}).PassRate())
+	assert.Equal(t, float64(0), (&Summary{Total: 2, Skipped: 1, Disabled: 1}).PassRate())
+	assert.Equal(t, float64(75), (&Summary{Total: 6, Passed: 3, Failed: 1, Skipped: 1, Disabled: 1}).PassRate())
+	assert.Equal(t, float64(75), (&Summary{Total: 7, Passed: 3, Failed: 1, Skipped: 1, Disabled: 1, Quarantined: 1}).PassRate())
+}
+
 func Test_NewTest(t *testing.T) {
 	t.Setenv("IP", "192.168.0.1")
 	t.Setenv("SEMAPHORE_PIPELINE_ID", "1")
@@ -465,3 +521,70 @@ func newSuite(id string, name string) Suite {
 	suite.Name = name
 	return suite
 }
+
+func Test_Summary_Tags(t *testing.T) {
+	suite := newSuite("1", "Suite")
+	for _, test := range []Test{
+		{Name: "a", State: StatePassed, Duration: 10, Tags: []string{"slow", "smoke"}},
+		{Name: "b", State: StateFailed, Duration: 20, Tags: []string{"slow"}},
+		{Name: "c", State: StateSkipped},
+	} {
+		suite.AppendTest(test)
+	}
+
+	assert.Equal(t, map[string]Summary{
+		"slow":  {Total: 2, Passed: 1, Failed: 1, Duration: 30},
+		"smoke": {Total: 1, Passed: 1, Duration: 10},
+	}, suite.Summary.Tags)
+
+	testResults := NewTestResults()
+	testResults.Suites = []Suite{suite, suite}
+	testResults.Aggregate()
+
+	assert.Equal(t, Summary{Total: 4, Passed: 2, Failed: 2, Duration: 60}, testResults.Summary.Tags["slow"])
+	assert.Equal(t, 1, suite.Summary.Tags["slow"].Failed, "merging should not modify suite summaries")
+}
+
+func Test_Result_FilterTags(t *testing.T) {
+	newResult := func() Result {
+		suite := newSuite("1", "Suite")
+		for _, test := range []Test{
+			{Name: "a", State: StatePassed, Tags: []string{"slow", "smoke"}},
+			{Name: "b", State: StateFailed, Tags: []string{"slow"}},
+			{Name: "c", State: StatePassed},
+		} {
+			suite.AppendTest(test)
+		}
+
+		testResults := NewTestResults()
+		testResults.Suites = []Suite{suite}
+		testResults.Aggregate()
+
+		return Result{TestResults: []TestResults{testResults}}
+	}
+
+	names := func(result Result) []string {
+		found := []string{}
+		for _, suite := range result.TestResults[0].Suites {
+			for _, test := range suite.Tests {
+				found = append(found, test.Name)
+			}
+		}
+		return found
+	}
+
+	result := newResult()
+	assert.Equal(t, 2, result.FilterTags([]string{"@Slow"}, []string{"smoke"}))
+	assert.Equal(t, []string{"b"}, names(result))
+	assert.Equal(t, 1, result.TestResults[0].Summary.Total)
+	assert.Equal(t, 1, result.TestResults[0].Summary.Failed)
+
+	result = newResult()
+	assert.Equal(t, 2, result.FilterTags(nil, []string{"slow"}))
+	assert.Equal(t, []string{"c"}, names(result))
+
+	result = newResult()
+	assert.Equal(t, 3, result.FilterTags([]string{"missing"}, nil))
+	assert.Empty(t, result.TestResults[0].Suites, "empty suites should be dropped")
+	assert.Equal(t, Summary{}, result.TestResults[0].Summary)
+}
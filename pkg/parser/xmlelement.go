@@ -34,12 +34,14 @@ func (me *XMLElement) Tag() string {
 func (me *XMLElement) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
 	logger.Trace("Decoding element: %s", start.Name.Local)
 	type alias XMLElement
+
+	// Attributes are set first, so they are kept when decoding of children fails
+	me.Attributes = parseAttributes(start.Attr)
 	if err := d.DecodeElement((*alias)(me), &start); err != nil {
 		logger.Error("Decoding element failed: %v", err)
 		return err
 	}
 
-	me.Attributes = parseAttributes(start.Attr)
 	return nil
 }
 
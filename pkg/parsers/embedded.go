@@ -27,7 +27,7 @@ func (e Embedded) IsApplicable(string) bool {
 }
 
 // Parse parses the string given using the embedded format
-func (e Embedded) Parse(path string) parser.TestResults {
+func (e Embedded) Parse(path string) (parser.TestResults, error) {
 	results := parser.NewTestResults()
 	results.Name = "Suite"
 	results.Framework = e.GetName()
@@ -35,11 +35,10 @@ func (e Embedded) Parse(path string) parser.TestResults {
 
 	xmlElement, err := LoadXML(path)
 
-	if err != nil {
+	if xmlElement == nil {
 		logger.Error("Loading XML failed: %v", err)
-		results.Status = parser.StatusError
-		results.StatusMessage = err.Error()
-		return results
+		results.Fail(err)
+		return results, err
 	}
 
 	flatten(xmlElement)
@@ -51,16 +50,19 @@ func (e Embedded) Parse(path string) parser.TestResults {
 	case "testsuite":
 		tag := xmlElement.Tag()
 		logger.Debug("<testsuite> as root element not supported")
-		results.Status = parser.StatusError
-		results.StatusMessage = fmt.Sprintf("Invalid root element found: <%s>, must be <testsuites>", tag)
+		err = unsupportedRoot(path, fmt.Sprintf("Invalid root element found: <%s>, must be <testsuites>", tag))
 	default:
 		tag := xmlElement.Tag()
 		logger.Debug("Invalid root element found: <%s>", tag)
-		results.Status = parser.StatusError
-		results.StatusMessage = fmt.Sprintf("Invalid root element found: <%s>,  must be <testsuites>", tag)
+		err = unsupportedRoot(path, fmt.Sprintf("Invalid root element found: <%s>,  must be <testsuites>", tag))
 	}
 
-	return results
+	if err != nil {
+		logger.Error("Parsing %s failed: %v", path, err)
+		results.Fail(err)
+	}
+
+	return results, err
 }
 
 func flatten(root *parser.XMLElement) {
@@ -211,9 +213,12 @@ func (e Embedded) newTest(xml parser.XMLElement, suite parser.Suite) parser.Test
 			test.SystemOut = string(node.Contents)
 		case "system-err":
 			test.SystemErr = string(node.Contents)
+		case "properties":
+			test.Properties = parser.ParseProperties(node)
 		}
 	}
 
+	test.Tags = parser.ParseTags(test.Name, test.Properties)
 	test.EnsureID(suite)
 
 	return test
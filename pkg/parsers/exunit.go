@@ -27,7 +27,7 @@ func (me ExUnit) IsApplicable(path string) bool {
 	xmlElement, err := LoadXML(path)
 	logger.Debug("Checking applicability of %s parser", me.GetName())
 
-	if err != nil {
+	if xmlElement == nil {
 		logger.Error("Loading XML failed: %v", err)
 		return false
 	}
@@ -65,16 +65,15 @@ func (me ExUnit) IsApplicable(path string) bool {
 }
 
 // Parse ...
-func (me ExUnit) Parse(path string) parser.TestResults {
+func (me ExUnit) Parse(path string) (parser.TestResults, error) {
 	results := parser.NewTestResults()
 
 	xmlElement, err := LoadXML(path)
 
-	if err != nil {
+	if xmlElement == nil {
 		logger.Error("Loading XML failed: %v", err)
-		results.Status = parser.StatusError
-		results.StatusMessage = err.Error()
-		return results
+		results.Fail(err)
+		return results, err
 	}
 
 	switch xmlElement.Tag() {
@@ -91,13 +90,17 @@ func (me ExUnit) Parse(path string) parser.TestResults {
 	default:
 		tag := xmlElement.Tag()
 		logger.Debug("Invalid root element found: <%s>", tag)
-		results.Status = parser.StatusError
-		results.StatusMessage = fmt.Sprintf("Invalid root element found: <%s>, must be one of <testsuites>, <testsuite>", tag)
+		err = unsupportedRoot(path, fmt.Sprintf("Invalid root element found: <%s>, must be one of <testsuites>, <testsuite>", tag))
 	}
 
 	results.Aggregate()
 
-	return results
+	if err != nil {
+		logger.Error("Parsing %s failed: %v", path, err)
+		results.Fail(err)
+	}
+
+	return results, err
 }
 
 func (me ExUnit) newTestResults(xml parser.XMLElement) parser.TestResults {
@@ -216,9 +219,12 @@ func (me ExUnit) newTest(xml parser.XMLElement, suite parser.Suite) parser.Test
 			test.SystemOut = string(node.Contents)
 		case "system-err":
 			test.SystemErr = string(node.Contents)
+		case "properties":
+			test.Properties = parser.ParseProperties(node)
 		}
 	}
 
+	test.Tags = parser.ParseTags(test.Name, test.Properties)
 	test.EnsureID(suite)
 
 	return test
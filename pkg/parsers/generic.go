@@ -29,16 +29,15 @@ func (me Generic) GetName() string {
 }
 
 // Parse ...
-func (me Generic) Parse(path string) parser.TestResults {
+func (me Generic) Parse(path string) (parser.TestResults, error) {
 	results := parser.NewTestResults()
 
 	xmlElement, err := LoadXML(path)
 
-	if err != nil {
+	if xmlElement == nil {
 		logger.Error("Loading XML failed: %v", err)
-		results.Status = parser.StatusError
-		results.StatusMessage = err.Error()
-		return results
+		results.Fail(err)
+		return results, err
 	}
 
 	switch xmlElement.Tag() {
@@ -53,15 +52,20 @@ func (me Generic) Parse(path string) parser.TestResults {
 	default:
 		tag := xmlElement.Tag()
 		logger.Debug("Invalid root element found: <%s>", tag)
-		results.Status = parser.StatusError
-		results.StatusMessage = fmt.Sprintf("Invalid root element found: <%s>, must be one of <testsuites>, <testsuite>", tag)
-		return results
+		err = unsupportedRoot(path, fmt.Sprintf("Invalid root element found: <%s>, must be one of <testsuites>, <testsuite>", tag))
+		results.Fail(err)
+		return results, err
 	}
 
 	results.Aggregate()
 	results.Status = parser.StatusSuccess
 
-	return results
+	if err != nil {
+		logger.Error("Parsing %s failed: %v", path, err)
+		results.Fail(err)
+	}
+
+	return results, err
 }
 
 func (me Generic) newTestResults(xml parser.XMLElement) parser.TestResults {
@@ -181,9 +185,12 @@ func (me Generic) newTest(xml parser.XMLElement, suite parser.Suite) parser.Test
 			test.SystemOut = string(node.Contents)
 		case "system-err":
 			test.SystemErr = string(node.Contents)
+		case "properties":
+			test.Properties = parser.ParseProperties(node)
 		}
 	}
 
+	test.Tags = parser.ParseTags(test.Name, test.Properties)
 	test.EnsureID(suite)
 
 	return test
@@ -2,6 +2,7 @@ package parsers
 
 import (
 	"fmt"
+	"regexp"
 	"strings"
 
 	"github.com/semaphoreci/test-results/pkg/logger"
@@ -27,7 +28,7 @@ func (me GoLang) IsApplicable(path string) bool {
 	xmlElement, err := LoadXML(path)
 	logger.Debug("Checking applicability of %s parser", me.GetName())
 
-	if err != nil {
+	if xmlElement == nil {
 		logger.Error("Loading XML failed: %v", err)
 		return false
 	}
@@ -66,16 +67,15 @@ func hasProperty(testsuiteElement parser.XMLElement, property string) bool {
 }
 
 // Parse ...
-func (me GoLang) Parse(path string) parser.TestResults {
+func (me GoLang) Parse(path string) (parser.TestResults, error) {
 	results := parser.NewTestResults()
 
 	xmlElement, err := LoadXML(path)
 
-	if err != nil {
+	if xmlElement == nil {
 		logger.Error("Loading XML failed: %v", err)
-		results.Status = parser.StatusError
-		results.StatusMessage = err.Error()
-		return results
+		results.Fail(err)
+		return results, err
 	}
 
 	switch xmlElement.Tag() {
@@ -91,13 +91,17 @@ func (me GoLang) Parse(path string) parser.TestResults {
 	default:
 		tag := xmlElement.Tag()
 		logger.Debug("Invalid root element found: <%s>", tag)
-		results.Status = parser.StatusError
-		results.StatusMessage = fmt.Sprintf("Invalid root element found: <%s>, must be one of <testsuites>, <testsuite>", tag)
+		err = unsupportedRoot(path, fmt.Sprintf("Invalid root element found: <%s>, must be one of <testsuites>, <testsuite>", tag))
 	}
 
 	results.Aggregate()
 
-	return results
+	if err != nil {
+		logger.Error("Parsing %s failed: %v", path, err)
+		results.Fail(err)
+	}
+
+	return results, err
 }
 
 func (me GoLang) newTestResults(xml parser.XMLElement) parser.TestResults {
@@ -216,10 +220,28 @@ func (me GoLang) newTest(xml parser.XMLElement, suite parser.Suite) parser.Test
 			test.SystemOut = string(node.Contents)
 		case "system-err":
 			test.SystemErr = string(node.Contents)
+		case "properties":
+			test.Properties = parser.ParseProperties(node)
 		}
 	}
 
+	test.Tags = parser.NormalizeTags(append(parser.ParseTags(test.Name, test.Properties), subtestTags(test.Name)...))
 	test.EnsureID(suite)
 
 	return test
 }
+
+var subtestTagRegexp = regexp.MustCompile(`\[([\w:.-]+)\]`)
+
+// subtestTags returns `[tag]` markers from subtest names, e.g. `TestLogin/[slow]_with_expired_token`
+func subtestTags(name string) []string {
+	tags := []string{}
+	segments := strings.Split(name, "/")
+	for _, segment := range segments[1:] {
+		for _, match := range subtestTagRegexp.FindAllStringSubmatch(segment, -1) {
+			tags = append(tags, match[1])
+		}
+	}
+
+	return tags
+}
@@ -2,7 +2,7 @@ package parsers
 
 import (
 	"bytes"
-	"io/ioutil"
+	"errors"
 
 	"github.com/semaphoreci/test-results/pkg/fileloader"
 	"github.com/semaphoreci/test-results/pkg/parser"
@@ -10,36 +10,38 @@ import (
 
 // LoadPath ...
 func LoadPath(path string) (*bytes.Reader, error) {
-	var reader *bytes.Reader
-	// Preload path with loader. If nothing is found in file cache - load it up from path.
-	reader, found := fileloader.Load(path, &bytes.Reader{})
-
-	if !found {
-		file, err := ioutil.ReadFile(path) // #nosec
-
-		if err != nil {
-			return nil, err
-		}
-
-		b := bytes.NewReader(file)
-		reader, _ = fileloader.Load(path, b)
+	data, err := fileloader.ReadFile(path)
+	if err != nil {
+		return nil, err
 	}
-	return reader, nil
+
+	return bytes.NewReader(data), nil
 }
 
-// LoadXML ...
+// LoadXML loads and parses XML document at path. Errors are returned as *parser.FileError.
+// When document is broken after root element and some of its children, e.g. it was truncated,
+// parsed elements are returned together with ErrorPartial error.
 func LoadXML(path string) (*parser.XMLElement, error) {
 	reader, err := LoadPath(path)
 	if err != nil {
-		return nil, err
+		return nil, parser.NewFileError(parser.ErrorIO, path, err)
 	}
 
 	xmlElement := parser.NewXMLElement()
 
 	err = xmlElement.Parse(reader)
 	if err != nil {
-		return nil, err
+		if xmlElement.Tag() != "" && len(xmlElement.Children) > 0 {
+			return &xmlElement, parser.NewFileError(parser.ErrorPartial, path, err)
+		}
+
+		return nil, parser.NewFileError(parser.ErrorInvalidXML, path, err)
 	}
 
 	return &xmlElement, nil
 }
+
+// unsupportedRoot returns error of root element not supported by parser
+func unsupportedRoot(path, message string) error {
+	return parser.NewFileError(parser.ErrorUnsupportedRoot, path, errors.New(message))
+}
@@ -40,3 +40,13 @@ func FindParser(name string, path string) (parser.Parser, error) {
 
 	return nil, fmt.Errorf("no applicable parsers found")
 }
+
+// Names returns names of available parsers
+func Names() []string {
+	names := []string{}
+	for _, p := range availableParsers {
+		names = append(names, p.GetName())
+	}
+
+	return names
+}
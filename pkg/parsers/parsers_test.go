@@ -118,7 +118,10 @@ func runParserTests(t *testing.T, parser parser.Parser, testCases []parserTestCa
 	for _, tc := range testCases {
 		xml := bytes.NewReader([]byte(tc.Input))
 		path := fileloader.Ensure(xml)
-		got := parser.Parse(path)
+		got, err := parser.Parse(path)
+		if (err != nil) != (tc.want.Status == "error") {
+			t.Errorf("%s parsing returned unexpected error for \"%s\" case: %v", parser.GetName(), tc.Name, err)
+		}
 
 		if diff := cmp.Diff(tc.want, got); diff != "" {
 			t.Errorf("%s parsing failed for \"%s\" case:\n%s", parser.GetName(), tc.Name, diff)
@@ -126,3 +129,124 @@ func runParserTests(t *testing.T, parser parser.Parser, testCases []parserTestCa
 		}
 	}
 }
+
+func Test_Parsers_Tags(t *testing.T) {
+	for _, tc := range []struct {
+		parser     parser.Parser
+		input      string
+		tags       []string
+		properties parser.Properties
+	}{
+		{
+			parser: NewGeneric(),
+			input: `
+				<testsuite name="LoginTest">
+					<testcase name="logs in" classname="LoginTest">
+						<properties>
+							<property name="tags" value="Smoke, slow"/>
+							<property name="browser">firefox</property>
+						</properties>
+					</testcase>
+				</testsuite>`,
+			tags:       []string{"slow", "smoke"},
+			properties: parser.Properties{"tags": "Smoke, slow", "browser": "firefox"},
+		},
+		{
+			parser:     NewGeneric(),
+			input:      `<testsuite name="Login"><testcase name="Scenario: user logs in @smoke @wip" classname="user@example.com"/></testsuite>`,
+			tags:       []string{"smoke", "wip"},
+			properties: nil,
+		},
+		{
+			parser: NewRSpec(),
+			input: `
+				<testsuite name="rspec">
+					<testcase name="User signs in" file="./spec/user_spec.rb">
+						<properties>
+							<property name="slow" value="true"/>
+							<property name="type" value="feature"/>
+							<property name="retried" value="true"/>
+						</properties>
+					</testcase>
+				</testsuite>`,
+			tags:       []string{"slow"},
+			properties: parser.Properties{"slow": "true", "type": "feature", "retried": "true"},
+		},
+		{
+			parser: NewRSpec().WithTagMetadata("integration", "db"),
+			input: `
+				<testsuite name="rspec">
+					<testcase name="Order syncs" file="./spec/order_spec.rb">
+						<properties>
+							<property name="integration" value="true"/>
+							<property name="db" value="false"/>
+							<property name="smoke" value="true"/>
+							<property name="retried" value="true"/>
+						</properties>
+					</testcase>
+				</testsuite>`,
+			tags:       []string{"integration", "smoke"},
+			properties: parser.Properties{"integration": "true", "db": "false", "smoke": "true", "retried": "true"},
+		},
+		{
+			parser:     NewGoLang(),
+			input:      `<testsuite name="pkg/auth"><testcase name="TestLogin/[slow]_expired_token" classname="pkg/auth"/></testsuite>`,
+			tags:       []string{"slow"},
+			properties: nil,
+		},
+	} {
+		path := fileloader.Ensure(bytes.NewReader([]byte(tc.input)))
+		results, err := tc.parser.Parse(path)
+		if err != nil {
+			t.Fatalf("%s: parsing failed: %v", tc.parser.GetName(), err)
+		}
+		test := results.Suites[0].Tests[0]
+
+		if diff := cmp.Diff(tc.tags, test.Tags); diff != "" {
+			t.Errorf("%s: tags mismatch (-want +got):\n%s", tc.parser.GetName(), diff)
+		}
+
+		if diff := cmp.Diff(tc.properties, test.Properties); diff != "" {
+			t.Errorf("%s: properties mismatch (-want +got):\n%s", tc.parser.GetName(), diff)
+		}
+	}
+}
+
+func Test_Parsers_Errors(t *testing.T) {
+	truncated := `<?xml version="1.0"?>
+		<testsuites>
+			<testsuite name="first"><testcase name="passes" classname="first"/></testsuite>
+			<testsuite name="second"><testcase name="is cut`
+
+	for _, tc := range []struct {
+		name   string
+		input  string
+		kind   parser.ErrorKind
+		suites int
+	}{
+		{name: "empty", input: "", kind: parser.ErrorInvalidXML},
+		{name: "not xml", input: "not xml at all", kind: parser.ErrorInvalidXML},
+		{name: "unsupported root", input: `<html><body></body></html>`, kind: parser.ErrorUnsupportedRoot},
+		{name: "truncated", input: truncated, kind: parser.ErrorPartial, suites: 1},
+	} {
+		for _, p := range []parser.Parser{NewGeneric(), NewRSpec(), NewExUnit(), NewMocha(), NewGoLang(), NewPHPUnit(), NewEmbedded()} {
+			path := fileloader.Ensure(bytes.NewReader([]byte(tc.input)))
+			results, err := p.Parse(path)
+
+			if got := parser.ErrorKindOf(err); got != tc.kind {
+				t.Errorf("%s: %s: expected %q error, got %q: %v", p.GetName(), tc.name, tc.kind, got, err)
+			}
+			if results.Status != parser.StatusError {
+				t.Errorf("%s: %s: expected error status, got %q", p.GetName(), tc.name, results.Status)
+			}
+			if len(results.Suites) < tc.suites {
+				t.Errorf("%s: %s: expected at least %d parsed suite(s), got %d", p.GetName(), tc.name, tc.suites, len(results.Suites))
+			}
+		}
+	}
+
+	_, err := NewGeneric().Parse("/non/existing/report.xml")
+	if got := parser.ErrorKindOf(err); got != parser.ErrorIO {
+		t.Errorf("expected %q error for missing file, got %q: %v", parser.ErrorIO, got, err)
+	}
+}
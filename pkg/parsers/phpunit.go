@@ -28,7 +28,7 @@ func (me PHPUnit) IsApplicable(path string) bool {
 }
 
 // Parse ...
-func (me PHPUnit) Parse(path string) parser.TestResults {
+func (me PHPUnit) Parse(path string) (parser.TestResults, error) {
 	results := parser.NewTestResults()
 	results.Name = "PHPUnit Suite"
 	results.Framework = me.GetName()
@@ -36,11 +36,10 @@ func (me PHPUnit) Parse(path string) parser.TestResults {
 
 	xmlElement, err := LoadXML(path)
 
-	if err != nil {
+	if xmlElement == nil {
 		logger.Error("Loading XML failed: %v", err)
-		results.Status = parser.StatusError
-		results.StatusMessage = err.Error()
-		return results
+		results.Fail(err)
+		return results, err
 	}
 
 	flattenTestSuites(xmlElement)
@@ -55,13 +54,17 @@ func (me PHPUnit) Parse(path string) parser.TestResults {
 	default:
 		tag := xmlElement.Tag()
 		logger.Debug("Invalid root element found: <%s>", tag)
-		results.Status = parser.StatusError
-		results.StatusMessage = fmt.Sprintf("Invalid root element found: <%s>, must be one of <testsuites>, <testsuite>", tag)
+		err = unsupportedRoot(path, fmt.Sprintf("Invalid root element found: <%s>, must be one of <testsuites>, <testsuite>", tag))
 	}
 
 	results.Aggregate()
 
-	return results
+	if err != nil {
+		logger.Error("Parsing %s failed: %v", path, err)
+		results.Fail(err)
+	}
+
+	return results, err
 }
 
 func flattenTestSuites(xmlElement *parser.XMLElement) {
@@ -223,9 +226,12 @@ func (me PHPUnit) newTest(xml parser.XMLElement, suite parser.Suite) parser.Test
 			test.SystemOut = string(node.Contents)
 		case "system-err":
 			test.SystemErr = string(node.Contents)
+		case "properties":
+			test.Properties = parser.ParseProperties(node)
 		}
 	}
 
+	test.Tags = parser.ParseTags(test.Name, test.Properties)
 	test.EnsureID(suite)
 
 	return test
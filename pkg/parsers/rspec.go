@@ -10,6 +10,7 @@ import (
 
 // RSpec ...
 type RSpec struct {
+	tagMetadata []string
 }
 
 // NewRSpec ...
@@ -17,6 +18,12 @@ func NewRSpec() RSpec {
 	return RSpec{}
 }
 
+// WithTagMetadata returns parser which also takes given boolean metadata as tags, in addition to RSpecTagMetadata
+func (me RSpec) WithTagMetadata(keys ...string) RSpec {
+	me.tagMetadata = append(append([]string{}, me.tagMetadata...), keys...)
+	return me
+}
+
 // GetName ...
 func (me RSpec) GetName() string {
 	return "rspec"
@@ -27,7 +34,7 @@ func (me RSpec) IsApplicable(path string) bool {
 	xmlElement, err := LoadXML(path)
 	logger.Debug("Checking applicability of %s parser", me.GetName())
 
-	if err != nil {
+	if xmlElement == nil {
 		logger.Error("Loading XML failed: %v", err)
 		return false
 	}
@@ -47,7 +54,7 @@ func (me RSpec) IsApplicable(path string) bool {
 }
 
 // Parse ...
-func (me RSpec) Parse(path string) parser.TestResults {
+func (me RSpec) Parse(path string) (parser.TestResults, error) {
 	results := parser.NewTestResults()
 	results.Name = strings.Title(me.GetName() + " suite")
 	results.Framework = me.GetName()
@@ -55,12 +62,11 @@ func (me RSpec) Parse(path string) parser.TestResults {
 
 	xmlElement, err := LoadXML(path)
 
-	if err != nil {
+	if xmlElement == nil {
 		logger.Error("Loading XML failed: %v", err)
-		results.Status = parser.StatusError
-		results.StatusMessage = err.Error()
+		results.Fail(err)
 		results.Framework = me.GetName()
-		return results
+		return results, err
 	}
 
 	switch xmlElement.Tag() {
@@ -73,14 +79,18 @@ func (me RSpec) Parse(path string) parser.TestResults {
 	default:
 		tag := xmlElement.Tag()
 		logger.Debug("Invalid root element found: <%s>", tag)
-		results.Status = parser.StatusError
-		results.StatusMessage = fmt.Sprintf("Invalid root element found: <%s>, must be one of <testsuites>, <testsuite>", tag)
+		err = unsupportedRoot(path, fmt.Sprintf("Invalid root element found: <%s>, must be one of <testsuites>, <testsuite>", tag))
 	}
 
 	results.ArrangeSuitesByTestFile()
 	results.Aggregate()
 
-	return results
+	if err != nil {
+		logger.Error("Parsing %s failed: %v", path, err)
+		results.Fail(err)
+	}
+
+	return results, err
 }
 
 func (me RSpec) newTestResults(xml parser.XMLElement) parser.TestResults {
@@ -201,10 +211,29 @@ func (me RSpec) newTest(xml parser.XMLElement, suite parser.Suite) parser.Test {
 			test.SystemOut = string(node.Contents)
 		case "system-err":
 			test.SystemErr = string(node.Contents)
+		case "properties":
+			test.Properties = parser.ParseProperties(node)
 		}
 	}
 
+	test.Tags = parser.NormalizeTags(append(parser.ParseTags(test.Name, test.Properties), me.metadataTags(test.Properties)...))
 	test.EnsureID(suite)
 
 	return test
 }
+
+// RSpecTagMetadata lists boolean RSpec metadata used to filter examples by default, e.g. `it "...", :slow`.
+// Other boolean properties describe the example or the CI run and are not tags, unless added with WithTagMetadata.
+var RSpecTagMetadata = []string{"focus", "slow", "smoke", "flaky", "wip", "js"}
+
+// metadataTags returns tag metadata of the example which is set to true
+func (me RSpec) metadataTags(properties parser.Properties) []string {
+	tags := []string{}
+	for _, key := range append(append([]string{}, RSpecTagMetadata...), me.tagMetadata...) {
+		if properties[key] == "true" {
+			tags = append(tags, key)
+		}
+	}
+
+	return tags
+}
@@ -0,0 +1,231 @@
+package quarantine
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/semaphoreci/test-results/pkg/glob"
+	"github.com/semaphoreci/test-results/pkg/parser"
+	"gopkg.in/yaml.v3"
+)
+
+// Entry is a single quarantined test or a group of tests matched by name and classname patterns
+type Entry struct {
+	ID        string `yaml:"id" json:"id,omitempty"`
+	Name      string `yaml:"name" json:"name,omitempty"`
+	Classname string `yaml:"classname" json:"classname,omitempty"`
+	Owner     string `yaml:"owner" json:"owner"`
+	Expires   string `yaml:"expires" json:"expires"`
+	Reason    string `yaml:"reason" json:"reason,omitempty"`
+
+	expiresAt time.Time
+	name      *regexp.Regexp
+	classname *regexp.Regexp
+}
+
+// List ...
+type List struct {
+	Tests []*Entry `yaml:"tests"`
+}
+
+// Match is a test failure downgraded by quarantine entry
+type Match struct {
+	Entry *Entry
+	Suite string
+	Test  string
+}
+
+// Load reads quarantine list from YAML file
+func Load(path string) (*List, error) {
+	data, err := os.ReadFile(filepath.Clean(path))
+	if err != nil {
+		return nil, err
+	}
+
+	list, err := Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+
+	return list, nil
+}
+
+// Parse parses and validates quarantine list
+//
+//	tests:
+//	  - id: 0f6a6e4e-...
+//	    owner: team-payments
+//	    expires: 2024-12-31
+//	  - name: "*times out*"
+//	    classname: "Payments::*"
+//	    owner: team-payments
+//	    expires: 2024-12-31
+func Parse(data []byte) (*List, error) {
+	list := &List{}
+	if err := yaml.Unmarshal(data, list); err != nil {
+		return nil, err
+	}
+
+	for i, entry := range list.Tests {
+		if entry == nil {
+			return nil, fmt.Errorf("entry #%d is empty", i+1)
+		}
+
+		if err := entry.compile(); err != nil {
+			return nil, fmt.Errorf("entry #%d: %v", i+1, err)
+		}
+	}
+
+	return list, nil
+}
+
+func (e *Entry) compile() error {
+	if e.ID == "" && e.Name == "" && e.Classname == "" {
+		return fmt.Errorf("id, name or classname is required")
+	}
+
+	if e.Owner == "" {
+		return fmt.Errorf("owner is required")
+	}
+
+	if e.Expires == "" {
+		return fmt.Errorf("expires is required")
+	}
+
+	expiresAt, err := parseDate(e.Expires)
+	if err != nil {
+		return err
+	}
+	e.expiresAt = expiresAt
+
+	if e.Name != "" {
+		e.name = glob.Name(e.Name)
+	}
+
+	if e.Classname != "" {
+		e.classname = glob.Name(e.Classname)
+	}
+
+	return nil
+}
+
+func parseDate(s string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+
+	t, err := time.Parse("2006-01-02", s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid expiry date %q, expected YYYY-MM-DD or RFC3339", s)
+	}
+
+	// Date-only entries are valid until the end of the day
+	return t.Add(24*time.Hour - time.Nanosecond), nil
+}
+
+// Expired returns true when entry is no longer valid at `now`
+func (e *Entry) Expired(now time.Time) bool {
+	return now.After(e.expiresAt)
+}
+
+// Matches returns true when test is covered by the entry
+func (e *Entry) Matches(test parser.Test) bool {
+	if e.ID != "" && e.ID != test.ID {
+		return false
+	}
+
+	if e.name != nil && !e.name.MatchString(test.Name) {
+		return false
+	}
+
+	if e.classname != nil && !e.classname.MatchString(test.Classname) {
+		return false
+	}
+
+	return true
+}
+
+// String describes entry for log messages
+func (e *Entry) String() string {
+	parts := []string{}
+	if e.ID != "" {
+		parts = append(parts, "id="+e.ID)
+	}
+	if e.Name != "" {
+		parts = append(parts, "name="+e.Name)
+	}
+	if e.Classname != "" {
+		parts = append(parts, "classname="+e.Classname)
+	}
+
+	return fmt.Sprintf("%s (owner: %s, expires: %s)", strings.Join(parts, " "), e.Owner, e.Expires)
+}
+
+// Expired returns entries that are no longer valid at `now`
+func (l *List) Expired(now time.Time) []*Entry {
+	expired := []*Entry{}
+	for _, entry := range l.Tests {
+		if entry.Expired(now) {
+			expired = append(expired, entry)
+		}
+	}
+
+	return expired
+}
+
+// Apply marks failed and errored tests covered by active entries as quarantined.
+// Failure and error details are kept and summaries are re-aggregated.
+func (l *List) Apply(result *parser.Result, now time.Time) []Match {
+	matches := []Match{}
+
+	for i := range result.TestResults {
+		testResults := &result.TestResults[i]
+		changed := false
+
+		for j := range testResults.Suites {
+			suite := &testResults.Suites[j]
+			suiteChanged := false
+
+			for k := range suite.Tests {
+				test := &suite.Tests[k]
+				if test.State != parser.StateFailed && test.State != parser.StateError {
+					continue
+				}
+
+				entry := l.find(*test, now)
+				if entry == nil {
+					continue
+				}
+
+				test.State = parser.StateQuarantined
+				matches = append(matches, Match{Entry: entry, Suite: suite.Name, Test: test.Name})
+				suiteChanged = true
+			}
+
+			if suiteChanged {
+				suite.Aggregate()
+				changed = true
+			}
+		}
+
+		if changed {
+			testResults.Aggregate()
+		}
+	}
+
+	return matches
+}
+
+func (l *List) find(test parser.Test, now time.Time) *Entry {
+	for _, entry := range l.Tests {
+		if !entry.Expired(now) && entry.Matches(test) {
+			return entry
+		}
+	}
+
+	return nil
+}
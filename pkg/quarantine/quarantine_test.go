@@ -0,0 +1,119 @@
+package quarantine_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/semaphoreci/test-results/pkg/parser"
+	"github.com/semaphoreci/test-results/pkg/quarantine"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var now = time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+
+func newResult() parser.Result {
+	testResults := parser.NewTestResults()
+	testResults.Name = "Unit"
+	testResults.EnsureID()
+
+	suite := parser.NewSuite()
+	suite.Name = "Payments"
+	suite.EnsureID(testResults)
+
+	for _, tc := range []struct {
+		name      string
+		classname string
+		state     parser.State
+	}{
+		{"charges card", "Payments::Charge", parser.StateFailed},
+		{"refunds card", "Payments::Refund", parser.StateError},
+		{"times out sometimes", "Payments::Webhook", parser.StateFailed},
+		{"passes", "Payments::Charge", parser.StatePassed},
+	} {
+		test := parser.NewTest()
+		test.Name = tc.name
+		test.Classname = tc.classname
+		test.State = tc.state
+		if tc.state == parser.StateFailed {
+			failure := parser.NewFailure()
+			failure.Message = "boom"
+			test.Failure = &failure
+		}
+		test.EnsureID(suite)
+		suite.AppendTest(test)
+	}
+
+	suite.Aggregate()
+	testResults.Suites = append(testResults.Suites, suite)
+	testResults.Aggregate()
+
+	return parser.Result{TestResults: []parser.TestResults{testResults}}
+}
+
+func Test_Apply(t *testing.T) {
+	result := newResult()
+	id := result.TestResults[0].Suites[0].Tests[1].ID
+
+	list, err := quarantine.Parse([]byte(`
+tests:
+  - id: ` + id + `
+    owner: team-a
+    expires: 2024-06-01
+  - name: "*times out*"
+    classname: Payments::*
+    owner: team-b
+    expires: 2024-12-31T00:00:00Z
+  - classname: Payments::Charge
+    owner: team-c
+    expires: 2024-05-31
+`))
+	require.NoError(t, err)
+
+	matches := list.Apply(&result, now)
+	require.Len(t, matches, 2)
+	assert.Equal(t, "team-a", matches[0].Entry.Owner)
+	assert.Equal(t, "refunds card", matches[0].Test)
+	assert.Equal(t, "team-b", matches[1].Entry.Owner)
+
+	tests := result.TestResults[0].Suites[0].Tests
+	assert.Equal(t, parser.StateFailed, tests[0].State, "expired entries should not be applied")
+	assert.Equal(t, parser.StateQuarantined, tests[1].State)
+	assert.Equal(t, parser.StateQuarantined, tests[2].State)
+	assert.Equal(t, "boom", tests[2].Failure.Message, "failure should be kept")
+	assert.Equal(t, parser.StatePassed, tests[3].State)
+
+	assert.Equal(t, parser.Summary{Total: 4, Passed: 1, Failed: 1, Quarantined: 2}, result.TestResults[0].Suites[0].Summary)
+	assert.Equal(t, parser.Summary{Total: 4, Passed: 1, Failed: 1, Quarantined: 2}, result.TestResults[0].Summary)
+
+	expired := list.Expired(now)
+	require.Len(t, expired, 1)
+	assert.Equal(t, "classname=Payments::Charge (owner: team-c, expires: 2024-05-31)", expired[0].String())
+}
+
+func Test_Parse_Invalid(t *testing.T) {
+	for name, data := range map[string]string{
+		"missing matcher": "tests:\n  - owner: a\n    expires: 2024-01-01\n",
+		"missing owner":   "tests:\n  - id: a\n    expires: 2024-01-01\n",
+		"missing expires": "tests:\n  - id: a\n    owner: a\n",
+		"invalid date":    "tests:\n  - id: a\n    owner: a\n    expires: tomorrow\n",
+		"invalid yaml":    "tests: [",
+	} {
+		_, err := quarantine.Parse([]byte(data))
+		assert.Error(t, err, name)
+	}
+}
+
+func Test_Load(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "quarantine.yml")
+	require.NoError(t, os.WriteFile(path, []byte("tests:\n  - id: a\n    owner: a\n    expires: 2024-01-01\n"), 0600))
+
+	list, err := quarantine.Load(path)
+	require.NoError(t, err)
+	assert.Len(t, list.Tests, 1)
+
+	_, err = quarantine.Load(filepath.Join(t.TempDir(), "missing.yml"))
+	assert.Error(t, err)
+}
@@ -0,0 +1,342 @@
+package query
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/semaphoreci/test-results/pkg/parser"
+	"github.com/semaphoreci/test-results/pkg/report"
+)
+
+// Query is a list of conditions that all have to match a test
+type Query struct {
+	conditions []condition
+}
+
+type condition struct {
+	field    string
+	op       string
+	values   []string
+	regexp   *regexp.Regexp
+	duration time.Duration
+}
+
+var operators = []string{"!=", "!~", ">=", "<=", "=", "~", ">", "<"}
+
+var fields = map[string]string{
+	"state":     "=, !=",
+	"name":      "=, !=, ~, !~",
+	"classname": "=, !=, ~, !~",
+	"file":      "=, !=, ~, !~",
+	"suite":     "=, !=, ~, !~",
+	"package":   "=, !=, ~, !~",
+	"framework": "=, !=, ~, !~",
+	"duration":  "=, !=, >, >=, <, <=",
+	"tag":       "=, !=",
+}
+
+// Parse parses filter expression.
+//
+// Expression is a whitespace separated list of `field<op>value` conditions, all of which have to match.
+// Fields are state, name, classname, file, suite, package, framework, duration, tag and prop.<name> for test or suite properties.
+// Operators are `=` and `!=` (comma separated list of accepted values), `~` and `!~` (regexp),
+// and `>`, `>=`, `<`, `<=` for durations. Values containing whitespace can be double quoted.
+//
+//	state=failed,error duration>1s name~"^User (signs|logs) in"
+func Parse(expression string) (*Query, error) {
+	terms, err := split(expression)
+	if err != nil {
+		return nil, err
+	}
+
+	query := &Query{}
+	for _, term := range terms {
+		if strings.EqualFold(term, "and") {
+			continue
+		}
+
+		c, err := parseCondition(term)
+		if err != nil {
+			return nil, err
+		}
+		query.conditions = append(query.conditions, c)
+	}
+
+	return query, nil
+}
+
+func split(expression string) ([]string, error) {
+	terms := []string{}
+	var current strings.Builder
+	quoted := false
+
+	for _, r := range expression {
+		switch {
+		case r == '"':
+			quoted = !quoted
+		case !quoted && (r == ' ' || r == '\t' || r == '\n'):
+			if current.Len() > 0 {
+				terms = append(terms, current.String())
+				current.Reset()
+			}
+		default:
+			current.WriteRune(r)
+		}
+	}
+
+	if quoted {
+		return nil, fmt.Errorf("unterminated quote in %q", expression)
+	}
+
+	if current.Len() > 0 {
+		terms = append(terms, current.String())
+	}
+
+	return terms, nil
+}
+
+func parseCondition(term string) (condition, error) {
+	index := strings.IndexAny(term, "!=~<>")
+	if index <= 0 {
+		return condition{}, fmt.Errorf("invalid condition %q, expected <field><operator><value>", term)
+	}
+
+	c := condition{field: strings.ToLower(term[:index])}
+	rest := term[index:]
+	for _, op := range operators {
+		if strings.HasPrefix(rest, op) {
+			c.op = op
+			break
+		}
+	}
+
+	if c.op == "" {
+		return c, fmt.Errorf("invalid operator in condition %q", term)
+	}
+
+	value := rest[len(c.op):]
+
+	allowed, found := fields[c.field]
+	if strings.HasPrefix(c.field, "prop.") && len(c.field) > len("prop.") {
+		// Property names are case sensitive
+		c.field = "prop." + term[len("prop."):index]
+		allowed, found = fields["name"], true
+	}
+
+	if !found {
+		return c, fmt.Errorf("unknown field %q in condition %q", c.field, term)
+	}
+
+	if !strings.Contains(", "+allowed+",", " "+c.op+",") {
+		return c, fmt.Errorf("operator %s is not supported for field %s, use one of: %s", c.op, c.field, allowed)
+	}
+
+	switch {
+	case c.field == "duration":
+		duration, err := time.ParseDuration(value)
+		if err != nil {
+			return c, fmt.Errorf("invalid duration in condition %q: %v", term, err)
+		}
+		c.duration = duration
+	case c.op == "~" || c.op == "!~":
+		re, err := regexp.Compile(value)
+		if err != nil {
+			return c, fmt.Errorf("invalid regexp in condition %q: %v", term, err)
+		}
+		c.regexp = re
+	case c.field == "tag":
+		c.values = parser.NormalizeTags(strings.Split(value, ","))
+	default:
+		c.values = strings.Split(value, ",")
+	}
+
+	return c, nil
+}
+
+// Match returns true when test matches all conditions
+func (q *Query) Match(entry report.TestEntry) bool {
+	for _, c := range q.conditions {
+		if !c.match(entry) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (c *condition) match(entry report.TestEntry) bool {
+	if c.field == "duration" {
+		d := entry.Test.Duration
+		switch c.op {
+		case "=":
+			return d == c.duration
+		case "!=":
+			return d != c.duration
+		case ">":
+			return d > c.duration
+		case ">=":
+			return d >= c.duration
+		case "<":
+			return d < c.duration
+		case "<=":
+			return d <= c.duration
+		}
+	}
+
+	if c.field == "tag" {
+		return entry.Test.HasTag(c.values...) == (c.op == "=")
+	}
+
+	value := c.value(entry)
+	switch c.op {
+	case "~":
+		return c.regexp.MatchString(value)
+	case "!~":
+		return !c.regexp.MatchString(value)
+	case "=":
+		return c.oneOf(value)
+	case "!=":
+		return !c.oneOf(value)
+	}
+
+	return false
+}
+
+func (c *condition) oneOf(value string) bool {
+	for _, v := range c.values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (c *condition) value(entry report.TestEntry) string {
+	switch c.field {
+	case "state":
+		return string(entry.Test.State)
+	case "name":
+		return entry.Test.Name
+	case "classname":
+		return entry.Test.Classname
+	case "file":
+		return entry.Test.File
+	case "suite":
+		return entry.Suite.Name
+	case "package":
+		return entry.Test.Package
+	case "framework":
+		return entry.TestResults.Framework
+	}
+
+	property := strings.TrimPrefix(c.field, "prop.")
+	if value, found := entry.Test.Properties[property]; found {
+		return value
+	}
+
+	return entry.Suite.Properties[property]
+}
+
+// Find returns all tests matching the query
+func (q *Query) Find(result *parser.Result) []report.TestEntry {
+	found := []report.TestEntry{}
+	for _, entry := range report.Tests(result) {
+		if q.Match(entry) {
+			found = append(found, entry)
+		}
+	}
+
+	return found
+}
+
+// Prune returns copy of result with matching tests only, empty suites and test results are dropped
+// and summaries are aggregated again from the remaining tests.
+func (q *Query) Prune(result parser.Result) parser.Result {
+	pruned := parser.NewResult()
+
+	for i := range result.TestResults {
+		testResults := result.TestResults[i]
+		suites := []parser.Suite{}
+
+		for j := range testResults.Suites {
+			suite := testResults.Suites[j]
+			tests := []parser.Test{}
+			for k := range suite.Tests {
+				if q.Match(report.TestEntry{TestResults: &testResults, Suite: &suite, Test: &suite.Tests[k]}) {
+					tests = append(tests, suite.Tests[k])
+				}
+			}
+
+			if len(tests) == 0 {
+				continue
+			}
+
+			suite.Tests = tests
+			suite.Summary = parser.Summary{}
+			suite.Aggregate()
+			suites = append(suites, suite)
+		}
+
+		if len(suites) == 0 {
+			continue
+		}
+
+		testResults.Suites = suites
+		testResults.Aggregate()
+		pruned.TestResults = append(pruned.TestResults, testResults)
+	}
+
+	return pruned
+}
+
+// Row is a single matching test in JSON lines output
+type Row struct {
+	TestResults string      `json:"testResults"`
+	Framework   string      `json:"framework"`
+	Suite       string      `json:"suite"`
+	Test        parser.Test `json:"test"`
+}
+
+// JSONLines renders matching tests as JSON, one test per line
+func JSONLines(entries []report.TestEntry) ([]byte, error) {
+	var b bytes.Buffer
+	encoder := json.NewEncoder(&b)
+	for _, entry := range entries {
+		err := encoder.Encode(Row{
+			TestResults: entry.TestResults.Name,
+			Framework:   entry.TestResults.Framework,
+			Suite:       entry.Suite.Name,
+			Test:        *entry.Test,
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return b.Bytes(), nil
+}
+
+// Table renders matching tests as aligned text table
+func Table(entries []report.TestEntry) string {
+	var b strings.Builder
+	writer := tabwriter.NewWriter(&b, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(writer, "STATE\tDURATION\tSUITE\tTEST\tFILE")
+	for _, entry := range entries {
+		fmt.Fprintf(writer, "%s\t%s\t%s\t%s\t%s\n",
+			entry.Test.State, report.FormatDuration(entry.Test.Duration), oneLine(entry.Suite.Name), oneLine(entry.Test.Name), entry.Test.File)
+	}
+	writer.Flush()
+
+	fmt.Fprintf(&b, "\n%d test(s) matched\n", len(entries))
+	return b.String()
+}
+
+func oneLine(s string) string {
+	return strings.ReplaceAll(s, "\n", " ")
+}
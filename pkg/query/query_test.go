@@ -0,0 +1,147 @@
+package query_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/semaphoreci/test-results/pkg/parser"
+	"github.com/semaphoreci/test-results/pkg/query"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newResult() parser.Result {
+	result := parser.NewResult()
+
+	for _, framework := range []string{"rspec", "golang"} {
+		testResults := parser.NewTestResults()
+		testResults.Name = framework + " tests"
+		testResults.Framework = framework
+		testResults.EnsureID()
+
+		suite := parser.NewSuite()
+		suite.Name = "User"
+		suite.Properties = parser.Properties{"seed": "1234"}
+		suite.EnsureID(testResults)
+
+		for _, tc := range []struct {
+			name     string
+			file     string
+			state    parser.State
+			duration time.Duration
+		}{
+			{"signs in", "spec/user_spec.rb", parser.StatePassed, 100 * time.Millisecond},
+			{"logs in", "spec/user_spec.rb", parser.StateFailed, 2 * time.Second},
+			{"logs out", "spec/session_spec.rb", parser.StateError, 3 * time.Second},
+			{"signs up", "spec/session_spec.rb", parser.StateSkipped, 0},
+		} {
+			test := parser.NewTest()
+			test.Name = tc.name
+			test.Classname = "User::Session"
+			test.File = tc.file
+			test.State = tc.state
+			test.Duration = tc.duration
+			if tc.state != parser.StatePassed {
+				test.Tags = []string{"broken"}
+			}
+			test.EnsureID(suite)
+			suite.AppendTest(test)
+		}
+
+		// Summary duration reported by the framework is bigger than sum of the tests
+		suite.Summary.Duration = time.Minute
+		testResults.Suites = append(testResults.Suites, suite)
+		testResults.Aggregate()
+		result.TestResults = append(result.TestResults, testResults)
+	}
+
+	return result
+}
+
+func names(t *testing.T, expression string) []string {
+	q, err := query.Parse(expression)
+	require.NoError(t, err, expression)
+
+	result := newResult()
+	found := []string{}
+	for _, entry := range q.Find(&result) {
+		found = append(found, entry.TestResults.Framework+":"+entry.Test.Name)
+	}
+
+	return found
+}
+
+func Test_Parse(t *testing.T) {
+	assert.Len(t, names(t, ""), 8, "empty query matches everything")
+	assert.Equal(t, []string{"rspec:logs in", "rspec:logs out", "golang:logs in", "golang:logs out"}, names(t, "state=failed,error"))
+	assert.Equal(t, []string{"rspec:logs in", "rspec:logs out"}, names(t, "state!=passed,skipped and framework=rspec"))
+	assert.Equal(t, []string{"golang:logs out"}, names(t, "framework=golang duration>2s"))
+	assert.Equal(t, []string{"golang:logs in", "golang:logs out"}, names(t, "framework=golang duration>=2s"))
+	assert.Equal(t, []string{"rspec:signs in", "rspec:signs up"}, names(t, `framework~^rsp name~"^signs (in|up)$"`))
+	assert.Equal(t, []string{"golang:signs in", "golang:logs in"}, names(t, "file~user_spec framework!~rspec"))
+	assert.Len(t, names(t, "suite=User classname=User::Session prop.seed=1234 duration<=3s"), 8)
+	assert.Empty(t, names(t, "prop.seed=1"))
+	assert.Empty(t, names(t, "prop.missing~."))
+	assert.Equal(t, []string{"rspec:logs in", "rspec:logs out", "rspec:signs up"}, names(t, "framework=rspec tag=@Broken"))
+	assert.Equal(t, []string{"rspec:signs in", "golang:signs in"}, names(t, "tag!=broken"))
+}
+
+func Test_Parse_Errors(t *testing.T) {
+	for _, expression := range []string{
+		"state",
+		"=failed",
+		"owner=me",
+		"state>failed",
+		"duration~1s",
+		"duration>fast",
+		"name~(",
+		`name="unterminated`,
+	} {
+		_, err := query.Parse(expression)
+		assert.Error(t, err, expression)
+	}
+}
+
+func Test_Prune(t *testing.T) {
+	q, err := query.Parse("framework=rspec state=failed,passed")
+	require.NoError(t, err)
+
+	original := newResult()
+	pruned := q.Prune(original)
+
+	require.Len(t, pruned.TestResults, 1)
+	require.Len(t, pruned.TestResults[0].Suites, 1)
+	assert.Len(t, pruned.TestResults[0].Suites[0].Tests, 2)
+	assert.Equal(t, parser.Summary{
+		Total:    2,
+		Passed:   1,
+		Failed:   1,
+		Duration: 2100 * time.Millisecond,
+		Tags:     map[string]parser.Summary{"broken": {Total: 1, Failed: 1, Duration: 2 * time.Second}},
+	}, pruned.TestResults[0].Suites[0].Summary)
+	assert.Equal(t, pruned.TestResults[0].Suites[0].Summary, pruned.TestResults[0].Summary)
+
+	assert.Len(t, original.TestResults[0].Suites[0].Tests, 4, "original result should not be modified")
+
+	q, err = query.Parse("name=nothing")
+	require.NoError(t, err)
+	assert.Empty(t, q.Prune(original).TestResults)
+}
+
+func Test_Output(t *testing.T) {
+	q, err := query.Parse("state=failed framework=rspec")
+	require.NoError(t, err)
+
+	result := newResult()
+	entries := q.Find(&result)
+
+	table := query.Table(entries)
+	assert.Contains(t, table, "STATE   DURATION  SUITE  TEST     FILE\nfailed  2.00s     User   logs in  spec/user_spec.rb\n")
+	assert.True(t, strings.HasSuffix(table, "\n1 test(s) matched\n"))
+
+	data, err := query.JSONLines(entries)
+	require.NoError(t, err)
+	assert.Equal(t, 1, strings.Count(string(data), "\n"))
+	assert.Contains(t, string(data), `{"testResults":"rspec tests","framework":"rspec","suite":"User","test":{"id":`)
+}
@@ -0,0 +1,213 @@
+package redact
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/semaphoreci/test-results/pkg/glob"
+	"github.com/semaphoreci/test-results/pkg/parser"
+)
+
+// DefaultEnv lists patterns of environment variables whose values are masked by default
+var DefaultEnv = []string{"SEMAPHORE_*_TOKEN"}
+
+// minEnvValueLength prevents masking short values like `1` or `true` everywhere in the output
+const minEnvValueLength = 6
+
+// Detector finds secrets of a single kind. When the expression has a capture group, only the group is redacted.
+type Detector struct {
+	Kind string
+	re   *regexp.Regexp
+}
+
+// Detectors are built-in detectors, applied in order
+var Detectors = []Detector{
+	{"private-key", regexp.MustCompile(`-----BEGIN [A-Z ]*PRIVATE KEY-----[\s\S]*?-----END [A-Z ]*PRIVATE KEY-----`)},
+	{"url-credentials", regexp.MustCompile(`[a-zA-Z][a-zA-Z0-9+.-]*://([^:@/\s]+:[^@/\s]+)@`)},
+	{"jwt", regexp.MustCompile(`\beyJ[A-Za-z0-9_-]{5,}\.eyJ[A-Za-z0-9_-]{5,}\.[A-Za-z0-9_-]{10,}`)},
+	{"aws-access-key", regexp.MustCompile(`\b(?:AKIA|ASIA)[0-9A-Z]{16}\b`)},
+	{"github-token", regexp.MustCompile(`\b(?:gh[pousr]_[A-Za-z0-9]{36,}|github_pat_[A-Za-z0-9_]{22,})\b`)},
+	{"slack-token", regexp.MustCompile(`\bxox[abposr]-[A-Za-z0-9-]{10,}`)},
+	{"stripe-key", regexp.MustCompile(`\b[rs]k_(?:live|test)_[A-Za-z0-9]{16,}\b`)},
+	{"google-api-key", regexp.MustCompile(`\bAIza[0-9A-Za-z_-]{35}\b`)},
+	{"bearer-token", regexp.MustCompile(`(?i)\bbearer\s+([A-Za-z0-9._~+/-]{20,}=*)`)},
+}
+
+// EmailDetector finds email addresses. It is enabled with Options.Emails, as emails in test output
+// are usually fixtures rather than secrets.
+var EmailDetector = Detector{"email", regexp.MustCompile(`\b[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}\b`)}
+
+// Options ...
+type Options struct {
+	// Patterns are user supplied regular expressions, redacted as `custom`
+	Patterns []string
+	// Env lists glob patterns of environment variable names whose values are masked
+	Env []string
+	// Environ is the environment to read variables from, defaults to os.Environ()
+	Environ []string
+	// Emails enables redaction of email addresses
+	Emails bool
+}
+
+// Redactor replaces secrets with placeholders. The same secret is always replaced with the same placeholder.
+type Redactor struct {
+	detectors []Detector
+	env       map[string]string
+
+	mu           sync.Mutex
+	placeholders map[string]string
+	counters     map[string]int
+}
+
+// New creates redactor with built-in detectors, user patterns and environment variables matching options
+func New(options Options) (*Redactor, error) {
+	r := &Redactor{
+		detectors:    append([]Detector{}, Detectors...),
+		env:          map[string]string{},
+		placeholders: map[string]string{},
+		counters:     map[string]int{},
+	}
+
+	if options.Emails {
+		r.detectors = append(r.detectors, EmailDetector)
+	}
+
+	for _, pattern := range options.Patterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid redaction pattern %q: %v", pattern, err)
+		}
+		r.detectors = append(r.detectors, Detector{Kind: "custom", re: re})
+	}
+
+	environ := options.Environ
+	if environ == nil {
+		environ = os.Environ()
+	}
+
+	for _, pattern := range options.Env {
+		re := glob.Name(pattern)
+		for _, variable := range environ {
+			name, value, found := strings.Cut(variable, "=")
+			if found && re.MatchString(name) && len(value) >= minEnvValueLength {
+				r.env[name] = value
+			}
+		}
+	}
+
+	return r, nil
+}
+
+// Redact replaces secrets found in s, the number of replaced secrets is returned
+func (r *Redactor) Redact(s string) (string, int) {
+	if s == "" {
+		return s, 0
+	}
+
+	count := 0
+
+	// Longer values first, so a value containing another one is masked as a whole
+	names := make([]string, 0, len(r.env))
+	for name := range r.env {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		if len(r.env[names[i]]) != len(r.env[names[j]]) {
+			return len(r.env[names[i]]) > len(r.env[names[j]])
+		}
+		return names[i] < names[j]
+	})
+
+	for _, name := range names {
+		if n := strings.Count(s, r.env[name]); n > 0 {
+			s = strings.ReplaceAll(s, r.env[name], "[REDACTED:env:"+name+"]")
+			count += n
+		}
+	}
+
+	for _, detector := range r.detectors {
+		s = detector.re.ReplaceAllStringFunc(s, func(match string) string {
+			secret := match
+			if submatches := detector.re.FindStringSubmatchIndex(match); len(submatches) >= 4 && submatches[2] >= 0 {
+				secret = match[submatches[2]:submatches[3]]
+				count++
+				return match[:submatches[2]] + r.placeholder(detector.Kind, secret) + match[submatches[3]:]
+			}
+
+			count++
+			return r.placeholder(detector.Kind, secret)
+		})
+	}
+
+	return s, count
+}
+
+func (r *Redactor) placeholder(kind, secret string) string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := kind + "\x00" + secret
+	if placeholder, found := r.placeholders[key]; found {
+		return placeholder
+	}
+
+	r.counters[kind]++
+	placeholder := fmt.Sprintf("[REDACTED:%s:%d]", kind, r.counters[kind])
+	r.placeholders[key] = placeholder
+
+	return placeholder
+}
+
+// Count is number of redactions in a single test, Test is empty for suite output
+type Count struct {
+	Suite      string
+	Test       string
+	Redactions int
+}
+
+// Apply redacts captured output and failures of all tests and suites in result
+func (r *Redactor) Apply(result *parser.Result) []Count {
+	counts := []Count{}
+
+	for i := range result.TestResults {
+		for j := range result.TestResults[i].Suites {
+			suite := &result.TestResults[i].Suites[j]
+
+			if n := r.redactAll(&suite.SystemOut, &suite.SystemErr); n > 0 {
+				counts = append(counts, Count{Suite: suite.Name, Redactions: n})
+			}
+
+			for k := range suite.Tests {
+				test := &suite.Tests[k]
+				fields := []*string{&test.SystemOut, &test.SystemErr}
+				if test.Failure != nil {
+					fields = append(fields, &test.Failure.Message, &test.Failure.Body)
+				}
+				if test.Error != nil {
+					fields = append(fields, &test.Error.Message, &test.Error.Body)
+				}
+
+				if n := r.redactAll(fields...); n > 0 {
+					counts = append(counts, Count{Suite: suite.Name, Test: test.Name, Redactions: n})
+				}
+			}
+		}
+	}
+
+	return counts
+}
+
+func (r *Redactor) redactAll(fields ...*string) int {
+	total := 0
+	for _, field := range fields {
+		var n int
+		*field, n = r.Redact(*field)
+		total += n
+	}
+
+	return total
+}
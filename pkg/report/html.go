@@ -0,0 +1,94 @@
+package report
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	"html/template"
+	"strings"
+	"time"
+
+	"github.com/semaphoreci/test-results/pkg/logger"
+	"github.com/semaphoreci/test-results/pkg/parser"
+)
+
+//go:embed templates/*
+var templates embed.FS
+
+var htmlTemplate = template.Must(template.New("report.html.tmpl").Funcs(template.FuncMap{
+	"duration": FormatDuration,
+	"passRate": PassRate,
+	"percent":  percent,
+	"join":     strings.Join,
+}).ParseFS(templates, "templates/report.html.tmpl"))
+
+// HTMLOptions ...
+type HTMLOptions struct {
+	Title string
+}
+
+type htmlReport struct {
+	Title       string
+	Summary     parser.Summary
+	TestResults []parser.TestResults
+	States      []parser.State
+	Owners      []OwnerFailures
+}
+
+// HTML renders test results into a self-contained HTML document
+func HTML(result parser.Result, options HTMLOptions) ([]byte, error) {
+	report := htmlReport{
+		Title:       options.Title,
+		Summary:     Summarize(result),
+		TestResults: result.TestResults,
+		States:      []parser.State{parser.StatePassed, parser.StateFailed, parser.StateError, parser.StateSkipped, parser.StateDisabled, parser.StateQuarantined},
+		Owners:      FailuresByOwner(&result),
+	}
+
+	if report.Title == "" {
+		report.Title = "Test results"
+	}
+
+	var buf bytes.Buffer
+	if err := htmlTemplate.Execute(&buf, report); err != nil {
+		logger.Error("Rendering HTML report failed with: %v", err)
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// Summarize merges summaries of all test results
+func Summarize(result parser.Result) parser.Summary {
+	summary := parser.Summary{}
+	for i := range result.TestResults {
+		summary.Merge(&result.TestResults[i].Summary)
+	}
+
+	return summary
+}
+
+// PassRate returns percentage of passed tests, skipped and disabled tests are not taken into account
+func PassRate(summary parser.Summary) float64 {
+	return summary.PassRate()
+}
+
+func percent(part, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+
+	return float64(part) / float64(total) * 100
+}
+
+// FormatDuration formats duration in human readable form with millisecond precision
+func FormatDuration(d time.Duration) string {
+	switch {
+	case d < time.Second:
+		return fmt.Sprintf("%dms", d.Milliseconds())
+	case d < time.Minute:
+		return fmt.Sprintf("%.2fs", d.Seconds())
+	default:
+		return d.Round(time.Second).String()
+	}
+}
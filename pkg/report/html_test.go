@@ -0,0 +1,108 @@
+package report_test
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/semaphoreci/test-results/pkg/parser"
+	"github.com/semaphoreci/test-results/pkg/report"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newResult() parser.Result {
+	testResults := parser.NewTestResults()
+	testResults.Name = "Unit Tests"
+	testResults.Framework = "golang"
+	testResults.EnsureID()
+
+	suite := parser.NewSuite()
+	suite.Name = "pkg/foo"
+	suite.EnsureID(testResults)
+
+	tests := []struct {
+		name     string
+		state    parser.State
+		duration time.Duration
+		failure  *parser.Failure
+	}{
+		{"TestPasses", parser.StatePassed, 120 * time.Millisecond, nil},
+		{"TestFails", parser.StateFailed, 2 * time.Second, &parser.Failure{Message: "values differ", Body: "foo_test.go:12:\n\t<script>alert(1)</script>\n\t    expected: 1"}},
+		{"TestSkips", parser.StateSkipped, 0, nil},
+		{"TestSlow", parser.StatePassed, 90 * time.Second, nil},
+	}
+
+	for _, tc := range tests {
+		test := parser.NewTest()
+		test.Name = tc.name
+		test.State = tc.state
+		test.Duration = tc.duration
+		test.Failure = tc.failure
+		test.EnsureID(suite)
+		suite.AppendTest(test)
+	}
+
+	testResults.Suites = append(testResults.Suites, suite)
+	testResults.Aggregate()
+
+	result := parser.NewResult()
+	result.TestResults = append(result.TestResults, testResults)
+
+	return result
+}
+
+func Test_HTML(t *testing.T) {
+	data, err := report.HTML(newResult(), report.HTMLOptions{})
+	require.NoError(t, err)
+	html := string(data)
+
+	assert.Contains(t, html, "<title>Test results</title>", "should use default title")
+	assert.Contains(t, html, `<div class="value">4</div><div class="label">Total</div>`)
+	assert.Contains(t, html, `<div class="value">1</div><div class="label">Failed</div>`)
+	assert.Contains(t, html, `<div class="value">66.7%</div><div class="label">Pass rate</div>`)
+	assert.Contains(t, html, `data-state="failed" data-duration="2000000000"`)
+	assert.Contains(t, html, "<details class=\"suite\" data-duration=\"92120000000\" open>", "failed suites should be expanded")
+
+	t.Run("escapes failure bodies and preserves whitespace", func(t *testing.T) {
+		assert.NotContains(t, html, "<script>alert(1)</script>")
+		assert.Contains(t, html, "<pre>foo_test.go:12:\n\t&lt;script&gt;alert(1)&lt;/script&gt;\n\t    expected: 1</pre>")
+	})
+
+	t.Run("does not reference network assets", func(t *testing.T) {
+		assert.False(t, regexp.MustCompile(`(src|href)=["']?(https?:)?//`).MatchString(html))
+		assert.NotContains(t, html, "@import")
+	})
+}
+
+func Test_HTML_Title(t *testing.T) {
+	data, err := report.HTML(parser.NewResult(), report.HTMLOptions{Title: "Nightly <build>"})
+	require.NoError(t, err)
+
+	assert.Contains(t, string(data), "<title>Nightly &lt;build&gt;</title>")
+	assert.True(t, strings.Contains(string(data), "No test results found"))
+}
+
+func Test_PassRate(t *testing.T) {
+	assert.Equal(t, float64(0), report.PassRate(parser.Summary{}))
+	assert.Equal(t, float64(0), report.PassRate(parser.Summary{Total: 2, Skipped: 2}))
+	assert.Equal(t, float64(50), report.PassRate(parser.Summary{Total: 5, Passed: 2, Failed: 2, Skipped: 1}))
+}
+
+func Test_FormatDuration(t *testing.T) {
+	assert.Equal(t, "0ms", report.FormatDuration(0))
+	assert.Equal(t, "250ms", report.FormatDuration(250*time.Millisecond))
+	assert.Equal(t, "1.50s", report.FormatDuration(1500*time.Millisecond))
+	assert.Equal(t, "2m5s", report.FormatDuration(125*time.Second))
+}
+
+func Test_HTML_Owners(t *testing.T) {
+	data, err := report.HTML(newOwnedResult(), report.HTMLOptions{})
+	require.NoError(t, err)
+	html := string(data)
+
+	assert.Contains(t, html, "<h2>Failures by owner</h2>")
+	assert.Contains(t, html, "<strong>@org/backend</strong>")
+	assert.Contains(t, html, `<div class="file">Owners: @org/backend, @jane</div>`)
+}
@@ -0,0 +1,278 @@
+package report
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/semaphoreci/test-results/pkg/parser"
+)
+
+// MarkdownOptions ...
+type MarkdownOptions struct {
+	// MaxFailures limits number of failed tests listed in the report, 0 lists none
+	MaxFailures int
+	// Top limits number of slowest tests and suites listed in the report, 0 lists none
+	Top int
+	// MessageLength truncates failure messages to N characters, 0 means unlimited
+	MessageLength int
+}
+
+// DefaultMarkdownOptions ...
+func DefaultMarkdownOptions() MarkdownOptions {
+	return MarkdownOptions{MaxFailures: 10, Top: 5, MessageLength: 200}
+}
+
+// TestEntry is a test together with results and suite it belongs to
+type TestEntry struct {
+	TestResults *parser.TestResults
+	Suite       *parser.Suite
+	Test        *parser.Test
+}
+
+// SuiteEntry is a suite together with results it belongs to
+type SuiteEntry struct {
+	TestResults *parser.TestResults
+	Suite       *parser.Suite
+}
+
+// Tests lists all tests found in result
+func Tests(result *parser.Result) []TestEntry {
+	entries := []TestEntry{}
+	for i := range result.TestResults {
+		for j := range result.TestResults[i].Suites {
+			for k := range result.TestResults[i].Suites[j].Tests {
+				entries = append(entries, TestEntry{
+					TestResults: &result.TestResults[i],
+					Suite:       &result.TestResults[i].Suites[j],
+					Test:        &result.TestResults[i].Suites[j].Tests[k],
+				})
+			}
+		}
+	}
+
+	return entries
+}
+
+// Suites lists all suites found in result
+func Suites(result *parser.Result) []SuiteEntry {
+	entries := []SuiteEntry{}
+	for i := range result.TestResults {
+		for j := range result.TestResults[i].Suites {
+			entries = append(entries, SuiteEntry{
+				TestResults: &result.TestResults[i],
+				Suite:       &result.TestResults[i].Suites[j],
+			})
+		}
+	}
+
+	return entries
+}
+
+// SlowestTests returns up to n tests sorted by duration, longest first
+func SlowestTests(result *parser.Result, n int) []TestEntry {
+	tests := Tests(result)
+	sort.SliceStable(tests, func(i, j int) bool {
+		return tests[i].Test.Duration > tests[j].Test.Duration
+	})
+
+	if len(tests) > n {
+		tests = tests[:n]
+	}
+
+	return tests
+}
+
+// SlowestSuites returns up to n suites sorted by duration, longest first
+func SlowestSuites(result *parser.Result, n int) []SuiteEntry {
+	suites := Suites(result)
+	sort.SliceStable(suites, func(i, j int) bool {
+		return suites[i].Suite.Summary.Duration > suites[j].Suite.Summary.Duration
+	})
+
+	if len(suites) > n {
+		suites = suites[:n]
+	}
+
+	return suites
+}
+
+// FailedTests returns all failed and errored tests
+func FailedTests(result *parser.Result) []TestEntry {
+	failed := []TestEntry{}
+	for _, entry := range Tests(result) {
+		switch entry.Test.State {
+		case parser.StateFailed, parser.StateError:
+			failed = append(failed, entry)
+		}
+	}
+
+	return failed
+}
+
+// FailureMessage returns failure or error message of the test, falling back to the first line of its body
+func FailureMessage(test *parser.Test) string {
+	var message, body string
+
+	switch {
+	case test.Failure != nil:
+		message, body = test.Failure.Message, test.Failure.Body
+	case test.Error != nil:
+		message, body = test.Error.Message, test.Error.Body
+	}
+
+	if strings.TrimSpace(message) == "" {
+		for _, line := range strings.Split(body, "\n") {
+			if strings.TrimSpace(line) != "" {
+				return strings.TrimSpace(line)
+			}
+		}
+	}
+
+	return strings.TrimSpace(message)
+}
+
+// Markdown renders test results summary as markdown
+func Markdown(result parser.Result, options MarkdownOptions) string {
+	summary := Summarize(result)
+
+	out := "## 🧪 Test Results Summary\n\n"
+	out += fmt.Sprintf("**Total:** `%d` | **✅ Passed:** `%d` | **❌ Failed:** `%d` | **💥 Errors:** `%d` | **⏭️ Skipped:** `%d` | **🚫 Disabled:** `%d`  \n",
+		summary.Total, summary.Passed, summary.Failed, summary.Error, summary.Skipped, summary.Disabled)
+	if summary.Quarantined > 0 {
+		out += fmt.Sprintf("**🔒 Quarantined:** `%d`  \n", summary.Quarantined)
+	}
+	out += fmt.Sprintf("**🕒 Duration:** `%s`  \n\n", FormatDuration(summary.Duration))
+	out += fmt.Sprintf("**Pass rate:** %s `%.1f%%`\n\n", progressBar(PassRate(summary), 20), PassRate(summary))
+
+	if summary.Total > 0 {
+		out += "```mermaid\n"
+		out += "pie showData\n"
+		out += "title Test states\n"
+		for _, slice := range []struct {
+			label string
+			value int
+		}{
+			{"Passed", summary.Passed},
+			{"Failed", summary.Failed},
+			{"Error", summary.Error},
+			{"Skipped", summary.Skipped},
+			{"Disabled", summary.Disabled},
+			{"Quarantined", summary.Quarantined},
+		} {
+			if slice.value > 0 {
+				out += fmt.Sprintf("\"%s\" : %d\n", slice.label, slice.value)
+			}
+		}
+		out += "```\n\n"
+	}
+
+	failed := FailedTests(&result)
+	if len(failed) > 0 && options.MaxFailures > 0 {
+		out += fmt.Sprintf("### ❌ Failed tests (%d)\n\n", len(failed))
+		out += "| Test | Suite | Message |\n"
+		out += "| --- | --- | --- |\n"
+		for i, entry := range failed {
+			if i >= options.MaxFailures {
+				break
+			}
+			out += fmt.Sprintf("| %s | %s | %s |\n",
+				EscapeCell(testName(entry.Test)),
+				EscapeCell(entry.Suite.Name),
+				EscapeCell(Truncate(FailureMessage(entry.Test), options.MessageLength)))
+		}
+		if len(failed) > options.MaxFailures {
+			out += fmt.Sprintf("\n_...and %d more_\n", len(failed)-options.MaxFailures)
+		}
+		out += "\n"
+	}
+
+	if groups := FailuresByOwner(&result); len(groups) > 0 && options.MaxFailures > 0 {
+		out += ownersMarkdown(groups, options)
+	}
+
+	if len(summary.Tags) > 0 {
+		out += tagsMarkdown(summary.Tags)
+	}
+
+	if slowest := SlowestTests(&result, options.Top); len(slowest) > 0 {
+		out += "### 🐢 Slowest tests\n\n"
+		out += "| Test | Suite | Duration |\n"
+		out += "| --- | --- | --- |\n"
+		for _, entry := range slowest {
+			out += fmt.Sprintf("| %s | %s | `%s` |\n", EscapeCell(testName(entry.Test)), EscapeCell(entry.Suite.Name), FormatDuration(entry.Test.Duration))
+		}
+		out += "\n"
+	}
+
+	if slowest := SlowestSuites(&result, options.Top); len(slowest) > 0 {
+		out += "### 🐌 Slowest suites\n\n"
+		out += "| Suite | Tests | Duration |\n"
+		out += "| --- | --- | --- |\n"
+		for _, entry := range slowest {
+			out += fmt.Sprintf("| %s | %d | `%s` |\n", EscapeCell(entry.Suite.Name), entry.Suite.Summary.Total, FormatDuration(entry.Suite.Summary.Duration))
+		}
+		out += "\n"
+	}
+
+	out += "---\n\n"
+
+	return out
+}
+
+func tagsMarkdown(tags map[string]parser.Summary) string {
+	names := []string{}
+	for name := range tags {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	out := "### 🏷️ Tags\n\n"
+	out += "| Tag | Total | Passed | Failed | Errors | Skipped | Pass rate |\n"
+	out += "| --- | --- | --- | --- | --- | --- | --- |\n"
+	for _, name := range names {
+		summary := tags[name]
+		out += fmt.Sprintf("| %s | %d | %d | %d | %d | %d | `%.1f%%` |\n",
+			EscapeCell(name), summary.Total, summary.Passed, summary.Failed, summary.Error, summary.Skipped, summary.PassRate())
+	}
+
+	return out + "\n"
+}
+
+// Truncate shortens string to at most n runes, marking truncated strings with an ellipsis
+func Truncate(s string, n int) string {
+	if n <= 0 || utf8.RuneCountInString(s) <= n {
+		return s
+	}
+
+	return string([]rune(s)[:n]) + "…"
+}
+
+func testName(test *parser.Test) string {
+	if test.Classname != "" && !strings.HasPrefix(test.Name, test.Classname) {
+		return test.Classname + " " + test.Name
+	}
+
+	return test.Name
+}
+
+func progressBar(percent float64, width int) string {
+	filled := int(percent / 100 * float64(width))
+	if filled > width {
+		filled = width
+	}
+
+	return strings.Repeat("🟩", filled) + strings.Repeat("⬜", width-filled)
+}
+
+// EscapeCell makes s safe to put in a markdown table cell: line breaks become spaces, pipes are escaped
+// and backticks are replaced, so they don't open code spans
+func EscapeCell(s string) string {
+	s = strings.ReplaceAll(s, "\r\n", " ")
+	s = strings.ReplaceAll(s, "\n", " ")
+	s = strings.ReplaceAll(s, "|", "\\|")
+	s = strings.ReplaceAll(s, "`", "'")
+
+	return s
+}
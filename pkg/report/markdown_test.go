@@ -0,0 +1,113 @@
+package report_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/semaphoreci/test-results/pkg/parser"
+	"github.com/semaphoreci/test-results/pkg/report"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Markdown(t *testing.T) {
+	out := report.Markdown(newResult(), report.DefaultMarkdownOptions())
+
+	assert.True(t, strings.HasPrefix(out, "## 🧪 Test Results Summary\n"))
+	assert.Contains(t, out, "**Total:** `4` | **✅ Passed:** `2` | **❌ Failed:** `1`")
+	assert.Contains(t, out, "**Pass rate:** "+strings.Repeat("🟩", 13)+strings.Repeat("⬜", 7)+" `66.7%`")
+
+	t.Run("renders pie chart of non-empty states", func(t *testing.T) {
+		assert.Contains(t, out, "```mermaid\npie showData\ntitle Test states\n\"Passed\" : 2\n\"Failed\" : 1\n\"Skipped\" : 1\n```")
+	})
+
+	t.Run("lists failed tests", func(t *testing.T) {
+		assert.Contains(t, out, "### ❌ Failed tests (1)")
+		assert.Contains(t, out, "| TestFails | pkg/foo | values differ |")
+	})
+
+	t.Run("lists slowest tests and suites", func(t *testing.T) {
+		assert.Contains(t, out, "### 🐢 Slowest tests\n\n| Test | Suite | Duration |\n| --- | --- | --- |\n| TestSlow | pkg/foo | `1m30s` |\n| TestFails | pkg/foo | `2.00s` |")
+		assert.Contains(t, out, "| pkg/foo | 4 | `1m32s` |")
+	})
+}
+
+func Test_Markdown_Limits(t *testing.T) {
+	result := newResult()
+	for i := range result.TestResults[0].Suites[0].Tests {
+		result.TestResults[0].Suites[0].Tests[i].State = parser.StateFailed
+		result.TestResults[0].Suites[0].Tests[i].Failure = &parser.Failure{Body: "\n  first line | of body\nsecond line"}
+	}
+
+	out := report.Markdown(result, report.MarkdownOptions{MaxFailures: 2, Top: 1, MessageLength: 10})
+
+	assert.Contains(t, out, "| TestPasses | pkg/foo | first line… |", "should use first body line and truncate it")
+	assert.Contains(t, out, "_...and 2 more_")
+	assert.Equal(t, 2, strings.Count(out, "| `"), "should list only top slowest test and suite")
+}
+
+func Test_Markdown_Quarantined(t *testing.T) {
+	result := newResult()
+	suite := &result.TestResults[0].Suites[0]
+	for i := range suite.Tests {
+		if suite.Tests[i].State == parser.StateFailed {
+			suite.Tests[i].State = parser.StateQuarantined
+		}
+	}
+	suite.Aggregate()
+	result.TestResults[0].Aggregate()
+
+	out := report.Markdown(result, report.DefaultMarkdownOptions())
+
+	assert.Contains(t, out, "**🔒 Quarantined:** `1`")
+	assert.Contains(t, out, "\"Quarantined\" : 1\n")
+	assert.NotContains(t, out, "### ❌ Failed tests")
+}
+
+func Test_Markdown_Empty(t *testing.T) {
+	out := report.Markdown(parser.NewResult(), report.DefaultMarkdownOptions())
+
+	assert.Contains(t, out, "**Total:** `0`")
+	assert.NotContains(t, out, "mermaid")
+	assert.NotContains(t, out, "###")
+}
+
+func Test_FailureMessage(t *testing.T) {
+	assert.Equal(t, "", report.FailureMessage(&parser.Test{}))
+	assert.Equal(t, "msg", report.FailureMessage(&parser.Test{Failure: &parser.Failure{Message: " msg ", Body: "body"}}))
+	assert.Equal(t, "body", report.FailureMessage(&parser.Test{Error: &parser.Error{Body: "\n body\nmore"}}))
+}
+
+func Test_Truncate(t *testing.T) {
+	assert.Equal(t, "abc", report.Truncate("abc", 0))
+	assert.Equal(t, "abc", report.Truncate("abc", 3))
+	assert.Equal(t, "ab…", report.Truncate("abc", 2))
+	assert.Equal(t, "żó…", report.Truncate("żółw", 2), "should respect rune boundaries")
+}
+
+func Test_Markdown_Owners(t *testing.T) {
+	assert.NotContains(t, report.Markdown(newResult(), report.DefaultMarkdownOptions()), "Failures by owner")
+
+	out := report.Markdown(newOwnedResult(), report.MarkdownOptions{MaxFailures: 1, Top: 1, MessageLength: 10})
+
+	assert.Contains(t, out, "### 👥 Failures by owner\n\n| Owner | Failed | Tests |\n| --- | --- | --- |\n")
+	assert.Contains(t, out, "| @org/backend | 2 | TestPasses<br>_...and 1 more_ |")
+	assert.Contains(t, out, "| unowned | 1 | TestFails |")
+}
+
+func Test_Markdown_Tags(t *testing.T) {
+	assert.NotContains(t, report.Markdown(newResult(), report.DefaultMarkdownOptions()), "Tags")
+
+	result := newResult()
+	suite := &result.TestResults[0].Suites[0]
+	for i := range suite.Tests {
+		suite.Tests[i].Tags = []string{"unit"}
+	}
+	suite.Tests[1].Tags = []string{"slow", "unit"}
+	suite.Aggregate()
+	result.TestResults[0].Aggregate()
+
+	out := report.Markdown(result, report.DefaultMarkdownOptions())
+
+	assert.Contains(t, out, "### 🏷️ Tags\n\n| Tag | Total | Passed | Failed | Errors | Skipped | Pass rate |\n| --- | --- | --- | --- | --- | --- | --- |\n")
+	assert.Contains(t, out, "| slow | 1 | 0 | 1 | 0 | 0 | `0.0%` |\n| unit | 4 | 2 | 1 | 0 | 1 | `66.7%` |\n")
+}
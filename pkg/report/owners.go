@@ -0,0 +1,100 @@
+package report
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/semaphoreci/test-results/pkg/parser"
+)
+
+// Unowned groups failed tests without owners
+const Unowned = "unowned"
+
+// OwnerFailures lists failed tests of a single owner
+type OwnerFailures struct {
+	Owner string
+	Tests []TestEntry
+}
+
+// FailuresByOwner groups failed tests by their owners, a test with multiple owners is listed under each of them.
+// Groups are sorted by number of failures, unowned tests come last. Nil is returned when no failed test has owners.
+func FailuresByOwner(result *parser.Result) []OwnerFailures {
+	groups := map[string]*OwnerFailures{}
+	owned := false
+
+	for _, entry := range FailedTests(result) {
+		testOwners := entry.Test.Owners
+		if len(testOwners) == 0 {
+			testOwners = []string{Unowned}
+		} else {
+			owned = true
+		}
+
+		for _, owner := range testOwners {
+			if groups[owner] == nil {
+				groups[owner] = &OwnerFailures{Owner: owner}
+			}
+			groups[owner].Tests = append(groups[owner].Tests, entry)
+		}
+	}
+
+	if !owned {
+		return nil
+	}
+
+	sorted := []OwnerFailures{}
+	for _, group := range groups {
+		sorted = append(sorted, *group)
+	}
+
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if (sorted[i].Owner == Unowned) != (sorted[j].Owner == Unowned) {
+			return sorted[j].Owner == Unowned
+		}
+		if len(sorted[i].Tests) != len(sorted[j].Tests) {
+			return len(sorted[i].Tests) > len(sorted[j].Tests)
+		}
+		return sorted[i].Owner < sorted[j].Owner
+	})
+
+	return sorted
+}
+
+// OwnersText renders failures grouped by owner as plain text
+func OwnersText(groups []OwnerFailures) string {
+	if len(groups) == 0 {
+		return "No failures with owners found\n"
+	}
+
+	var b strings.Builder
+	for _, group := range groups {
+		fmt.Fprintf(&b, "%s: %d failed\n", group.Owner, len(group.Tests))
+		for _, entry := range group.Tests {
+			fmt.Fprintf(&b, "  - %s › %s\n", entry.Suite.Name, testName(entry.Test))
+		}
+	}
+
+	return b.String()
+}
+
+func ownersMarkdown(groups []OwnerFailures, options MarkdownOptions) string {
+	out := "### 👥 Failures by owner\n\n"
+	out += "| Owner | Failed | Tests |\n"
+	out += "| --- | --- | --- |\n"
+
+	for _, group := range groups {
+		names := []string{}
+		for i, entry := range group.Tests {
+			if i >= options.MaxFailures {
+				names = append(names, fmt.Sprintf("_...and %d more_", len(group.Tests)-i))
+				break
+			}
+			names = append(names, EscapeCell(testName(entry.Test)))
+		}
+
+		out += fmt.Sprintf("| %s | %d | %s |\n", EscapeCell(group.Owner), len(group.Tests), strings.Join(names, "<br>"))
+	}
+
+	return out + "\n"
+}
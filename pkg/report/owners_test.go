@@ -0,0 +1,56 @@
+package report_test
+
+import (
+	"testing"
+
+	"github.com/semaphoreci/test-results/pkg/parser"
+	"github.com/semaphoreci/test-results/pkg/report"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newOwnedResult() parser.Result {
+	result := newResult()
+	tests := result.TestResults[0].Suites[0].Tests
+	for i := range tests {
+		switch tests[i].Name {
+		case "TestPasses":
+			tests[i].State = parser.StateFailed
+			tests[i].Owners = []string{"@org/backend", "@jane"}
+		case "TestSkips":
+			tests[i].State = parser.StateError
+			tests[i].Owners = []string{"@org/backend"}
+		}
+	}
+
+	return result
+}
+
+func Test_FailuresByOwner(t *testing.T) {
+	result := newResult()
+	assert.Nil(t, report.FailuresByOwner(&result), "should be empty when no failed test has owners")
+
+	result = newOwnedResult()
+	groups := report.FailuresByOwner(&result)
+	require.Len(t, groups, 3)
+
+	assert.Equal(t, "@org/backend", groups[0].Owner)
+	assert.Len(t, groups[0].Tests, 2)
+	assert.Equal(t, "@jane", groups[1].Owner)
+	assert.Equal(t, report.Unowned, groups[2].Owner, "unowned tests should come last")
+	assert.Equal(t, "TestFails", groups[2].Tests[0].Test.Name)
+}
+
+func Test_OwnersText(t *testing.T) {
+	result := newOwnedResult()
+
+	assert.Equal(t, "No failures with owners found\n", report.OwnersText(nil))
+	assert.Equal(t, `@org/backend: 2 failed
+  - pkg/foo › TestPasses
+  - pkg/foo › TestSkips
+@jane: 1 failed
+  - pkg/foo › TestPasses
+unowned: 1 failed
+  - pkg/foo › TestFails
+`, report.OwnersText(report.FailuresByOwner(&result)))
+}
@@ -0,0 +1,15 @@
+package report
+
+import "time"
+
+// Slower tells if a test got slower from base to head duration. Both thresholds have to be reached:
+// the duration grew by at least min and by at least percent of base, a zero threshold disables its check.
+// Tests without base duration only have to reach min.
+func Slower(base, head time.Duration, percent float64, min time.Duration) bool {
+	delta := head - base
+	if delta <= 0 || delta < min {
+		return false
+	}
+
+	return percent <= 0 || base == 0 || float64(delta)/float64(base)*100 >= percent
+}
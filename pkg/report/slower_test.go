@@ -0,0 +1,18 @@
+package report_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/semaphoreci/test-results/pkg/report"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Slower(t *testing.T) {
+	assert.True(t, report.Slower(time.Second, 3*time.Second, 50, time.Second))
+	assert.False(t, report.Slower(time.Second, 1200*time.Millisecond, 10, time.Second), "slowdown below min")
+	assert.False(t, report.Slower(10*time.Second, 12*time.Second, 50, time.Second), "slowdown below percent")
+	assert.True(t, report.Slower(10*time.Second, 12*time.Second, 0, time.Second), "zero percent disables the check")
+	assert.True(t, report.Slower(0, time.Second, 50, time.Second), "tests without base duration only have to reach min")
+	assert.False(t, report.Slower(time.Second, time.Second, 0, 0))
+}
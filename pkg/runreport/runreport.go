@@ -0,0 +1,195 @@
+// Package runreport records what a test-results command did: inputs, parsed files, test counts,
+// warnings, uploaded artifacts and timings. The report is written as JSON, so runs can be monitored across jobs.
+package runreport
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/semaphoreci/test-results/pkg/parser"
+	log "github.com/sirupsen/logrus"
+)
+
+// StatusSuccess and StatusError are statuses of a finished run
+const (
+	StatusSuccess = "success"
+	StatusError   = "error"
+)
+
+// File is a parsed input file
+type File struct {
+	Path      string          `json:"path"`
+	Parser    string          `json:"parser,omitempty"`
+	Rule      string          `json:"rule,omitempty"`
+	Summary   *parser.Summary `json:"summary,omitempty"`
+	Error     string          `json:"error,omitempty"`
+	ErrorKind string          `json:"errorKind,omitempty"`
+}
+
+// Artifact is an uploaded artifact
+type Artifact struct {
+	Level       string  `json:"level"`
+	File        string  `json:"file"`
+	Destination string  `json:"destination"`
+	Attempts    int     `json:"attempts"`
+	DurationMs  float64 `json:"durationMs"`
+	Error       string  `json:"error,omitempty"`
+}
+
+// Timing is the duration of a phase of the run, e.g. parsing or uploading
+type Timing struct {
+	Name       string  `json:"name"`
+	DurationMs float64 `json:"durationMs"`
+}
+
+// Report of a single command run
+type Report struct {
+	Command    string          `json:"command"`
+	Args       []string        `json:"args"`
+	Version    string          `json:"version"`
+	Status     string          `json:"status"`
+	Error      string          `json:"error,omitempty"`
+	StartedAt  time.Time       `json:"startedAt"`
+	FinishedAt time.Time       `json:"finishedAt"`
+	DurationMs float64         `json:"durationMs"`
+	Inputs     []string        `json:"inputs"`
+	Files      []File          `json:"files"`
+	Summary    *parser.Summary `json:"summary,omitempty"`
+	Warnings   []string        `json:"warnings"`
+	Artifacts  []Artifact      `json:"artifacts"`
+	Timings    []Timing        `json:"timings"`
+
+	mu  sync.Mutex
+	now func() time.Time
+}
+
+// New starts report of command run
+func New(command string, args []string, version string) *Report {
+	r := &Report{
+		Command:   command,
+		Args:      args,
+		Version:   version,
+		Inputs:    []string{},
+		Files:     []File{},
+		Warnings:  []string{},
+		Artifacts: []Artifact{},
+		Timings:   []Timing{},
+		now:       time.Now,
+	}
+	r.StartedAt = r.now()
+
+	return r
+}
+
+// AddInputs records files discovered from command arguments
+func (r *Report) AddInputs(paths ...string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.Inputs = append(r.Inputs, paths...)
+}
+
+// AddFile records parsed file
+func (r *Report) AddFile(file File) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.Files = append(r.Files, file)
+}
+
+// AddArtifact records uploaded artifact
+func (r *Report) AddArtifact(artifact Artifact) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.Artifacts = append(r.Artifacts, artifact)
+}
+
+// SetResult records test counts of result, later calls replace earlier ones
+func (r *Report) SetResult(result *parser.Result) {
+	summary := Summarize(result)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.Summary = &summary
+}
+
+// Time starts timing of a phase, the returned function stops it
+func (r *Report) Time(name string) func() {
+	started := r.now()
+	return func() {
+		duration := r.now().Sub(started)
+
+		r.mu.Lock()
+		defer r.mu.Unlock()
+
+		r.Timings = append(r.Timings, Timing{Name: name, DurationMs: Milliseconds(duration)})
+	}
+}
+
+// Finish records outcome of the run
+func (r *Report) Finish(err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.FinishedAt = r.now()
+	r.DurationMs = Milliseconds(r.FinishedAt.Sub(r.StartedAt))
+	r.Status = StatusSuccess
+	if err != nil {
+		r.Status = StatusError
+		r.Error = err.Error()
+	}
+}
+
+// Write saves report as indented JSON
+func (r *Report) Write(path string) error {
+	data := &bytes.Buffer{}
+	encoder := json.NewEncoder(data)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+
+	r.mu.Lock()
+	err := encoder.Encode(r)
+	r.mu.Unlock()
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(filepath.Clean(path), data.Bytes(), 0600)
+}
+
+// Levels are log levels recorded as warnings of the report
+func (r *Report) Levels() []log.Level {
+	return []log.Level{log.WarnLevel}
+}
+
+// Fire records warnings logged during the run, it makes the report a logrus hook.
+// Errors are not recorded, they are reported with files and artifacts they belong to, and as error of the run.
+func (r *Report) Fire(entry *log.Entry) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.Warnings = append(r.Warnings, strings.TrimSpace(entry.Message))
+	return nil
+}
+
+// Summarize sums up summaries of all test results
+func Summarize(result *parser.Result) parser.Summary {
+	summary := parser.Summary{}
+	for i := range result.TestResults {
+		summary.Merge(&result.TestResults[i].Summary)
+	}
+
+	return summary
+}
+
+// Milliseconds converts duration into fractional milliseconds
+func Milliseconds(duration time.Duration) float64 {
+	return float64(duration.Microseconds()) / 1000
+}
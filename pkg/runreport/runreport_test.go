@@ -0,0 +1,91 @@
+package runreport
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/semaphoreci/test-results/pkg/parser"
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func fakeClock(start time.Time, step time.Duration) func() time.Time {
+	now := start
+	return func() time.Time {
+		current := now
+		now = now.Add(step)
+		return current
+	}
+}
+
+func Test_Report(t *testing.T) {
+	start := time.Date(2022, 1, 1, 10, 0, 0, 0, time.UTC)
+
+	r := New("compile", []string{"results/"}, "0.8.0")
+	r.now = fakeClock(start, 250*time.Millisecond)
+	r.StartedAt = r.now()
+
+	r.AddInputs("results/a.xml", "results/b.xml")
+
+	stop := r.Time("parse")
+	r.AddFile(File{Path: "results/a.xml", Parser: "golang", Summary: &parser.Summary{Total: 2, Passed: 1, Failed: 1}})
+	r.AddFile(File{Path: "results/b.xml", Error: "EOF", ErrorKind: "invalid-xml"})
+	stop()
+
+	result := parser.NewResult()
+	result.TestResults = []parser.TestResults{
+		{Summary: parser.Summary{Total: 2, Passed: 1, Failed: 1}},
+		{Summary: parser.Summary{Total: 3, Passed: 3}},
+	}
+	r.SetResult(&result)
+
+	r.AddArtifact(Artifact{Level: "job", File: "/tmp/out.json", Destination: "test-results/junit.json", Attempts: 1})
+
+	logger := log.New()
+	logger.SetOutput(io.Discard)
+	logger.AddHook(r)
+	logger.Warn("1 of 2 file(s) failed to parse, skipping them:\n")
+	logger.Error("Not recorded")
+
+	r.Finish(errors.New("1 of 2 file(s) failed to parse"))
+
+	path := filepath.Join(t.TempDir(), "run.json")
+	require.NoError(t, r.Write(path))
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+
+	report := Report{}
+	require.NoError(t, json.Unmarshal(data, &report))
+
+	assert.Equal(t, "compile", report.Command)
+	assert.Equal(t, []string{"results/"}, report.Args)
+	assert.Equal(t, StatusError, report.Status)
+	assert.Equal(t, "1 of 2 file(s) failed to parse", report.Error)
+	assert.Equal(t, start, report.StartedAt)
+	assert.Equal(t, 750.0, report.DurationMs)
+	assert.Equal(t, []string{"results/a.xml", "results/b.xml"}, report.Inputs)
+	assert.Len(t, report.Files, 2)
+	assert.Equal(t, "invalid-xml", report.Files[1].ErrorKind)
+	assert.Equal(t, 5, report.Summary.Total)
+	assert.Equal(t, 4, report.Summary.Passed)
+	assert.Equal(t, 1, report.Summary.Failed)
+	assert.Equal(t, []string{"1 of 2 file(s) failed to parse, skipping them:"}, report.Warnings)
+	assert.Equal(t, []Artifact{{Level: "job", File: "/tmp/out.json", Destination: "test-results/junit.json", Attempts: 1}}, report.Artifacts)
+	assert.Equal(t, []Timing{{Name: "parse", DurationMs: 250}}, report.Timings)
+}
+
+func Test_Report_Success(t *testing.T) {
+	r := New("combine", []string{}, "0.8.0")
+	r.Finish(nil)
+
+	assert.Equal(t, StatusSuccess, r.Status)
+	assert.Empty(t, r.Error)
+	assert.False(t, r.FinishedAt.Before(r.StartedAt))
+}
@@ -0,0 +1,173 @@
+package split
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/semaphoreci/test-results/pkg/parser"
+)
+
+// By selects what items are being distributed between shards
+type By string
+
+const (
+	// ByFile distributes test files
+	ByFile By = "file"
+	// ByTest distributes test IDs
+	ByTest By = "test"
+)
+
+// ParseBy ...
+func ParseBy(s string) (By, error) {
+	switch By(s) {
+	case ByFile, ByTest:
+		return By(s), nil
+	}
+
+	return "", fmt.Errorf("unsupported split mode: %s, must be one of: file, test", s)
+}
+
+// Options ...
+type Options struct {
+	// Count is the number of shards
+	Count int
+	// DefaultDuration is estimated duration of items without history, average of known items is used when zero
+	DefaultDuration time.Duration
+}
+
+// Item is a single file or test assigned to a shard
+type Item struct {
+	Name      string        `json:"name"`
+	Duration  time.Duration `json:"duration"`
+	Estimated bool          `json:"estimated"`
+}
+
+// Shard ...
+type Shard struct {
+	Index    int           `json:"index"`
+	Duration time.Duration `json:"duration"`
+	Items    []Item        `json:"items"`
+}
+
+// Names returns names of all items in the shard
+func (s *Shard) Names() []string {
+	names := []string{}
+	for _, item := range s.Items {
+		names = append(names, item.Name)
+	}
+
+	return names
+}
+
+// Durations collects historical durations of test files or test IDs found in result.
+// Tests without a file are left out when splitting by file, suite names are not paths the test runner could run.
+func Durations(result parser.Result, by By) map[string]time.Duration {
+	durations := map[string]time.Duration{}
+
+	for _, testResults := range result.TestResults {
+		for _, suite := range testResults.Suites {
+			for _, test := range suite.Tests {
+				key := test.ID
+				if by == ByFile {
+					if test.File == "" {
+						continue
+					}
+					key = NormalizePath(test.File)
+				}
+
+				durations[key] += test.Duration
+			}
+		}
+	}
+
+	return durations
+}
+
+// NormalizePath makes paths comparable between historical results and current file list
+func NormalizePath(path string) string {
+	return strings.TrimPrefix(filepath.ToSlash(filepath.Clean(path)), "./")
+}
+
+// Split distributes items between shards so that shards have balanced predicted durations.
+// Items are assigned from the longest one to the shortest one, each to the currently shortest shard.
+// The result is deterministic for the same input, so each parallel job can compute it independently.
+func Split(names []string, durations map[string]time.Duration, options Options) ([]Shard, error) {
+	if options.Count < 1 {
+		return nil, fmt.Errorf("number of shards must be positive, got %d", options.Count)
+	}
+
+	estimate := options.DefaultDuration
+	if estimate <= 0 {
+		estimate = average(durations)
+	}
+
+	items := []Item{}
+	seen := map[string]bool{}
+	for _, name := range names {
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+
+		duration, found := durations[name]
+		items = append(items, Item{Name: name, Duration: duration})
+		if !found {
+			items[len(items)-1].Duration = estimate
+			items[len(items)-1].Estimated = true
+		}
+	}
+
+	sort.SliceStable(items, func(i, j int) bool {
+		if items[i].Duration != items[j].Duration {
+			return items[i].Duration > items[j].Duration
+		}
+		return items[i].Name < items[j].Name
+	})
+
+	shards := make([]Shard, options.Count)
+	for i := range shards {
+		shards[i] = Shard{Index: i + 1, Items: []Item{}}
+	}
+
+	for _, item := range items {
+		shortest := 0
+		for i := range shards {
+			if shards[i].Duration < shards[shortest].Duration ||
+				shards[i].Duration == shards[shortest].Duration && len(shards[i].Items) < len(shards[shortest].Items) {
+				shortest = i
+			}
+		}
+
+		shards[shortest].Items = append(shards[shortest].Items, item)
+		shards[shortest].Duration += item.Duration
+	}
+
+	for i := range shards {
+		sort.SliceStable(shards[i].Items, func(a, b int) bool {
+			return shards[i].Items[a].Name < shards[i].Items[b].Name
+		})
+	}
+
+	return shards, nil
+}
+
+func average(durations map[string]time.Duration) time.Duration {
+	var total time.Duration
+	count := 0
+	for _, duration := range durations {
+		if duration > 0 {
+			total += duration
+			count++
+		}
+	}
+
+	if count == 0 {
+		// Without any history every item weighs the same
+		return time.Second
+	}
+
+	return total / time.Duration(count)
+}
@@ -0,0 +1,113 @@
+package split_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/semaphoreci/test-results/pkg/parser"
+	"github.com/semaphoreci/test-results/pkg/split"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Durations(t *testing.T) {
+	testResults := parser.NewTestResults()
+	testResults.Name = "RSpec"
+	testResults.EnsureID()
+
+	suite := parser.NewSuite()
+	suite.Name = "pkg/foo"
+	suite.EnsureID(testResults)
+
+	for _, tc := range []struct {
+		file     string
+		duration time.Duration
+	}{
+		{"./spec/a_spec.rb", time.Second},
+		{"spec/a_spec.rb", 2 * time.Second},
+		{"spec/b_spec.rb", 3 * time.Second},
+		{"", 4 * time.Second},
+	} {
+		test := parser.NewTest()
+		test.Name = tc.file
+		test.File = tc.file
+		test.Duration = tc.duration
+		test.EnsureID(suite)
+		suite.AppendTest(test)
+	}
+
+	testResults.Suites = append(testResults.Suites, suite)
+	result := parser.Result{TestResults: []parser.TestResults{testResults}}
+
+	assert.Equal(t, map[string]time.Duration{
+		"spec/a_spec.rb": 3 * time.Second,
+		"spec/b_spec.rb": 3 * time.Second,
+	}, split.Durations(result, split.ByFile), "should group by normalized file, skipping tests without file")
+
+	byTest := split.Durations(result, split.ByTest)
+	assert.Len(t, byTest, 4)
+	assert.Equal(t, 4*time.Second, byTest[suite.Tests[3].ID])
+}
+
+func Test_Split(t *testing.T) {
+	durations := map[string]time.Duration{
+		"a": 8 * time.Second,
+		"b": 7 * time.Second,
+		"c": 6 * time.Second,
+		"d": 5 * time.Second,
+		"e": 4 * time.Second,
+		"f": 0,
+	}
+
+	shards, err := split.Split([]string{"a", "b", "c", "d", "e", "new", "a"}, durations, split.Options{Count: 3})
+	require.NoError(t, err)
+	require.Len(t, shards, 3)
+
+	assert.Equal(t, 1, shards[0].Index)
+	assert.Equal(t, []string{"a", "e"}, shards[0].Names())
+	assert.Equal(t, []string{"b", "d"}, shards[1].Names())
+	assert.Equal(t, []string{"c", "new"}, shards[2].Names())
+
+	assert.Equal(t, 6*time.Second, shards[2].Items[1].Duration, "new item should be estimated with average of known items")
+	assert.True(t, shards[2].Items[1].Estimated)
+	for _, shard := range shards {
+		assert.Equal(t, 12*time.Second, shard.Duration)
+	}
+}
+
+func Test_Split_DefaultDuration(t *testing.T) {
+	shards, err := split.Split([]string{"a", "b", "c"}, map[string]time.Duration{"a": 10 * time.Second}, split.Options{Count: 2, DefaultDuration: 6 * time.Second})
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"a"}, shards[0].Names())
+	assert.Equal(t, []string{"b", "c"}, shards[1].Names())
+}
+
+func Test_Split_WithoutHistory(t *testing.T) {
+	shards, err := split.Split([]string{"a", "b", "c", "d", "e"}, map[string]time.Duration{}, split.Options{Count: 2})
+	require.NoError(t, err)
+
+	assert.Len(t, shards[0].Items, 3, "items should be distributed evenly")
+	assert.Len(t, shards[1].Items, 2)
+}
+
+func Test_Split_MoreShardsThanItems(t *testing.T) {
+	shards, err := split.Split([]string{"a"}, map[string]time.Duration{}, split.Options{Count: 3})
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"a"}, shards[0].Names())
+	assert.Empty(t, shards[1].Items)
+	assert.Empty(t, shards[2].Items)
+
+	_, err = split.Split([]string{"a"}, map[string]time.Duration{}, split.Options{Count: 0})
+	assert.Error(t, err)
+}
+
+func Test_ParseBy(t *testing.T) {
+	by, err := split.ParseBy("test")
+	assert.NoError(t, err)
+	assert.Equal(t, split.ByTest, by)
+
+	_, err = split.ParseBy("suite")
+	assert.Error(t, err)
+}
@@ -0,0 +1,357 @@
+package stats
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/semaphoreci/test-results/pkg/parser"
+	"github.com/semaphoreci/test-results/pkg/report"
+)
+
+// Options ...
+type Options struct {
+	// Top limits number of slowest tests, suites and files
+	Top int
+	// SlowerPercent reports tests that got slower by at least given percentage, 0 disables the check
+	SlowerPercent float64
+	// SlowerBy reports tests that got slower by at least given duration, 0 disables the check.
+	// Both thresholds have to be reached, as in diff.
+	SlowerBy time.Duration
+	// MinDuration ignores tests faster than given duration in both runs to avoid noise
+	MinDuration time.Duration
+}
+
+// DefaultOptions ...
+func DefaultOptions() Options {
+	return Options{Top: 10, SlowerPercent: 50, SlowerBy: time.Second, MinDuration: 100 * time.Millisecond}
+}
+
+// Test ...
+type Test struct {
+	ID        string        `json:"id"`
+	Name      string        `json:"name"`
+	Suite     string        `json:"suite"`
+	File      string        `json:"file"`
+	Framework string        `json:"framework"`
+	Duration  time.Duration `json:"duration"`
+}
+
+// Suite ...
+type Suite struct {
+	Name      string        `json:"name"`
+	Framework string        `json:"framework"`
+	Tests     int           `json:"tests"`
+	Duration  time.Duration `json:"duration"`
+	// Share is percentage of total wall time spent in the suite
+	Share float64 `json:"share"`
+}
+
+// File ...
+type File struct {
+	File     string        `json:"file"`
+	Tests    int           `json:"tests"`
+	Duration time.Duration `json:"duration"`
+}
+
+// Distribution describes test durations of a single framework
+type Distribution struct {
+	Framework string        `json:"framework"`
+	Tests     int           `json:"tests"`
+	Total     time.Duration `json:"total"`
+	P50       time.Duration `json:"p50"`
+	P90       time.Duration `json:"p90"`
+	P99       time.Duration `json:"p99"`
+	Max       time.Duration `json:"max"`
+}
+
+// Regression is a test that got slower compared to baseline
+type Regression struct {
+	Test
+	BaseDuration time.Duration `json:"baseDuration"`
+	Delta        time.Duration `json:"delta"`
+	Percent      float64       `json:"percent"`
+}
+
+// Stats ...
+type Stats struct {
+	Tests         int            `json:"tests"`
+	Duration      time.Duration  `json:"duration"`
+	SlowestTests  []Test         `json:"slowestTests"`
+	SlowestSuites []Suite        `json:"slowestSuites"`
+	SlowestFiles  []File         `json:"slowestFiles"`
+	Distributions []Distribution `json:"distributions"`
+	Regressions   []Regression   `json:"regressions,omitempty"`
+	HasBaseline   bool           `json:"hasBaseline"`
+}
+
+// Compute collects duration statistics of result, regressions are computed only when baseline is given
+func Compute(result parser.Result, baseline *parser.Result, options Options) Stats {
+	stats := Stats{
+		SlowestTests:  []Test{},
+		SlowestSuites: []Suite{},
+		SlowestFiles:  []File{},
+		Distributions: []Distribution{},
+	}
+
+	entries := report.Tests(&result)
+	stats.Tests = len(entries)
+
+	tests := []Test{}
+	files := map[string]*File{}
+	durations := map[string][]time.Duration{}
+	frameworks := []string{}
+	for _, entry := range entries {
+		test := newTest(entry)
+		tests = append(tests, test)
+
+		if test.File != "" {
+			if files[test.File] == nil {
+				files[test.File] = &File{File: test.File}
+			}
+			files[test.File].Tests++
+			files[test.File].Duration += test.Duration
+		}
+
+		if _, found := durations[test.Framework]; !found {
+			frameworks = append(frameworks, test.Framework)
+		}
+		durations[test.Framework] = append(durations[test.Framework], test.Duration)
+	}
+
+	sort.SliceStable(tests, func(i, j int) bool { return tests[i].Duration > tests[j].Duration })
+	stats.SlowestTests = tests[:limit(len(tests), options.Top)]
+
+	suites := []Suite{}
+	for _, entry := range report.Suites(&result) {
+		stats.Duration += entry.Suite.Summary.Duration
+		suites = append(suites, Suite{
+			Name:      entry.Suite.Name,
+			Framework: entry.TestResults.Framework,
+			Tests:     len(entry.Suite.Tests),
+			Duration:  entry.Suite.Summary.Duration,
+		})
+	}
+
+	for i := range suites {
+		if stats.Duration > 0 {
+			suites[i].Share = float64(suites[i].Duration) / float64(stats.Duration) * 100
+		}
+	}
+
+	sort.SliceStable(suites, func(i, j int) bool { return suites[i].Duration > suites[j].Duration })
+	stats.SlowestSuites = suites[:limit(len(suites), options.Top)]
+
+	fileStats := []File{}
+	for _, file := range files {
+		fileStats = append(fileStats, *file)
+	}
+	sort.SliceStable(fileStats, func(i, j int) bool {
+		if fileStats[i].Duration != fileStats[j].Duration {
+			return fileStats[i].Duration > fileStats[j].Duration
+		}
+		return fileStats[i].File < fileStats[j].File
+	})
+	stats.SlowestFiles = fileStats[:limit(len(fileStats), options.Top)]
+
+	sort.Strings(frameworks)
+	for _, framework := range frameworks {
+		stats.Distributions = append(stats.Distributions, distribution(framework, durations[framework]))
+	}
+
+	if baseline != nil {
+		stats.HasBaseline = true
+		stats.Regressions = regressions(tests, *baseline, options)
+	}
+
+	return stats
+}
+
+// Percentile returns nearest-rank percentile of sorted durations
+func Percentile(sorted []time.Duration, p float64) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+
+	rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+	if rank < 1 {
+		rank = 1
+	}
+	if rank > len(sorted) {
+		rank = len(sorted)
+	}
+
+	return sorted[rank-1]
+}
+
+func distribution(framework string, durations []time.Duration) Distribution {
+	sorted := append([]time.Duration{}, durations...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	d := Distribution{Framework: framework, Tests: len(sorted)}
+	for _, duration := range sorted {
+		d.Total += duration
+	}
+
+	d.P50 = Percentile(sorted, 50)
+	d.P90 = Percentile(sorted, 90)
+	d.P99 = Percentile(sorted, 99)
+	if len(sorted) > 0 {
+		d.Max = sorted[len(sorted)-1]
+	}
+
+	return d
+}
+
+func regressions(tests []Test, baseline parser.Result, options Options) []Regression {
+	base := map[string]time.Duration{}
+	for _, entry := range report.Tests(&baseline) {
+		base[entry.Test.ID] = entry.Test.Duration
+	}
+
+	found := []Regression{}
+	for _, test := range tests {
+		baseDuration, ok := base[test.ID]
+		if !ok || !isSlower(baseDuration, test.Duration, options) {
+			continue
+		}
+
+		regression := Regression{Test: test, BaseDuration: baseDuration, Delta: test.Duration - baseDuration}
+		if baseDuration > 0 {
+			regression.Percent = float64(regression.Delta) / float64(baseDuration) * 100
+		}
+		found = append(found, regression)
+	}
+
+	sort.SliceStable(found, func(i, j int) bool { return found[i].Delta > found[j].Delta })
+	return found
+}
+
+func isSlower(base, head time.Duration, options Options) bool {
+	return head >= options.MinDuration && report.Slower(base, head, options.SlowerPercent, options.SlowerBy)
+}
+
+func newTest(entry report.TestEntry) Test {
+	return Test{
+		ID:        entry.Test.ID,
+		Name:      entry.Test.Name,
+		Suite:     entry.Suite.Name,
+		File:      entry.Test.File,
+		Framework: entry.TestResults.Framework,
+		Duration:  entry.Test.Duration,
+	}
+}
+
+func limit(length, n int) int {
+	if n >= 0 && length > n {
+		return n
+	}
+
+	return length
+}
+
+// JSON renders stats as json
+func (s *Stats) JSON() ([]byte, error) {
+	return json.MarshalIndent(s, "", "  ")
+}
+
+// Text renders stats as human readable text
+func (s *Stats) Text() string {
+	var b strings.Builder
+	d := report.FormatDuration
+
+	fmt.Fprintf(&b, "%d tests, total duration %s\n", s.Tests, d(s.Duration))
+
+	b.WriteString("\nSlowest tests:\n")
+	for i, test := range s.SlowestTests {
+		fmt.Fprintf(&b, "  %2d. %10s  %s › %s\n", i+1, d(test.Duration), test.Suite, test.Name)
+	}
+
+	b.WriteString("\nSlowest suites:\n")
+	for i, suite := range s.SlowestSuites {
+		fmt.Fprintf(&b, "  %2d. %10s  %5.1f%%  %s (%d tests)\n", i+1, d(suite.Duration), suite.Share, suite.Name, suite.Tests)
+	}
+
+	if len(s.SlowestFiles) > 0 {
+		b.WriteString("\nSlowest files:\n")
+		for i, file := range s.SlowestFiles {
+			fmt.Fprintf(&b, "  %2d. %10s  %s (%d tests)\n", i+1, d(file.Duration), file.File, file.Tests)
+		}
+	}
+
+	b.WriteString("\nDuration distribution:\n")
+	for _, dist := range s.Distributions {
+		fmt.Fprintf(&b, "  %s: %d tests, p50 %s, p90 %s, p99 %s, max %s\n", frameworkName(dist.Framework), dist.Tests, d(dist.P50), d(dist.P90), d(dist.P99), d(dist.Max))
+	}
+
+	if s.HasBaseline {
+		fmt.Fprintf(&b, "\nSlower than baseline (%d):\n", len(s.Regressions))
+		for _, r := range s.Regressions {
+			fmt.Fprintf(&b, "  %s › %s: %s → %s (+%s, %s)\n", r.Suite, r.Name, d(r.BaseDuration), d(r.Duration), d(r.Delta), percent(r))
+		}
+	}
+
+	return b.String()
+}
+
+// Markdown renders stats as markdown
+func (s *Stats) Markdown() string {
+	var b strings.Builder
+	d := report.FormatDuration
+
+	b.WriteString("## ⏱️ Test Duration Stats\n\n")
+	fmt.Fprintf(&b, "**Tests:** `%d` | **🕒 Duration:** `%s`\n\n", s.Tests, d(s.Duration))
+
+	b.WriteString("### 🐢 Slowest tests\n\n| Test | Suite | Duration |\n| --- | --- | --- |\n")
+	for _, test := range s.SlowestTests {
+		fmt.Fprintf(&b, "| %s | %s | `%s` |\n", report.EscapeCell(test.Name), report.EscapeCell(test.Suite), d(test.Duration))
+	}
+
+	b.WriteString("\n### 🐌 Slowest suites\n\n| Suite | Tests | Duration | Share |\n| --- | --- | --- | --- |\n")
+	for _, suite := range s.SlowestSuites {
+		fmt.Fprintf(&b, "| %s | %d | `%s` | %.1f%% |\n", report.EscapeCell(suite.Name), suite.Tests, d(suite.Duration), suite.Share)
+	}
+
+	if len(s.SlowestFiles) > 0 {
+		b.WriteString("\n### 📄 Slowest files\n\n| File | Tests | Duration |\n| --- | --- | --- |\n")
+		for _, file := range s.SlowestFiles {
+			fmt.Fprintf(&b, "| %s | %d | `%s` |\n", report.EscapeCell(file.File), file.Tests, d(file.Duration))
+		}
+	}
+
+	b.WriteString("\n### 📊 Duration distribution\n\n| Framework | Tests | p50 | p90 | p99 | Max |\n| --- | --- | --- | --- | --- | --- |\n")
+	for _, dist := range s.Distributions {
+		fmt.Fprintf(&b, "| %s | %d | `%s` | `%s` | `%s` | `%s` |\n", frameworkName(dist.Framework), dist.Tests, d(dist.P50), d(dist.P90), d(dist.P99), d(dist.Max))
+	}
+
+	if s.HasBaseline {
+		fmt.Fprintf(&b, "\n### 📈 Slower than baseline (%d)\n\n", len(s.Regressions))
+		if len(s.Regressions) > 0 {
+			b.WriteString("| Test | Suite | Baseline | Current | Change |\n| --- | --- | --- | --- | --- |\n")
+			for _, r := range s.Regressions {
+				fmt.Fprintf(&b, "| %s | %s | `%s` | `%s` | +%s (%s) |\n", report.EscapeCell(r.Name), report.EscapeCell(r.Suite), d(r.BaseDuration), d(r.Duration), d(r.Delta), percent(r))
+			}
+		}
+	}
+
+	return b.String()
+}
+
+func percent(r Regression) string {
+	if r.BaseDuration == 0 {
+		return "new duration"
+	}
+
+	return fmt.Sprintf("+%.0f%%", r.Percent)
+}
+
+func frameworkName(framework string) string {
+	if framework == "" {
+		return "unknown"
+	}
+
+	return framework
+}
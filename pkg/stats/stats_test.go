@@ -0,0 +1,151 @@
+package stats_test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/semaphoreci/test-results/pkg/parser"
+	"github.com/semaphoreci/test-results/pkg/stats"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testCase struct {
+	name     string
+	file     string
+	duration time.Duration
+}
+
+func newTestResults(name, framework string, suites map[string][]testCase) parser.TestResults {
+	testResults := parser.NewTestResults()
+	testResults.Name = name
+	testResults.Framework = framework
+	testResults.EnsureID()
+
+	for _, suiteName := range []string{"suite-a", "suite-b"} {
+		tests, found := suites[suiteName]
+		if !found {
+			continue
+		}
+
+		suite := parser.NewSuite()
+		suite.Name = suiteName
+		suite.EnsureID(testResults)
+
+		for _, tc := range tests {
+			test := parser.NewTest()
+			test.Name = tc.name
+			test.File = tc.file
+			test.Duration = tc.duration
+			test.EnsureID(suite)
+			suite.AppendTest(test)
+		}
+
+		suite.Aggregate()
+		testResults.Suites = append(testResults.Suites, suite)
+	}
+
+	testResults.Aggregate()
+	return testResults
+}
+
+func newResult(slow time.Duration) parser.Result {
+	return parser.Result{TestResults: []parser.TestResults{
+		newTestResults("Unit", "rspec", map[string][]testCase{
+			"suite-a": {
+				{"fast", "spec/a_spec.rb", 100 * time.Millisecond},
+				{"slow", "spec/a_spec.rb", slow},
+			},
+			"suite-b": {
+				{"medium", "spec/b_spec.rb", 2 * time.Second},
+				{"tiny", "", 10 * time.Millisecond},
+			},
+		}),
+		newTestResults("Go", "golang", map[string][]testCase{
+			"suite-a": {{"TestA", "", 4 * time.Second}},
+		}),
+	}}
+}
+
+func Test_Compute(t *testing.T) {
+	options := stats.DefaultOptions()
+	options.Top = 2
+
+	s := stats.Compute(newResult(8*time.Second), nil, options)
+
+	assert.Equal(t, 5, s.Tests)
+	assert.Equal(t, 14110*time.Millisecond, s.Duration)
+
+	require.Len(t, s.SlowestTests, 2)
+	assert.Equal(t, "slow", s.SlowestTests[0].Name)
+	assert.Equal(t, "TestA", s.SlowestTests[1].Name)
+	assert.Equal(t, "golang", s.SlowestTests[1].Framework)
+
+	require.Len(t, s.SlowestSuites, 2)
+	assert.Equal(t, "suite-a", s.SlowestSuites[0].Name)
+	assert.Equal(t, "rspec", s.SlowestSuites[0].Framework)
+	assert.InDelta(t, 57.4, s.SlowestSuites[0].Share, 0.1)
+
+	assert.Equal(t, []stats.File{
+		{File: "spec/a_spec.rb", Tests: 2, Duration: 8100 * time.Millisecond},
+		{File: "spec/b_spec.rb", Tests: 1, Duration: 2 * time.Second},
+	}, s.SlowestFiles)
+
+	require.Len(t, s.Distributions, 2)
+	assert.Equal(t, stats.Distribution{Framework: "golang", Tests: 1, Total: 4 * time.Second, P50: 4 * time.Second, P90: 4 * time.Second, P99: 4 * time.Second, Max: 4 * time.Second}, s.Distributions[0])
+	assert.Equal(t, "rspec", s.Distributions[1].Framework)
+	assert.Equal(t, 100*time.Millisecond, s.Distributions[1].P50)
+	assert.Equal(t, 8*time.Second, s.Distributions[1].P90)
+
+	assert.False(t, s.HasBaseline)
+	assert.Nil(t, s.Regressions)
+	assert.NotContains(t, s.Markdown(), "Slower than baseline")
+}
+
+func Test_Compute_Baseline(t *testing.T) {
+	baseline := newResult(8 * time.Second)
+	head := newResult(10 * time.Second)
+	head.TestResults[0].Suites[1].Tests[0].Duration = 3 * time.Second
+	head.TestResults[0].Suites[1].Tests[1].Duration = 50 * time.Millisecond
+
+	s := stats.Compute(head, &baseline, stats.DefaultOptions())
+
+	require.Len(t, s.Regressions, 1, "small relative and absolute slowdowns, and tests below minimal duration are ignored")
+	assert.Equal(t, "medium", s.Regressions[0].Name)
+	assert.Equal(t, time.Second, s.Regressions[0].Delta)
+	assert.Equal(t, 50.0, s.Regressions[0].Percent)
+
+	options := stats.DefaultOptions()
+	options.SlowerPercent = 20
+	options.SlowerBy = 2 * time.Second
+	require.Len(t, stats.Compute(head, &baseline, options).Regressions, 1, "both thresholds have to be reached")
+
+	options.SlowerBy = time.Second
+	s = stats.Compute(head, &baseline, options)
+
+	require.Len(t, s.Regressions, 2)
+	assert.Equal(t, "slow", s.Regressions[0].Name)
+
+	assert.Contains(t, s.Text(), "Slower than baseline (2):\n  suite-a › slow: 8.00s → 10.00s (+2.00s, +25%)")
+	assert.Contains(t, s.Markdown(), "| slow | suite-a | `8.00s` | `10.00s` | +2.00s (+25%) |")
+
+	data, err := s.JSON()
+	require.NoError(t, err)
+	decoded := map[string]interface{}{}
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	assert.Len(t, decoded["regressions"], 2)
+}
+
+func Test_Percentile(t *testing.T) {
+	sorted := []time.Duration{}
+	for i := 1; i <= 100; i++ {
+		sorted = append(sorted, time.Duration(i))
+	}
+
+	assert.Equal(t, time.Duration(50), stats.Percentile(sorted, 50))
+	assert.Equal(t, time.Duration(90), stats.Percentile(sorted, 90))
+	assert.Equal(t, time.Duration(99), stats.Percentile(sorted, 99))
+	assert.Equal(t, time.Duration(1), stats.Percentile(sorted, 0))
+	assert.Equal(t, time.Duration(0), stats.Percentile(nil, 50))
+}
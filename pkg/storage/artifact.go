@@ -0,0 +1,72 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os/exec"
+	"strings"
+
+	"github.com/semaphoreci/test-results/pkg/logger"
+)
+
+// ArtifactCLI stores artifacts with Semaphore artifact CLI
+type ArtifactCLI struct {
+	Binary  string
+	Force   bool
+	Verbose bool
+
+	// command creates the process, overridden in tests with a fake artifact binary
+	command func(ctx context.Context, name string, args ...string) *exec.Cmd
+}
+
+// Push ...
+func (a *ArtifactCLI) Push(ctx context.Context, level, file, destination string) error {
+	args := []string{"push", level, file, "-d", destination}
+	if a.Verbose {
+		args = append(args, "-v")
+	}
+
+	if a.Force {
+		args = append(args, "-f")
+	}
+
+	return a.run(ctx, "Pushing", args)
+}
+
+// Pull ...
+func (a *ArtifactCLI) Pull(ctx context.Context, level, source, localPath string) error {
+	args := []string{"pull", level, source, "-d", localPath}
+	if a.Verbose {
+		args = append(args, "-v")
+	}
+
+	return a.run(ctx, "Pulling", args)
+}
+
+func (a *ArtifactCLI) run(ctx context.Context, action string, args []string) error {
+	newCommand := a.command
+	if newCommand == nil {
+		newCommand = exec.CommandContext
+	}
+
+	command := newCommand(ctx, a.Binary, args...)
+	output, err := command.CombinedOutput()
+
+	logger.Info("%s artifacts:\n$ %s", action, command.String())
+
+	switch {
+	case errors.Is(err, exec.ErrNotFound), errors.Is(err, fs.ErrNotExist):
+		return Permanent(err)
+	case ctx.Err() != nil:
+		return ctx.Err()
+	case err != nil && strings.Contains(strings.ToLower(string(output)), "already exists"):
+		// Artifact CLI refuses to overwrite without --force, retrying would fail the same way
+		return Permanent(fmt.Errorf("%v\n%s", err, string(output)))
+	case err != nil:
+		return fmt.Errorf("%v\n%s", err, string(output))
+	}
+
+	return nil
+}
@@ -0,0 +1,137 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/semaphoreci/test-results/pkg/logger"
+)
+
+// ManifestFile is the name of manifest written by DryRun
+const ManifestFile = "manifest.json"
+
+// Artifact describes a file which would be pushed to storage
+type Artifact struct {
+	Level       string `json:"level"`
+	Destination string `json:"destination"`
+	// Path is location of the copy relative to the dry run directory
+	Path        string `json:"path"`
+	Size        int64  `json:"size"`
+	Compression string `json:"compression"`
+	SHA256      string `json:"sha256"`
+}
+
+// Manifest lists artifacts of a dry run
+type Manifest struct {
+	Artifacts []Artifact `json:"artifacts"`
+}
+
+// DryRun copies pushed files into a local directory, laid out as <level>/<destination>, instead of uploading them
+type DryRun struct {
+	Root string
+
+	mu        sync.Mutex
+	artifacts map[string]Artifact
+}
+
+// Push ...
+func (d *DryRun) Push(ctx context.Context, level, file, destination string) error {
+	if _, found := levelEnv[level]; !found {
+		return Permanent(fmt.Errorf("invalid artifact level %q, use one of: %s", level, strings.Join(Levels, ", ")))
+	}
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	data, err := os.ReadFile(filepath.Clean(file))
+	if err != nil {
+		return err
+	}
+
+	rel := strings.TrimPrefix(path.Join(level, path.Clean("/"+destination)), "/")
+	target := filepath.Join(d.Root, filepath.FromSlash(rel))
+
+	logger.Info("Dry run: writing artifact %s to %s", file, target)
+	err = writeFile(bytes.NewReader(data), target)
+	if err != nil {
+		return err
+	}
+
+	sum := sha256.Sum256(data)
+	artifact := Artifact{
+		Level:       level,
+		Destination: destination,
+		Path:        rel,
+		Size:        int64(len(data)),
+		Compression: compression(data),
+		SHA256:      hex.EncodeToString(sum[:]),
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.artifacts == nil {
+		d.artifacts = map[string]Artifact{}
+	}
+	d.artifacts[rel] = artifact
+
+	return nil
+}
+
+// Pull is not supported, dry runs do not read from storage
+func (d *DryRun) Pull(ctx context.Context, level, source, localPath string) error {
+	return Permanent(errors.New("pulling artifacts is not supported in dry run"))
+}
+
+// Manifest returns pushed artifacts sorted by level and destination
+func (d *DryRun) Manifest() Manifest {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	manifest := Manifest{Artifacts: []Artifact{}}
+	for _, artifact := range d.artifacts {
+		manifest.Artifacts = append(manifest.Artifacts, artifact)
+	}
+
+	sort.Slice(manifest.Artifacts, func(i, j int) bool {
+		return manifest.Artifacts[i].Path < manifest.Artifacts[j].Path
+	})
+
+	return manifest
+}
+
+// WriteManifest writes manifest into the dry run directory and returns its path
+func (d *DryRun) WriteManifest() (string, error) {
+	data, err := json.MarshalIndent(d.Manifest(), "", "  ")
+	if err != nil {
+		return "", err
+	}
+
+	file := filepath.Join(d.Root, ManifestFile)
+	err = writeFile(bytes.NewReader(append(data, '\n')), file)
+	if err != nil {
+		return "", err
+	}
+
+	return file, nil
+}
+
+// compression detects compression of artifact from its content
+func compression(data []byte) string {
+	if len(data) >= 2 && data[0] == 0x1f && data[1] == 0x8b {
+		return "gzip"
+	}
+
+	return "none"
+}
@@ -0,0 +1,83 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/semaphoreci/test-results/pkg/logger"
+)
+
+// Local stores artifacts in a local directory, useful for self-hosted setups with a shared volume
+type Local struct {
+	Root  string
+	Force bool
+}
+
+// Push ...
+func (l *Local) Push(ctx context.Context, level, file, destination string) error {
+	key, err := Key(level, destination)
+	if err != nil {
+		return Permanent(err)
+	}
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	target := filepath.Join(l.Root, filepath.FromSlash(key))
+	if _, err := os.Stat(target); err == nil && !l.Force {
+		return Permanent(fmt.Errorf("artifact %s already exists, use force to overwrite it", key))
+	}
+
+	logger.Info("Pushing artifact %s to %s", file, target)
+	return copyFile(file, target)
+}
+
+// Pull ...
+func (l *Local) Pull(ctx context.Context, level, source, localPath string) error {
+	key, err := Key(level, source)
+	if err != nil {
+		return Permanent(err)
+	}
+
+	root := filepath.Join(l.Root, filepath.FromSlash(key))
+	info, err := os.Stat(root)
+	if err != nil {
+		return fmt.Errorf("artifact %s not found: %v", key, err)
+	}
+
+	logger.Info("Pulling artifact %s to %s", root, localPath)
+	if !info.IsDir() {
+		return copyFile(root, localPath)
+	}
+
+	return filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+
+		return copyFile(path, filepath.Join(localPath, rel))
+	})
+}
+
+func copyFile(from, to string) error {
+	in, err := os.Open(filepath.Clean(from))
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	return writeFile(in, to)
+}
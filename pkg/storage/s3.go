@@ -0,0 +1,340 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/semaphoreci/test-results/pkg/logger"
+)
+
+// S3 stores artifacts in an S3 compatible object storage, e.g. AWS S3 or MinIO.
+// Requests use path-style addressing and are signed with AWS Signature Version 4.
+type S3 struct {
+	Endpoint     string
+	Bucket       string
+	Prefix       string
+	Region       string
+	AccessKey    string
+	SecretKey    string
+	SessionToken string
+	Force        bool
+	Client       *http.Client
+
+	// now is used to sign requests, overridden in tests
+	now func() time.Time
+}
+
+// Push ...
+func (s *S3) Push(ctx context.Context, level, file, destination string) error {
+	key, err := s.key(level, destination)
+	if err != nil {
+		return Permanent(err)
+	}
+
+	data, err := os.ReadFile(filepath.Clean(file))
+	if err != nil {
+		return err
+	}
+
+	if !s.Force {
+		response, err := s.do(ctx, http.MethodHead, key, nil, nil)
+		if err != nil {
+			return err
+		}
+		response.Body.Close()
+
+		if response.StatusCode == http.StatusOK {
+			return Permanent(fmt.Errorf("artifact %s already exists, use force to overwrite it", key))
+		}
+	}
+
+	logger.Info("Pushing artifact %s to s3://%s/%s", file, s.Bucket, key)
+	response, err := s.do(ctx, http.MethodPut, key, nil, data)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	return checkResponse(response, key)
+}
+
+// Pull downloads a single object, or all objects under the prefix when source is a directory
+func (s *S3) Pull(ctx context.Context, level, source, localPath string) error {
+	key, err := s.key(level, source)
+	if err != nil {
+		return Permanent(err)
+	}
+
+	logger.Info("Pulling artifact s3://%s/%s to %s", s.Bucket, key, localPath)
+
+	response, err := s.do(ctx, http.MethodGet, key, nil, nil)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode == http.StatusOK {
+		return writeFile(response.Body, localPath)
+	}
+
+	if response.StatusCode != http.StatusNotFound {
+		return checkResponse(response, key)
+	}
+
+	keys, err := s.list(ctx, key+"/")
+	if err != nil {
+		return err
+	}
+
+	if len(keys) == 0 {
+		return fmt.Errorf("artifact %s not found", key)
+	}
+
+	for _, objectKey := range keys {
+		err = s.download(ctx, objectKey, filepath.Join(localPath, filepath.FromSlash(strings.TrimPrefix(objectKey, key+"/"))))
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *S3) key(level, name string) (string, error) {
+	key, err := Key(level, name)
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimPrefix(path.Join(s.Prefix, key), "/"), nil
+}
+
+func (s *S3) download(ctx context.Context, key, localPath string) error {
+	response, err := s.do(ctx, http.MethodGet, key, nil, nil)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	err = checkResponse(response, key)
+	if err != nil {
+		return err
+	}
+
+	return writeFile(response.Body, localPath)
+}
+
+type listBucketResult struct {
+	Contents []struct {
+		Key string `xml:"Key"`
+	} `xml:"Contents"`
+	IsTruncated           bool   `xml:"IsTruncated"`
+	NextContinuationToken string `xml:"NextContinuationToken"`
+}
+
+func (s *S3) list(ctx context.Context, prefix string) ([]string, error) {
+	keys := []string{}
+	token := ""
+
+	for {
+		query := url.Values{"list-type": {"2"}, "prefix": {prefix}}
+		if token != "" {
+			query.Set("continuation-token", token)
+		}
+
+		response, err := s.do(ctx, http.MethodGet, "", query, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		err = checkResponse(response, prefix)
+		if err != nil {
+			response.Body.Close()
+			return nil, err
+		}
+
+		result := listBucketResult{}
+		err = xml.NewDecoder(response.Body).Decode(&result)
+		response.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("listing %s failed: %v", prefix, err)
+		}
+
+		for _, object := range result.Contents {
+			keys = append(keys, object.Key)
+		}
+
+		if !result.IsTruncated || result.NextContinuationToken == "" {
+			return keys, nil
+		}
+		token = result.NextContinuationToken
+	}
+}
+
+func (s *S3) do(ctx context.Context, method, key string, query url.Values, body []byte) (*http.Response, error) {
+	u, err := url.Parse(s.Endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("invalid S3 endpoint %q: %v", s.Endpoint, err)
+	}
+
+	u.Path = "/" + s.Bucket
+	if key != "" {
+		u.Path += "/" + key
+	}
+	u.RawPath = canonicalURI(u.Path)
+	u.RawQuery = canonicalQuery(query)
+
+	request, err := http.NewRequestWithContext(ctx, method, u.String(), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+
+	s.sign(request, body)
+
+	client := s.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	return client.Do(request)
+}
+
+// sign signs request with AWS Signature Version 4, see https://docs.aws.amazon.com/IAM/latest/UserGuide/create-signed-request.html
+func (s *S3) sign(request *http.Request, body []byte) {
+	now := time.Now
+	if s.now != nil {
+		now = s.now
+	}
+
+	t := now().UTC()
+	date := t.Format("20060102")
+	timestamp := t.Format("20060102T150405Z")
+	payloadHash := sha256Hex(body)
+
+	request.Header.Set("Host", request.URL.Host)
+	request.Header.Set("X-Amz-Date", timestamp)
+	request.Header.Set("X-Amz-Content-Sha256", payloadHash)
+	if s.SessionToken != "" {
+		request.Header.Set("X-Amz-Security-Token", s.SessionToken)
+	}
+
+	headers := []string{}
+	for name := range request.Header {
+		headers = append(headers, strings.ToLower(name))
+	}
+	sort.Strings(headers)
+
+	canonicalHeaders := ""
+	for _, name := range headers {
+		canonicalHeaders += name + ":" + strings.TrimSpace(request.Header.Get(name)) + "\n"
+	}
+	signedHeaders := strings.Join(headers, ";")
+
+	canonicalRequest := strings.Join([]string{
+		request.Method,
+		canonicalURI(request.URL.Path),
+		request.URL.RawQuery,
+		canonicalHeaders,
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := date + "/" + s.Region + "/s3/aws4_request"
+	stringToSign := strings.Join([]string{"AWS4-HMAC-SHA256", timestamp, scope, sha256Hex([]byte(canonicalRequest))}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+s.SecretKey), date)
+	key = hmacSHA256(key, s.Region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	request.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s", s.AccessKey, scope, signedHeaders, signature))
+}
+
+// canonicalURI encodes each segment of path as required by SigV4, escaping everything except unreserved characters
+func canonicalURI(path string) string {
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		escaped := strings.Builder{}
+		for _, b := range []byte(segment) {
+			if unreserved(b) {
+				escaped.WriteByte(b)
+			} else {
+				fmt.Fprintf(&escaped, "%%%02X", b)
+			}
+		}
+		segments[i] = escaped.String()
+	}
+
+	return strings.Join(segments, "/")
+}
+
+func unreserved(b byte) bool {
+	return 'A' <= b && b <= 'Z' || 'a' <= b && b <= 'z' || '0' <= b && b <= '9' || b == '-' || b == '.' || b == '_' || b == '~'
+}
+
+// canonicalQuery encodes query sorted by keys, with spaces encoded as %20 as required by SigV4
+func canonicalQuery(query url.Values) string {
+	return strings.ReplaceAll(query.Encode(), "+", "%20")
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
+
+func checkResponse(response *http.Response, key string) error {
+	if response.StatusCode >= 200 && response.StatusCode < 300 {
+		return nil
+	}
+
+	body, _ := io.ReadAll(io.LimitReader(response.Body, 1024))
+	err := fmt.Errorf("request for %s failed with %s: %s", key, response.Status, strings.TrimSpace(string(body)))
+
+	// Client errors are not fixed by retrying, except for timeouts and rate limiting
+	if response.StatusCode < 500 && response.StatusCode != http.StatusRequestTimeout && response.StatusCode != http.StatusTooManyRequests {
+		return Permanent(err)
+	}
+
+	return err
+}
+
+func writeFile(reader io.Reader, to string) error {
+	err := os.MkdirAll(filepath.Dir(to), 0755)
+	if err != nil {
+		return err
+	}
+
+	out, err := os.Create(filepath.Clean(to))
+	if err != nil {
+		return err
+	}
+
+	_, err = io.Copy(out, reader)
+	if err != nil {
+		out.Close()
+		return err
+	}
+
+	return out.Close()
+}
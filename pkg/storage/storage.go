@@ -0,0 +1,148 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/url"
+	"os"
+	"path"
+	"strings"
+)
+
+// Levels lists artifact levels, matching levels of Semaphore artifact CLI
+var Levels = []string{"job", "workflow", "project"}
+
+// levelEnv maps levels to environment variables identifying them
+var levelEnv = map[string]string{
+	"job":      "SEMAPHORE_JOB_ID",
+	"workflow": "SEMAPHORE_WORKFLOW_ID",
+	"project":  "SEMAPHORE_PROJECT_ID",
+}
+
+// Storage stores artifacts at job, workflow or project level
+type Storage interface {
+	// Push uploads local file to destination path
+	Push(ctx context.Context, level, file, destination string) error
+	// Pull downloads remote file or directory into local path
+	Pull(ctx context.Context, level, source, localPath string) error
+}
+
+// PermanentError marks failures that are not fixed by retrying, e.g. missing files or existing artifacts
+type PermanentError struct {
+	Err error
+}
+
+func (e *PermanentError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *PermanentError) Unwrap() error {
+	return e.Err
+}
+
+// Permanent wraps err as PermanentError
+func Permanent(err error) error {
+	return &PermanentError{Err: err}
+}
+
+// Retryable checks if failed call can be retried. Permanent errors, missing files
+// and cancellation of the parent context are not retried, timeouts of a single call are.
+func Retryable(ctx context.Context, err error) bool {
+	var permanent *PermanentError
+	if errors.As(err, &permanent) || errors.Is(err, fs.ErrNotExist) {
+		return false
+	}
+
+	return ctx.Err() == nil
+}
+
+// Options ...
+type Options struct {
+	// Force overwrites existing artifacts
+	Force bool
+	// Verbose enables verbose output of the backend
+	Verbose bool
+}
+
+// New creates storage from URL:
+//
+//	artifact                                  Semaphore artifact CLI, the default
+//	dir:///var/lib/test-results               local directory
+//	s3://bucket/prefix?endpoint=http://minio:9000&region=eu-west-1
+//
+// S3 credentials are read from AWS_ACCESS_KEY_ID, AWS_SECRET_ACCESS_KEY and AWS_SESSION_TOKEN.
+func New(rawURL string, options Options) (Storage, error) {
+	if rawURL == "" || rawURL == "artifact" {
+		return &ArtifactCLI{Binary: "artifact", Force: options.Force, Verbose: options.Verbose}, nil
+	}
+
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid storage %q: %v", rawURL, err)
+	}
+
+	switch u.Scheme {
+	case "dir", "file":
+		root := u.Path
+		if u.Host != "" {
+			// dir://relative/path
+			root = path.Join(u.Host, u.Path)
+		}
+		if root == "" {
+			return nil, fmt.Errorf("invalid storage %q: directory is missing", rawURL)
+		}
+		return &Local{Root: root, Force: options.Force}, nil
+	case "s3":
+		if u.Host == "" {
+			return nil, fmt.Errorf("invalid storage %q: bucket is missing", rawURL)
+		}
+
+		region := u.Query().Get("region")
+		if region == "" {
+			region = os.Getenv("AWS_REGION")
+		}
+		if region == "" {
+			region = "us-east-1"
+		}
+
+		endpoint := u.Query().Get("endpoint")
+		if endpoint == "" {
+			endpoint = os.Getenv("AWS_ENDPOINT_URL")
+		}
+		if endpoint == "" {
+			endpoint = "https://s3." + region + ".amazonaws.com"
+		}
+
+		return &S3{
+			Endpoint:     strings.TrimSuffix(endpoint, "/"),
+			Bucket:       u.Host,
+			Prefix:       strings.Trim(u.Path, "/"),
+			Region:       region,
+			AccessKey:    os.Getenv("AWS_ACCESS_KEY_ID"),
+			SecretKey:    os.Getenv("AWS_SECRET_ACCESS_KEY"),
+			SessionToken: os.Getenv("AWS_SESSION_TOKEN"),
+			Force:        options.Force,
+		}, nil
+	}
+
+	return nil, fmt.Errorf("unsupported storage %q, use one of: artifact, dir://<path>, s3://<bucket>", rawURL)
+}
+
+// Key returns location of an artifact relative to storage root, e.g. workflows/<workflow-id>/test-results/junit.json.
+// Artifacts are scoped by the ID of their level, taken from Semaphore environment variables.
+func Key(level, name string) (string, error) {
+	env, found := levelEnv[level]
+	if !found {
+		return "", fmt.Errorf("invalid artifact level %q, use one of: %s", level, strings.Join(Levels, ", "))
+	}
+
+	id := os.Getenv(env)
+	if id == "" {
+		return "", fmt.Errorf("%s env is missing, it is required to store %s artifacts", env, level)
+	}
+
+	key := path.Join(level+"s", id, path.Clean("/"+name))
+	return strings.TrimPrefix(key, "/"), nil
+}
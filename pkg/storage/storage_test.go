@@ -0,0 +1,229 @@
+package storage_test
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/semaphoreci/test-results/pkg/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_New(t *testing.T) {
+	t.Setenv("AWS_REGION", "")
+	t.Setenv("AWS_ENDPOINT_URL", "")
+
+	s, err := storage.New("", storage.Options{Force: true})
+	require.NoError(t, err)
+	assert.Equal(t, &storage.ArtifactCLI{Binary: "artifact", Force: true}, s)
+
+	s, err = storage.New("dir:///var/lib/results", storage.Options{})
+	require.NoError(t, err)
+	assert.Equal(t, &storage.Local{Root: "/var/lib/results"}, s)
+
+	s, err = storage.New("dir://results", storage.Options{})
+	require.NoError(t, err)
+	assert.Equal(t, &storage.Local{Root: "results"}, s)
+
+	s, err = storage.New("s3://bucket/ci/results?endpoint=http://minio:9000/&region=eu-west-1", storage.Options{})
+	require.NoError(t, err)
+	s3 := s.(*storage.S3)
+	assert.Equal(t, "http://minio:9000", s3.Endpoint)
+	assert.Equal(t, "bucket", s3.Bucket)
+	assert.Equal(t, "ci/results", s3.Prefix)
+	assert.Equal(t, "eu-west-1", s3.Region)
+
+	s, err = storage.New("s3://bucket", storage.Options{})
+	require.NoError(t, err)
+	assert.Equal(t, "https://s3.us-east-1.amazonaws.com", s.(*storage.S3).Endpoint)
+
+	for _, invalid := range []string{"ftp://host", "dir://", "s3:///prefix"} {
+		_, err = storage.New(invalid, storage.Options{})
+		assert.Error(t, err, invalid)
+	}
+}
+
+func Test_Key(t *testing.T) {
+	t.Setenv("SEMAPHORE_WORKFLOW_ID", "wf-1")
+	t.Setenv("SEMAPHORE_JOB_ID", "")
+
+	key, err := storage.Key("workflow", "test-results/pipeline.json")
+	require.NoError(t, err)
+	assert.Equal(t, "workflows/wf-1/test-results/pipeline.json", key)
+
+	key, err = storage.Key("workflow", "../../escape.json")
+	require.NoError(t, err)
+	assert.Equal(t, "workflows/wf-1/escape.json", key)
+
+	_, err = storage.Key("job", "test-results/junit.json")
+	assert.Error(t, err, "empty job ID must not collapse the key")
+
+	_, err = storage.Key("pipeline", "junit.json")
+	assert.Error(t, err)
+}
+
+// testStorage pushes two files of a pipeline and pulls them back as a directory
+func testStorage(t *testing.T, s storage.Storage, force func(bool)) {
+	t.Setenv("SEMAPHORE_WORKFLOW_ID", "wf-1")
+	ctx := context.Background()
+
+	dir := t.TempDir()
+	for name, content := range map[string]string{"job-1.json": "first", "job-2.json": "second"} {
+		file := filepath.Join(dir, name)
+		require.NoError(t, os.WriteFile(file, []byte(content), 0600))
+		require.NoError(t, s.Push(ctx, "workflow", file, "test-results/pipeline-1/"+name))
+	}
+
+	file := filepath.Join(dir, "job-1.json")
+	assert.Error(t, s.Push(ctx, "workflow", file, "test-results/pipeline-1/job-1.json"), "existing artifacts should not be overwritten")
+	force(true)
+	assert.NoError(t, s.Push(ctx, "workflow", file, "test-results/pipeline-1/job-1.json"))
+
+	out := filepath.Join(t.TempDir(), "pulled")
+	require.NoError(t, s.Pull(ctx, "workflow", "test-results/pipeline-1", out))
+
+	data, err := os.ReadFile(filepath.Join(out, "job-2.json"))
+	require.NoError(t, err)
+	assert.Equal(t, "second", string(data))
+
+	single := filepath.Join(t.TempDir(), "single.json")
+	require.NoError(t, s.Pull(ctx, "workflow", "test-results/pipeline-1/job-1.json", single))
+	data, err = os.ReadFile(single)
+	require.NoError(t, err)
+	assert.Equal(t, "first", string(data))
+
+	assert.Error(t, s.Pull(ctx, "workflow", "test-results/missing", out))
+}
+
+func Test_Local(t *testing.T) {
+	s := &storage.Local{Root: t.TempDir()}
+	testStorage(t, s, func(force bool) { s.Force = force })
+
+	_, err := os.Stat(filepath.Join(s.Root, "workflows", "wf-1", "test-results", "pipeline-1", "job-1.json"))
+	assert.NoError(t, err)
+}
+
+// fakeS3 is an in-memory stand-in for S3 compatible storage
+type fakeS3 struct {
+	mu      sync.Mutex
+	objects map[string][]byte
+}
+
+func (f *fakeS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	body, _ := io.ReadAll(r.Body)
+	sum := sha256.Sum256(body)
+	if !strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=access/") || r.Header.Get("X-Amz-Content-Sha256") != hex.EncodeToString(sum[:]) {
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+
+	key := strings.TrimPrefix(r.URL.Path, "/bucket/")
+	switch {
+	case r.Method == http.MethodGet && r.URL.Query().Get("list-type") == "2":
+		result := struct {
+			XMLName  xml.Name `xml:"ListBucketResult"`
+			Contents []struct {
+				Key string `xml:"Key"`
+			} `xml:"Contents"`
+		}{}
+
+		keys := []string{}
+		for k := range f.objects {
+			if strings.HasPrefix(k, r.URL.Query().Get("prefix")) {
+				keys = append(keys, k)
+			}
+		}
+		sort.Strings(keys)
+
+		for _, k := range keys {
+			result.Contents = append(result.Contents, struct {
+				Key string `xml:"Key"`
+			}{k})
+		}
+		_ = xml.NewEncoder(w).Encode(result)
+	case r.Method == http.MethodPut:
+		f.objects[key] = body
+	case r.Method == http.MethodGet || r.Method == http.MethodHead:
+		data, found := f.objects[key]
+		if !found {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write(data)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func Test_S3(t *testing.T) {
+	fake := &fakeS3{objects: map[string][]byte{}}
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	s := &storage.S3{Endpoint: server.URL, Bucket: "bucket", Prefix: "ci", Region: "us-east-1", AccessKey: "access", SecretKey: "secret"}
+	testStorage(t, s, func(force bool) { s.Force = force })
+
+	assert.Contains(t, fake.objects, "ci/workflows/wf-1/test-results/pipeline-1/job-1.json")
+
+	ctx := context.Background()
+	s.AccessKey = "wrong"
+	assert.Error(t, s.Push(ctx, "workflow", filepath.Join(t.TempDir(), "missing.json"), "x.json"))
+	assert.Error(t, s.Pull(ctx, "workflow", "test-results/pipeline-1/job-1.json", filepath.Join(t.TempDir(), "x.json")))
+}
+
+func Test_DryRun(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	s := &storage.DryRun{Root: filepath.Join(dir, "dry-run")}
+
+	plain := filepath.Join(dir, "junit.xml")
+	require.NoError(t, os.WriteFile(plain, []byte("<testsuites/>"), 0600))
+	gzipped := filepath.Join(dir, "junit.json")
+	require.NoError(t, os.WriteFile(gzipped, []byte{0x1f, 0x8b, 0x08, 0x00}, 0600))
+
+	uploads := []storage.Upload{
+		{Level: "workflow", File: gzipped, Destination: "test-results/pipeline-1/job-1.json"},
+		{Level: "job", File: plain, Destination: "test-results/junit.xml"},
+		{Level: "job", File: gzipped, Destination: "test-results/junit.json"},
+	}
+	report := (&storage.Uploader{Storage: s, Concurrency: 2}).Upload(ctx, uploads)
+	require.NoError(t, report.Err())
+
+	data, err := os.ReadFile(filepath.Join(s.Root, "job", "test-results", "junit.xml"))
+	require.NoError(t, err)
+	assert.Equal(t, "<testsuites/>", string(data))
+
+	manifest := s.Manifest()
+	require.Len(t, manifest.Artifacts, 3)
+	assert.Equal(t, storage.Artifact{
+		Level:       "job",
+		Destination: "test-results/junit.json",
+		Path:        "job/test-results/junit.json",
+		Size:        4,
+		Compression: "gzip",
+		SHA256:      "fd72d30440b0bae1b1c6db6c8ad807f238ef3ca613aa7e8d5329e1e8ddf7da72",
+	}, manifest.Artifacts[0])
+	assert.Equal(t, "none", manifest.Artifacts[1].Compression)
+	assert.Equal(t, "workflow/test-results/pipeline-1/job-1.json", manifest.Artifacts[2].Path)
+
+	file, err := s.WriteManifest()
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(s.Root, storage.ManifestFile), file)
+
+	assert.Error(t, s.Push(ctx, "pipeline", plain, "junit.xml"))
+	assert.Error(t, s.Pull(ctx, "job", "test-results", dir))
+}
@@ -0,0 +1,155 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Upload describes a single file pushed to storage
+type Upload struct {
+	Level       string
+	File        string
+	Destination string
+}
+
+// UploadResult is the outcome of an upload after all attempts
+type UploadResult struct {
+	Upload
+	Attempts int
+	Duration time.Duration
+	Err      error
+}
+
+// UploadReport lists results in the order uploads were given
+type UploadReport struct {
+	Results []UploadResult
+}
+
+// Failed returns results of failed uploads
+func (r UploadReport) Failed() []UploadResult {
+	failed := []UploadResult{}
+	for _, result := range r.Results {
+		if result.Err != nil {
+			failed = append(failed, result)
+		}
+	}
+
+	return failed
+}
+
+// Err returns an error summarizing failed uploads, nil when all of them succeeded
+func (r UploadReport) Err() error {
+	failed := r.Failed()
+	if len(failed) == 0 {
+		return nil
+	}
+
+	return fmt.Errorf("%d of %d artifact(s) failed to upload, first error: %s: %v", len(failed), len(r.Results), failed[0].Destination, failed[0].Err)
+}
+
+// Uploader pushes files to storage with bounded concurrency, retrying retryable failures with exponential backoff
+type Uploader struct {
+	Storage Storage
+	// Concurrency limits number of parallel uploads, defaults to 1
+	Concurrency int
+	// Retries is the number of additional attempts after a failed one
+	Retries int
+	// Timeout limits a single attempt, 0 means no limit
+	Timeout time.Duration
+	// Backoff is the delay before the first retry, doubled after each attempt up to MaxBackoff
+	Backoff    time.Duration
+	MaxBackoff time.Duration
+
+	// sleep waits between attempts, overridden in tests
+	sleep func(ctx context.Context, d time.Duration) error
+}
+
+// Upload pushes all uploads and waits for them to finish.
+// Cancelling ctx stops pending uploads and retries, their results carry the context error.
+func (u *Uploader) Upload(ctx context.Context, uploads []Upload) UploadReport {
+	report := UploadReport{Results: make([]UploadResult, len(uploads))}
+
+	concurrency := u.Concurrency
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	queue := make(chan int)
+	wg := sync.WaitGroup{}
+	for i := 0; i < concurrency && i < len(uploads); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for idx := range queue {
+				report.Results[idx] = u.upload(ctx, uploads[idx])
+			}
+		}()
+	}
+
+	for idx := range uploads {
+		queue <- idx
+	}
+	close(queue)
+	wg.Wait()
+
+	return report
+}
+
+func (u *Uploader) upload(ctx context.Context, upload Upload) UploadResult {
+	result := UploadResult{Upload: upload}
+	start := time.Now()
+	backoff := u.Backoff
+
+	for {
+		if err := ctx.Err(); err != nil {
+			result.Err = err
+			break
+		}
+
+		result.Attempts++
+		result.Err = u.attempt(ctx, upload)
+		if result.Err == nil || result.Attempts > u.Retries || !Retryable(ctx, result.Err) {
+			break
+		}
+
+		if err := u.wait(ctx, backoff); err != nil {
+			break
+		}
+
+		backoff *= 2
+		if u.MaxBackoff > 0 && backoff > u.MaxBackoff {
+			backoff = u.MaxBackoff
+		}
+	}
+
+	result.Duration = time.Since(start)
+	return result
+}
+
+func (u *Uploader) attempt(ctx context.Context, upload Upload) error {
+	if u.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, u.Timeout)
+		defer cancel()
+	}
+
+	return u.Storage.Push(ctx, upload.Level, upload.File, upload.Destination)
+}
+
+func (u *Uploader) wait(ctx context.Context, d time.Duration) error {
+	if u.sleep != nil {
+		return u.sleep(ctx, d)
+	}
+
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
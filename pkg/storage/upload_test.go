@@ -0,0 +1,247 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestHelperProcess acts as a fake artifact CLI when started by fakeArtifact.
+// Pushed files are copied into FAKE_ARTIFACT_DIR, destinations encode the behaviour:
+// "flaky-N" fails first N attempts, "slow" never finishes in time and "existing" is already stored, so it is pushed only with -f.
+func TestHelperProcess(t *testing.T) {
+	if os.Getenv("GO_WANT_HELPER_PROCESS") != "1" {
+		return
+	}
+
+	args := os.Args
+	for len(args) > 0 && args[0] != "--" {
+		args = args[1:]
+	}
+	// -- artifact push <level> <file> -d <destination> [-f]
+	if len(args) < 7 || args[2] != "push" {
+		fmt.Fprintf(os.Stderr, "unexpected arguments: %v\n", args)
+		os.Exit(2)
+	}
+	file, destination := args[4], args[6]
+	dir := os.Getenv("FAKE_ARTIFACT_DIR")
+
+	if strings.HasPrefix(destination, "slow") {
+		time.Sleep(10 * time.Second)
+	}
+
+	if strings.HasPrefix(destination, "existing") && args[len(args)-1] != "-f" {
+		fmt.Fprintf(os.Stderr, "Error: file '%s' already exists; use --force flag to overwrite\n", destination)
+		os.Exit(1)
+	}
+
+	if name, n, found := strings.Cut(destination, "flaky-"); found {
+		failures, _ := strconv.Atoi(strings.TrimSuffix(n, ".json"))
+		counter := filepath.Join(dir, name+"flaky.count")
+		data, _ := os.ReadFile(counter)
+		attempt, _ := strconv.Atoi(string(data))
+		_ = os.WriteFile(counter, []byte(strconv.Itoa(attempt+1)), 0600)
+		if attempt < failures {
+			fmt.Fprintln(os.Stderr, "error: 503 Service Unavailable")
+			os.Exit(1)
+		}
+	}
+
+	data, err := os.ReadFile(file)
+	if err == nil {
+		err = os.WriteFile(filepath.Join(dir, filepath.Base(destination)), data, 0600)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	os.Exit(0)
+}
+
+// fakeArtifact returns artifact CLI backed by TestHelperProcess, storing pushed files into dir
+func fakeArtifact(dir string) *ArtifactCLI {
+	return &ArtifactCLI{
+		Binary: "artifact",
+		command: func(ctx context.Context, name string, args ...string) *exec.Cmd {
+			cs := append([]string{"-test.run=TestHelperProcess", "--", name}, args...)
+			command := exec.CommandContext(ctx, os.Args[0], cs...) // #nosec
+			command.Env = append(os.Environ(), "GO_WANT_HELPER_PROCESS=1", "FAKE_ARTIFACT_DIR="+dir)
+			return command
+		},
+	}
+}
+
+func noSleep(ctx context.Context, d time.Duration) error {
+	return ctx.Err()
+}
+
+func Test_Uploader_Retries(t *testing.T) {
+	store := t.TempDir()
+	file := filepath.Join(t.TempDir(), "junit.json")
+	require.NoError(t, os.WriteFile(file, []byte("{}"), 0600))
+
+	delays := []time.Duration{}
+	uploader := &Uploader{
+		Storage:    fakeArtifact(store),
+		Retries:    3,
+		Backoff:    time.Second,
+		MaxBackoff: 3 * time.Second,
+		sleep: func(ctx context.Context, d time.Duration) error {
+			delays = append(delays, d)
+			return nil
+		},
+	}
+
+	report := uploader.Upload(context.Background(), []Upload{
+		{Level: "job", File: file, Destination: "a-flaky-2.json"},
+		{Level: "job", File: file, Destination: "b-flaky-5.json"},
+	})
+
+	require.Len(t, report.Results, 2)
+	assert.NoError(t, report.Results[0].Err)
+	assert.Equal(t, 3, report.Results[0].Attempts)
+	assert.FileExists(t, filepath.Join(store, "a-flaky-2.json"))
+
+	assert.Error(t, report.Results[1].Err)
+	assert.Equal(t, 4, report.Results[1].Attempts)
+	assert.Contains(t, report.Results[1].Err.Error(), "503 Service Unavailable")
+
+	assert.Equal(t, []time.Duration{time.Second, 2 * time.Second, time.Second, 2 * time.Second, 3 * time.Second}, delays)
+
+	require.Len(t, report.Failed(), 1)
+	assert.Equal(t, "b-flaky-5.json", report.Failed()[0].Destination)
+	assert.EqualError(t, report.Err(), "1 of 2 artifact(s) failed to upload, first error: b-flaky-5.json: "+report.Results[1].Err.Error())
+}
+
+func Test_Uploader_Timeout(t *testing.T) {
+	store := t.TempDir()
+	file := filepath.Join(t.TempDir(), "junit.json")
+	require.NoError(t, os.WriteFile(file, []byte("{}"), 0600))
+
+	uploader := &Uploader{Storage: fakeArtifact(store), Retries: 1, Timeout: 200 * time.Millisecond, sleep: noSleep}
+	report := uploader.Upload(context.Background(), []Upload{{Level: "job", File: file, Destination: "slow.json"}})
+
+	assert.ErrorIs(t, report.Results[0].Err, context.DeadlineExceeded)
+	assert.Equal(t, 2, report.Results[0].Attempts, "timed out attempts should be retried")
+	assert.Less(t, report.Results[0].Duration, 5*time.Second)
+}
+
+func Test_Uploader_Permanent(t *testing.T) {
+	uploader := &Uploader{Storage: &ArtifactCLI{Binary: filepath.Join(t.TempDir(), "missing-artifact")}, Retries: 3, sleep: noSleep}
+	report := uploader.Upload(context.Background(), []Upload{{Level: "job", File: "junit.json", Destination: "junit.json"}})
+	assert.Error(t, report.Results[0].Err)
+	assert.Equal(t, 1, report.Results[0].Attempts)
+
+	store := t.TempDir()
+	file := filepath.Join(t.TempDir(), "junit.json")
+	require.NoError(t, os.WriteFile(file, []byte("{}"), 0600))
+	uploader = &Uploader{Storage: fakeArtifact(store), Retries: 3, sleep: noSleep}
+	report = uploader.Upload(context.Background(), []Upload{{Level: "job", File: file, Destination: "existing.json"}})
+	assert.ErrorContains(t, report.Results[0].Err, "already exists")
+	assert.Equal(t, 1, report.Results[0].Attempts)
+
+	forced := fakeArtifact(store)
+	forced.Force = true
+	uploader = &Uploader{Storage: forced, Retries: 3, sleep: noSleep}
+	report = uploader.Upload(context.Background(), []Upload{{Level: "job", File: file, Destination: "existing.json"}})
+	assert.NoError(t, report.Results[0].Err)
+	assert.FileExists(t, filepath.Join(store, "existing.json"))
+
+	t.Setenv("SEMAPHORE_JOB_ID", "job-1")
+	local := &Local{Root: t.TempDir()}
+	uploader = &Uploader{Storage: local, Retries: 3, sleep: noSleep}
+	report = uploader.Upload(context.Background(), []Upload{{Level: "job", File: filepath.Join(t.TempDir(), "missing.json"), Destination: "junit.json"}})
+	assert.ErrorIs(t, report.Results[0].Err, os.ErrNotExist)
+	assert.Equal(t, 1, report.Results[0].Attempts)
+}
+
+// countingStorage records the maximum number of concurrent pushes
+type countingStorage struct {
+	mu      sync.Mutex
+	active  int
+	max     int
+	pushed  []string
+	release chan struct{}
+}
+
+func (s *countingStorage) Push(ctx context.Context, level, file, destination string) error {
+	s.mu.Lock()
+	s.active++
+	if s.active > s.max {
+		s.max = s.active
+	}
+	s.mu.Unlock()
+
+	defer func() {
+		s.mu.Lock()
+		s.active--
+		s.pushed = append(s.pushed, destination)
+		s.mu.Unlock()
+	}()
+
+	select {
+	case <-s.release:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (s *countingStorage) Pull(ctx context.Context, level, source, localPath string) error {
+	return errors.New("not implemented")
+}
+
+func Test_Uploader_Concurrency(t *testing.T) {
+	s := &countingStorage{release: make(chan struct{})}
+	uploads := []Upload{}
+	for i := 0; i < 10; i++ {
+		uploads = append(uploads, Upload{Level: "job", File: "junit.json", Destination: fmt.Sprintf("junit-%d.xml", i)})
+	}
+
+	go func() {
+		for i := 0; i < len(uploads); i++ {
+			time.Sleep(5 * time.Millisecond)
+			s.release <- struct{}{}
+		}
+	}()
+
+	report := (&Uploader{Storage: s, Concurrency: 3}).Upload(context.Background(), uploads)
+	assert.NoError(t, report.Err())
+	assert.Equal(t, 3, s.max)
+	assert.Len(t, s.pushed, 10)
+	for i, result := range report.Results {
+		assert.Equal(t, uploads[i].Destination, result.Destination, "results should keep order of uploads")
+		assert.Equal(t, 1, result.Attempts)
+	}
+}
+
+func Test_Uploader_Cancel(t *testing.T) {
+	s := &countingStorage{release: make(chan struct{})}
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(50*time.Millisecond, cancel)
+
+	uploader := &Uploader{Storage: s, Concurrency: 2, Retries: 5}
+	report := uploader.Upload(ctx, []Upload{
+		{Level: "job", File: "a", Destination: "a"},
+		{Level: "job", File: "b", Destination: "b"},
+		{Level: "job", File: "c", Destination: "c"},
+	})
+
+	require.Len(t, report.Failed(), 3)
+	for _, result := range report.Results {
+		assert.ErrorIs(t, result.Err, context.Canceled)
+		assert.LessOrEqual(t, result.Attempts, 1, "cancelled uploads should not be retried")
+	}
+	assert.Len(t, s.pushed, 2)
+}
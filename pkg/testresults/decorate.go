@@ -0,0 +1,60 @@
+package testresults
+
+import (
+	"context"
+
+	"github.com/semaphoreci/test-results/pkg/parser"
+	"github.com/semaphoreci/test-results/pkg/redact"
+	"github.com/semaphoreci/test-results/pkg/trim"
+)
+
+// DecorateOptions ...
+type DecorateOptions struct {
+	// OmitOutputForPassed drops stdout and stderr of passed tests
+	OmitOutputForPassed bool
+	// Redactor redacts secrets in outputs and failures, nil disables redaction
+	Redactor *redact.Redactor
+	// Trim limits size of outputs and failure bodies
+	Trim trim.Options
+}
+
+// DecorateStats describes changes made by Decorate
+type DecorateStats struct {
+	Redactions []redact.Count
+	Trim       trim.Stats
+}
+
+// Decorate drops, redacts and trims outputs of tests in place
+func Decorate(ctx context.Context, result *parser.Result, options DecorateOptions) (DecorateStats, error) {
+	stats := DecorateStats{}
+	if err := ctx.Err(); err != nil {
+		return stats, err
+	}
+
+	if options.OmitOutputForPassed {
+		for idx := range result.TestResults {
+			for suiteIdx := range result.TestResults[idx].Suites {
+				for caseIdx := range result.TestResults[idx].Suites[suiteIdx].Tests {
+					if result.TestResults[idx].Suites[suiteIdx].Tests[caseIdx].State == "passed" {
+						result.TestResults[idx].Suites[suiteIdx].Tests[caseIdx].SystemErr = ""
+						result.TestResults[idx].Suites[suiteIdx].Tests[caseIdx].SystemOut = ""
+					}
+				}
+			}
+		}
+	}
+
+	// Secrets are redacted before trimming, trimmed secrets would not be detected
+	if options.Redactor != nil {
+		stats.Redactions = options.Redactor.Apply(result)
+	}
+
+	stats.Trim = trim.Apply(result, options.Trim)
+	return stats, nil
+}
+
+// TrimTotal caps combined size of outputs in result to options.Trim.Total, per-test budgets are not applied.
+// ParseEach leaves the cap out, it is applied once to the merged result.
+func TrimTotal(result *parser.Result, options DecorateOptions) trim.Stats {
+	return trim.Apply(result, trim.Options{Total: options.Trim.Total})
+}
@@ -0,0 +1,200 @@
+package testresults
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/semaphoreci/test-results/pkg/logger"
+	"github.com/semaphoreci/test-results/pkg/parser"
+)
+
+// WriteOptions ...
+type WriteOptions struct {
+	// Compress writes report compressed with gzip
+	Compress bool
+}
+
+// FindFiles returns sorted paths of files with extension ext, directories are searched recursively
+func FindFiles(paths []string, ext string) ([]string, error) {
+	found := []string{}
+
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			return found, err
+		}
+
+		if !info.IsDir() {
+			if filepath.Ext(info.Name()) == ext {
+				found = append(found, path)
+			}
+			continue
+		}
+
+		err = filepath.WalkDir(path, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+
+			if d.Type().IsRegular() && filepath.Ext(d.Name()) == ext {
+				found = append(found, path)
+			}
+			return nil
+		})
+		if err != nil {
+			return found, fmt.Errorf("walking through directory %s: %w", path, err)
+		}
+	}
+
+	sort.Strings(found)
+	return found, nil
+}
+
+// Load loads JSON report from file, or merges all JSON reports found in directory
+func Load(ctx context.Context, path string) (*parser.Result, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if info.IsDir() {
+		return Merge(ctx, path, parser.MergeWorst)
+	}
+
+	return LoadFile(path)
+}
+
+// LoadFile loads JSON report, compressed or not, from file
+func LoadFile(path string) (*parser.Result, error) {
+	data, err := os.ReadFile(filepath.Clean(path))
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := Decode(data)
+	if err != nil {
+		return nil, fmt.Errorf("loading %s: %w", path, err)
+	}
+
+	return result, nil
+}
+
+// Merge combines all JSON reports found in directory into one, strategy decides which result of the same test is kept
+func Merge(ctx context.Context, dir string, strategy parser.MergeStrategy) (*parser.Result, error) {
+	paths, err := FindFiles([]string{dir}, ".json")
+	if err != nil {
+		return nil, err
+	}
+
+	result := parser.NewResult()
+	for _, path := range paths {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		newResult, err := LoadFile(path)
+		if err != nil {
+			return nil, err
+		}
+
+		logger.Debug("File loaded: %s", path)
+		result.CombineWith(*newResult, strategy)
+	}
+
+	return &result, nil
+}
+
+// Decode reads JSON report, compressed or not
+func Decode(data []byte) (*parser.Result, error) {
+	data, err := Decompress(data)
+	if err != nil {
+		return nil, err
+	}
+
+	var result parser.Result
+	err = json.Unmarshal(data, &result)
+	if err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+// Encode marshals result into JSON report
+func Encode(result *parser.Result, options WriteOptions) ([]byte, error) {
+	data, err := json.Marshal(result)
+	if err != nil {
+		return nil, fmt.Errorf("marshaling results: %w", err)
+	}
+
+	if !options.Compress {
+		return data, nil
+	}
+
+	return Compress(data)
+}
+
+// Write saves result as JSON report to path
+func Write(ctx context.Context, result *parser.Result, path string, options WriteOptions) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	data, err := Encode(result, options)
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(filepath.Clean(path), data, 0644) // #nosec
+}
+
+// IsCompressed checks if data starts with gzip header
+func IsCompressed(data []byte) bool {
+	return len(data) >= 2 && data[0] == 0x1f && data[1] == 0x8b
+}
+
+// Compress compresses data with gzip
+func Compress(data []byte) ([]byte, error) {
+	var buf bytes.Buffer
+
+	writer := gzip.NewWriter(&buf)
+	_, err := writer.Write(data)
+	if err != nil {
+		return data, err
+	}
+
+	err = writer.Close()
+	if err != nil {
+		return data, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// Decompress decompresses gzip data, data which is not compressed is returned as is
+func Decompress(data []byte) ([]byte, error) {
+	if !IsCompressed(data) {
+		return data, nil
+	}
+
+	reader, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return data, fmt.Errorf("decompressing: %w", err)
+	}
+	defer reader.Close()
+
+	newData, err := io.ReadAll(reader)
+	if err != nil {
+		return data, fmt.Errorf("decompressing: %w", err)
+	}
+
+	return newData, nil
+}
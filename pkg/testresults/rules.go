@@ -0,0 +1,134 @@
+package testresults
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/semaphoreci/test-results/pkg/glob"
+	"github.com/semaphoreci/test-results/pkg/parsers"
+)
+
+// Rule overrides parse options of files matching either Path or Regex.
+// Path is a glob where `*` matches within one directory and `**` matches any number of directories, e.g. `reports/php/**`.
+// Regex is matched against any part of the path, e.g. `/php/.*\.xml$`. Paths use forward slashes on every platform.
+type Rule struct {
+	Path        string            `mapstructure:"path" yaml:"path,omitempty" json:"path,omitempty"`
+	Regex       string            `mapstructure:"regex" yaml:"regex,omitempty" json:"regex,omitempty"`
+	Parser      string            `mapstructure:"parser" yaml:"parser,omitempty" json:"parser,omitempty"`
+	Name        string            `mapstructure:"name" yaml:"name,omitempty" json:"name,omitempty"`
+	SuitePrefix string            `mapstructure:"suite-prefix" yaml:"suite-prefix,omitempty" json:"suite-prefix,omitempty"`
+	Properties  map[string]string `mapstructure:"properties" yaml:"properties,omitempty" json:"properties,omitempty"`
+
+	index   int
+	pattern *regexp.Regexp
+}
+
+// Compile validates rule and prepares its pattern
+func (r *Rule) Compile() error {
+	var compiled *regexp.Regexp
+	switch {
+	case r.Path != "" && r.Regex != "":
+		return fmt.Errorf("only one of path and regex can be set")
+	case r.Path != "":
+		compiled = glob.Path(normalizePath(r.Path))
+	case r.Regex != "":
+		var err error
+		compiled, err = regexp.Compile(r.Regex)
+		if err != nil {
+			return fmt.Errorf("invalid pattern %q: %v", r.Regex, err)
+		}
+	default:
+		return fmt.Errorf("path or regex is required")
+	}
+
+	if r.Parser != "" && r.Parser != "auto" && !knownParser(r.Parser) {
+		return fmt.Errorf("unknown parser %q, expected one of: auto, %s", r.Parser, strings.Join(parsers.Names(), ", "))
+	}
+
+	r.pattern = compiled
+	return nil
+}
+
+// Matches tells if path of a file matches the rule. Rules which were not compiled don't match anything,
+// so rules shared by parsing workers are only read.
+func (r *Rule) Matches(path string) bool {
+	if r.pattern == nil {
+		return false
+	}
+
+	return r.pattern.MatchString(normalizePath(path))
+}
+
+// String describes the rule in logs, e.g. `#2 (path reports/php/**)`
+func (r *Rule) String() string {
+	if r.Regex != "" {
+		return fmt.Sprintf("#%d (regex %s)", r.index, r.Regex)
+	}
+
+	return fmt.Sprintf("#%d (path %s)", r.index, r.Path)
+}
+
+// apply returns options for a file matched by the rule, fields set in the rule take precedence
+func (r *Rule) apply(options ParseOptions) ParseOptions {
+	if r.Parser != "" {
+		options.Parser = r.Parser
+	}
+	if r.Name != "" {
+		options.Name = r.Name
+	}
+	if r.SuitePrefix != "" {
+		options.SuitePrefix = r.SuitePrefix
+	}
+
+	if len(r.Properties) > 0 {
+		properties := map[string]string{}
+		for name, value := range options.Properties {
+			properties[name] = value
+		}
+		for name, value := range r.Properties {
+			properties[name] = value
+		}
+		options.Properties = properties
+	}
+
+	return options
+}
+
+func knownParser(name string) bool {
+	for _, known := range parsers.Names() {
+		if known == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+// CompileRules validates all rules
+func CompileRules(rules []Rule) error {
+	for i := range rules {
+		rules[i].index = i + 1
+		if err := rules[i].Compile(); err != nil {
+			return fmt.Errorf("rule #%d: %v", i+1, err)
+		}
+	}
+
+	return nil
+}
+
+// Match returns the first rule matching path
+func Match(rules []Rule, path string) (*Rule, bool) {
+	for i := range rules {
+		if rules[i].Matches(path) {
+			return &rules[i], true
+		}
+	}
+
+	return nil, false
+}
+
+func normalizePath(path string) string {
+	return strings.TrimPrefix(filepath.ToSlash(filepath.Clean(path)), "./")
+}
@@ -0,0 +1,294 @@
+// Package testresults parses, decorates, merges and writes test reports in-process.
+// It is the API behind test-results CLI commands, options are plain structs and errors are returned to the caller.
+package testresults
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/semaphoreci/test-results/pkg/fileloader"
+	"github.com/semaphoreci/test-results/pkg/parser"
+	"github.com/semaphoreci/test-results/pkg/parsers"
+	"golang.org/x/text/cases"
+	"golang.org/x/text/language"
+)
+
+// ErrorPolicy decides what happens with files which could not be parsed
+type ErrorPolicy string
+
+const (
+	// FailOnError keeps results of all files and returns an error listing failed files. It is the default.
+	FailOnError ErrorPolicy = "fail"
+	// WarnOnError keeps results of failed files, marked with error status, and returns no error
+	WarnOnError ErrorPolicy = "warn"
+	// SkipOnError drops results of failed files and returns no error
+	SkipOnError ErrorPolicy = "skip"
+)
+
+// ParseErrorPolicy validates policy name, empty name is FailOnError
+func ParseErrorPolicy(name string) (ErrorPolicy, error) {
+	switch policy := ErrorPolicy(name); policy {
+	case "":
+		return FailOnError, nil
+	case FailOnError, WarnOnError, SkipOnError:
+		return policy, nil
+	default:
+		return "", fmt.Errorf("unknown parse error policy %q, expected one of: fail, warn, skip", name)
+	}
+}
+
+// ParseOptions ...
+type ParseOptions struct {
+	// Parser is the name of parser to use, empty or "auto" detects it from the file
+	Parser string
+	// Name overrides name of test results
+	Name string
+	// SuitePrefix is prepended to name of each suite
+	SuitePrefix string
+	// Decorate is applied to results of each file, except Trim.Total which ParseFiles applies to the merged result
+	Decorate DecorateOptions
+	// Jobs is the number of files parsed concurrently by ParseEach and ParseFiles, defaults to 1
+	Jobs int
+	// OnParseError is applied to files which parsers failed to parse, defaults to FailOnError
+	OnParseError ErrorPolicy
+	// Properties are added to every suite, overriding properties of the same name from the file
+	Properties map[string]string
+	// Rules override Parser, Name, SuitePrefix and Properties of files matching their path, the first matching rule is used
+	Rules []Rule
+	// MergeStrategy decides which result is kept when ParseFiles finds the same test in more than one file
+	MergeStrategy parser.MergeStrategy
+	// RSpecTagMetadata lists custom boolean RSpec metadata taken as tags, in addition to parsers.RSpecTagMetadata
+	RSpecTagMetadata []string
+}
+
+// File is the result of parsing a single file.
+// Err is set when parser failed, Result is then nil for SkipOnError policy and partial results otherwise.
+type File struct {
+	Path   string
+	Parser string
+	Result *parser.Result
+	Stats  DecorateStats
+	Err    error
+	// Rule is the rule applied to the file, nil when no rule matches it
+	Rule *Rule
+}
+
+// Detect finds parser by name or, when name is empty or "auto", by checking which parser is applicable to the file
+func Detect(ctx context.Context, path, name string) (parser.Parser, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	if name == "" {
+		name = "auto"
+	}
+
+	p, err := parsers.FindParser(name, path)
+	if err != nil {
+		return nil, fmt.Errorf("finding parser for %s: %w", path, err)
+	}
+
+	return p, nil
+}
+
+// Parse parses file at path with given parser, names test results and decorates them.
+// When parser fails, results parsed so far are returned together with the parser error.
+func Parse(ctx context.Context, p parser.Parser, path string, options ParseOptions) (*parser.Result, error) {
+	result, parseErr := parse(ctx, p, path, options)
+	if result == nil {
+		return nil, parseErr
+	}
+
+	_, err := Decorate(ctx, result, options.Decorate)
+	if err != nil {
+		return nil, fmt.Errorf("decorating results of %s: %w", path, err)
+	}
+
+	return result, parseErr
+}
+
+func parse(ctx context.Context, p parser.Parser, path string, options ParseOptions) (*parser.Result, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	// Content is cached while parsers check if they are applicable, it is not needed afterwards
+	defer fileloader.Forget(path)
+
+	if rspec, ok := p.(parsers.RSpec); ok && len(options.RSpecTagMetadata) > 0 {
+		p = rspec.WithTagMetadata(options.RSpecTagMetadata...)
+	}
+
+	result := parser.NewResult()
+	testResults, err := p.Parse(path)
+
+	if options.Name != "" {
+		testResults.Name = options.Name
+		testResults.RegenerateID()
+	}
+
+	if testResults.Name == "" {
+		testResults.Name = cases.Title(language.English, cases.NoLower).String(fmt.Sprintf("%s Suite", p.GetName()))
+	}
+
+	if options.SuitePrefix != "" {
+		for suiteIdx := range testResults.Suites {
+			testResults.Suites[suiteIdx].Name = fmt.Sprintf("%s %s", options.SuitePrefix, testResults.Suites[suiteIdx].Name)
+		}
+		testResults.RegenerateID()
+	}
+
+	if len(options.Properties) > 0 {
+		for suiteIdx := range testResults.Suites {
+			suite := &testResults.Suites[suiteIdx]
+			if suite.Properties == nil {
+				suite.Properties = parser.Properties{}
+			}
+			for name, value := range options.Properties {
+				suite.Properties[name] = value
+			}
+		}
+	}
+
+	result.TestResults = append(result.TestResults, testResults)
+	return &result, err
+}
+
+// ParseEach detects parser of each file and parses it, using up to options.Jobs goroutines.
+// Files are returned in order of paths. They are decorated one by one in that order, so redaction
+// placeholders don't depend on which file finished parsing first and output is the same as parsing serially.
+// Files which parsers failed to parse are handled according to options.OnParseError, any other error aborts parsing.
+// The total output cap is not applied to single files, callers merging them apply it with TrimTotal.
+func ParseEach(parent context.Context, paths []string, options ParseOptions) ([]File, error) {
+	ctx, cancel := context.WithCancel(parent)
+	defer cancel()
+
+	// Rules are compiled before workers start, so workers only read them
+	if err := CompileRules(options.Rules); err != nil {
+		return nil, err
+	}
+
+	files := make([]File, len(paths))
+	errs := make([]error, len(paths))
+
+	jobs := options.Jobs
+	if jobs < 1 {
+		jobs = 1
+	}
+
+	queue := make(chan int)
+	wg := sync.WaitGroup{}
+	for i := 0; i < jobs && i < len(paths); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for idx := range queue {
+				files[idx], errs[idx] = parseFile(ctx, paths[idx], options)
+				if errs[idx] != nil {
+					cancel()
+				}
+			}
+		}()
+	}
+
+	for idx := range paths {
+		queue <- idx
+	}
+	close(queue)
+	wg.Wait()
+
+	if err := parent.Err(); err != nil {
+		return nil, err
+	}
+
+	// The first failed file is reported, other files may have failed only because parsing was cancelled
+	for idx := range paths {
+		if errs[idx] != nil && !errors.Is(errs[idx], context.Canceled) {
+			return nil, errs[idx]
+		}
+	}
+
+	perFile := options.Decorate
+	perFile.Trim.Total = 0
+
+	failed := []error{}
+	for idx := range files {
+		if files[idx].Err != nil {
+			failed = append(failed, files[idx].Err)
+			if options.OnParseError == SkipOnError {
+				files[idx].Result = nil
+				continue
+			}
+		}
+
+		stats, err := Decorate(parent, files[idx].Result, perFile)
+		if err != nil {
+			return nil, fmt.Errorf("decorating results of %s: %w", files[idx].Path, err)
+		}
+		files[idx].Stats = stats
+	}
+
+	if len(failed) > 0 && (options.OnParseError == "" || options.OnParseError == FailOnError) {
+		return files, fmt.Errorf("%d of %d file(s) failed to parse: %w", len(failed), len(files), errors.Join(failed...))
+	}
+
+	return files, nil
+}
+
+// Failed returns files which parsers failed to parse
+func Failed(files []File) []File {
+	failed := []File{}
+	for _, file := range files {
+		if file.Err != nil {
+			failed = append(failed, file)
+		}
+	}
+
+	return failed
+}
+
+func parseFile(ctx context.Context, path string, options ParseOptions) (File, error) {
+	rule, found := Match(options.Rules, path)
+	if found {
+		options = rule.apply(options)
+	}
+
+	p, err := Detect(ctx, path, options.Parser)
+	if err != nil {
+		return File{}, err
+	}
+
+	result, err := parse(ctx, p, path, options)
+	if result == nil {
+		return File{}, err
+	}
+
+	return File{Path: path, Parser: p.GetName(), Result: result, Err: err, Rule: rule}, nil
+}
+
+// ParseFiles parses all files with ParseEach, merges their results in order of paths and caps their total output size
+func ParseFiles(ctx context.Context, paths []string, options ParseOptions) (*parser.Result, error) {
+	files, err := ParseEach(ctx, paths, options)
+	if err != nil {
+		return nil, err
+	}
+
+	result := MergeFiles(files, options.MergeStrategy)
+	TrimTotal(result, options.Decorate)
+	return result, nil
+}
+
+// MergeFiles combines results of parsed files in their order, files without results are skipped
+func MergeFiles(files []File, strategy parser.MergeStrategy) *parser.Result {
+	merged := parser.NewResult()
+	for _, file := range files {
+		if file.Result == nil {
+			continue
+		}
+		merged.CombineWith(*file.Result, strategy)
+	}
+
+	return &merged
+}
@@ -0,0 +1,210 @@
+package trim
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/semaphoreci/test-results/pkg/parser"
+)
+
+// Options sets output budgets in bytes, 0 means unlimited
+type Options struct {
+	// Passed limits each output of passed, skipped and disabled tests
+	Passed int
+	// Failed limits each output of failed and errored tests, and failure bodies
+	Failed int
+	// Total limits size of all outputs in the result, the largest outputs are shrunk first
+	Total int
+}
+
+// Stats ...
+type Stats struct {
+	// Trimmed is number of trimmed outputs
+	Trimmed int
+	// Omitted is number of removed bytes
+	Omitted int
+
+	trimmed map[*string]bool
+}
+
+func marker(omitted int) string {
+	return fmt.Sprintf("\n... [%d bytes omitted] ...\n", omitted)
+}
+
+// Text trims s to at most limit bytes, keeping a third of the budget for the head and the rest for the tail.
+// Omitted part is replaced with a marker, cuts are made on rune boundaries. Limit of 0 means unlimited.
+func Text(s string, limit int) string {
+	if limit <= 0 || len(s) <= limit {
+		return s
+	}
+
+	// The marker is sized for the worst case, so the result never exceeds the limit
+	budget := limit - len(marker(len(s)))
+	if budget <= 0 {
+		return s[:runeStart(s, limit)]
+	}
+
+	headEnd := runeStart(s, budget/3)
+	tailStart := nextRuneStart(s, len(s)-(budget-headEnd))
+
+	return s[:headEnd] + marker(tailStart-headEnd) + s[tailStart:]
+}
+
+// runeStart moves i back to the start of the rune it points into
+func runeStart(s string, i int) int {
+	if i >= len(s) {
+		return len(s)
+	}
+
+	for i > 0 && !utf8.RuneStart(s[i]) {
+		i--
+	}
+
+	return i
+}
+
+func nextRuneStart(s string, i int) int {
+	for i < len(s) && !utf8.RuneStart(s[i]) {
+		i++
+	}
+
+	return i
+}
+
+// Apply trims outputs of all tests and suites in result according to options
+func Apply(result *parser.Result, options Options) Stats {
+	stats := Stats{trimmed: map[*string]bool{}}
+	all := []*string{}
+
+	trim := func(limit int, fields ...*string) {
+		for _, field := range fields {
+			if *field == "" {
+				continue
+			}
+
+			stats.add(field, limit)
+			all = append(all, field)
+		}
+	}
+
+	for i := range result.TestResults {
+		for j := range result.TestResults[i].Suites {
+			suite := &result.TestResults[i].Suites[j]
+
+			suiteLimit := options.Passed
+			if suite.Summary.Failed > 0 || suite.Summary.Error > 0 || suite.Summary.Quarantined > 0 {
+				suiteLimit = options.Failed
+			}
+			trim(suiteLimit, &suite.SystemOut, &suite.SystemErr)
+
+			for k := range suite.Tests {
+				test := &suite.Tests[k]
+				switch test.State {
+				case parser.StateFailed, parser.StateError, parser.StateQuarantined:
+					trim(options.Failed, &test.SystemOut, &test.SystemErr)
+				default:
+					trim(options.Passed, &test.SystemOut, &test.SystemErr)
+				}
+
+				if test.Failure != nil {
+					trim(options.Failed, &test.Failure.Body)
+				}
+				if test.Error != nil {
+					trim(options.Failed, &test.Error.Body)
+				}
+			}
+		}
+	}
+
+	if options.Total > 0 {
+		limit := level(all, options.Total)
+		for _, field := range all {
+			stats.add(field, limit)
+		}
+	}
+
+	return stats
+}
+
+func (s *Stats) add(field *string, limit int) {
+	trimmed := Text(*field, limit)
+	if len(trimmed) == len(*field) {
+		return
+	}
+
+	if !s.trimmed[field] {
+		s.trimmed[field] = true
+		s.Trimmed++
+	}
+	s.Omitted += len(*field) - len(trimmed)
+	*field = trimmed
+}
+
+// level returns the largest per-field limit keeping total size of fields within total, 0 when no limit is needed
+func level(fields []*string, total int) int {
+	sizes := make([]int, 0, len(fields))
+	sum := 0
+	for _, field := range fields {
+		sizes = append(sizes, len(*field))
+		sum += len(*field)
+	}
+
+	if sum <= total {
+		return 0
+	}
+
+	sort.Sort(sort.Reverse(sort.IntSlice(sizes)))
+
+	// Fields larger than the level are trimmed to it, smaller ones are kept
+	rest := sum
+	for k := 1; k <= len(sizes); k++ {
+		rest -= sizes[k-1]
+		limit := (total - rest) / k
+		if k == len(sizes) || limit >= sizes[k] {
+			if limit < 1 {
+				return 1
+			}
+			return limit
+		}
+	}
+
+	return 1
+}
+
+// ParseSize parses size in bytes with optional KB, MB or GB suffix, e.g. 10MB
+func ParseSize(s string) (int, error) {
+	value := strings.ToUpper(strings.TrimSpace(s))
+	multiplier := 1
+	for _, unit := range []struct {
+		suffix     string
+		multiplier int
+	}{{"GB", 1 << 30}, {"MB", 1 << 20}, {"KB", 1 << 10}, {"B", 1}} {
+		if strings.HasSuffix(value, unit.suffix) {
+			value = strings.TrimSpace(strings.TrimSuffix(value, unit.suffix))
+			multiplier = unit.multiplier
+			break
+		}
+	}
+
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid size %q", s)
+	}
+
+	return n * multiplier, nil
+}
+
+// Size formats size in bytes, e.g. 1.5MB
+func Size(bytes int) string {
+	switch {
+	case bytes >= 1<<20:
+		return strconv.FormatFloat(float64(bytes)/(1<<20), 'f', 1, 64) + "MB"
+	case bytes >= 1<<10:
+		return strconv.FormatFloat(float64(bytes)/(1<<10), 'f', 1, 64) + "KB"
+	default:
+		return strconv.Itoa(bytes) + "B"
+	}
+}
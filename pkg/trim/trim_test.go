@@ -0,0 +1,103 @@
+package trim_test
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/semaphoreci/test-results/pkg/parser"
+	"github.com/semaphoreci/test-results/pkg/trim"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Text(t *testing.T) {
+	assert.Equal(t, "short", trim.Text("short", 10))
+	assert.Equal(t, "unlimited", trim.Text("unlimited", 0))
+
+	s := strings.Repeat("h", 100) + strings.Repeat("m", 800) + strings.Repeat("t", 100)
+	trimmed := trim.Text(s, 200)
+
+	assert.LessOrEqual(t, len(trimmed), 200)
+	assert.True(t, strings.HasPrefix(trimmed, "hhhh"))
+	assert.True(t, strings.HasSuffix(trimmed, strings.Repeat("t", 100)), "tail should get most of the budget")
+	assert.Regexp(t, `\n\.\.\. \[\d+ bytes omitted\] \.\.\.\n`, trimmed)
+
+	assert.Equal(t, "abc", trim.Text("abcdefghijklmnopqrstuvwxyz", 3), "marker is dropped when it does not fit")
+}
+
+func Test_Text_Runes(t *testing.T) {
+	s := strings.Repeat("żółw ", 200)
+
+	for limit := 40; limit < 80; limit++ {
+		trimmed := trim.Text(s, limit)
+		assert.True(t, utf8.ValidString(trimmed), "limit %d", limit)
+		assert.LessOrEqual(t, len(trimmed), limit)
+	}
+
+	assert.True(t, utf8.ValidString(trim.Text("żółw", 2)))
+}
+
+func newResult() parser.Result {
+	suite := parser.NewSuite()
+	suite.Name = "Suite"
+	suite.SystemOut = strings.Repeat("s", 1000)
+
+	passed := parser.NewTest()
+	passed.SystemOut = strings.Repeat("p", 1000)
+	suite.Tests = append(suite.Tests, passed)
+
+	failed := parser.NewTest()
+	failed.State = parser.StateFailed
+	failed.SystemOut = strings.Repeat("f", 1000)
+	failed.Failure = &parser.Failure{Body: strings.Repeat("b", 4000)}
+	suite.Tests = append(suite.Tests, failed)
+	suite.Aggregate()
+
+	testResults := parser.NewTestResults()
+	testResults.Suites = []parser.Suite{suite}
+
+	return parser.Result{TestResults: []parser.TestResults{testResults}}
+}
+
+func Test_Apply_Budgets(t *testing.T) {
+	result := newResult()
+	stats := trim.Apply(&result, trim.Options{Passed: 100, Failed: 500})
+
+	suite := result.TestResults[0].Suites[0]
+	assert.LessOrEqual(t, len(suite.Tests[0].SystemOut), 100)
+	assert.LessOrEqual(t, len(suite.Tests[1].SystemOut), 500)
+	assert.Greater(t, len(suite.Tests[1].SystemOut), 100)
+	assert.LessOrEqual(t, len(suite.Tests[1].Failure.Body), 500)
+	assert.LessOrEqual(t, len(suite.SystemOut), 500, "suites with failures use failed budget")
+	assert.Equal(t, 4, stats.Trimmed)
+}
+
+func Test_Apply_Total(t *testing.T) {
+	result := newResult()
+	stats := trim.Apply(&result, trim.Options{Total: 5000})
+
+	suite := result.TestResults[0].Suites[0]
+	total := len(suite.SystemOut) + len(suite.Tests[0].SystemOut) + len(suite.Tests[1].SystemOut) + len(suite.Tests[1].Failure.Body)
+
+	assert.LessOrEqual(t, total, 5000)
+	assert.Equal(t, 1000, len(suite.Tests[0].SystemOut), "smaller outputs should be kept")
+	assert.LessOrEqual(t, len(suite.Tests[1].Failure.Body), 2000, "the largest output should be shrunk first")
+	assert.Equal(t, 1, stats.Trimmed)
+
+	result = newResult()
+	assert.Equal(t, 0, trim.Apply(&result, trim.Options{Total: 10000}).Trimmed)
+}
+
+func Test_ParseSize(t *testing.T) {
+	for input, expected := range map[string]int{"100": 100, "10B": 10, "2kb": 2048, "1 MB": 1 << 20, "1GB": 1 << 30} {
+		size, err := trim.ParseSize(input)
+		require.NoError(t, err, input)
+		assert.Equal(t, expected, size, input)
+	}
+
+	for _, input := range []string{"", "MB", "-1", "1TB"} {
+		_, err := trim.ParseSize(input)
+		assert.Error(t, err, input)
+	}
+}